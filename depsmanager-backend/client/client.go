@@ -0,0 +1,242 @@
+// Package client is a Go client of the DepsManager HTTP API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"depsmanager"
+	customErr "depsmanager/pkg/errors"
+	"depsmanager/pkg/requestid"
+	"depsmanager/pkg/tenant"
+	"depsmanager/pkg/trace"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrBadRequest = errors.New("bad request")
+
+const maxErrorBodySize = 4 << 10
+
+// StatusError is returned when the API responds with a non-2xx status.
+// It unwraps to the sentinel error of Code, e.g. depsmanager.ErrProjectNotFound,
+// and every 400 response matches ErrBadRequest.
+type StatusError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	// Code, Detail, RequestID and Fields come from problem details body, see customErr.Problem.
+	Code      string
+	Detail    string
+	RequestID string
+	Fields    []customErr.FieldError
+
+	err error
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	} else if e.err != nil {
+		msg += ": " + e.err.Error()
+	}
+	if e.RequestID != "" {
+		msg += " (request_id: " + e.RequestID + ")"
+	}
+	return msg
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.err
+}
+
+func (e *StatusError) Is(target error) bool {
+	return target == ErrBadRequest && e.StatusCode == http.StatusBadRequest
+}
+
+type Client struct {
+	address    string
+	httpClient *http.Client
+	retries    int
+	retryWait  time.Duration
+	apiKey     string
+	tenant     string
+}
+
+type Option func(c *Client)
+
+// New returns client of DepsManager running at address, e.g. http://localhost:8085.
+func New(address string, opts ...Option) *Client {
+	c := &Client{
+		address:    strings.TrimRight(address, "/"),
+		httpClient: http.DefaultClient,
+		retryWait:  200 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey sends key as bearer token, required when the server has authentication enabled.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithTenant acts in tenant instead of the tenant of the api key, "*" reads across all tenants.
+// Requires admin role.
+func WithTenant(tenant string) Option {
+	return func(c *Client) {
+		c.tenant = tenant
+	}
+}
+
+// WithRetries retries idempotent requests up to retries times on transport errors
+// and 502, 503, 504 responses. Wait between attempts starts at wait and doubles.
+func WithRetries(retries int, wait time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.retryWait = wait
+	}
+}
+
+// request describes a single API call. notFound is the sentinel returned on 404.
+type request struct {
+	method      string
+	path        string
+	contentType string
+	body        []byte
+	idempotent  bool
+	notFound    error
+}
+
+func jsonRequest(method, path string, in any, notFound error) (request, error) {
+	r := request{method: method, path: path, idempotent: true, notFound: notFound}
+	if in == nil {
+		return r, nil
+	}
+
+	b, err := json.Marshal(in)
+	if err != nil {
+		return request{}, fmt.Errorf("json.Marshal(): %w", err)
+	}
+	r.body = b
+	r.contentType = "application/json"
+	return r, nil
+}
+
+// do sends r with retries and decodes the response into out, out may be nil.
+func (c *Client) do(ctx context.Context, r request, out any) error {
+	wait := c.retryWait
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, r, out)
+		if err == nil || !r.idempotent || attempt >= c.retries || !retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+func (c *Client) send(ctx context.Context, r request, out any) error {
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, c.address+"/api"+r.path, body)
+	if err != nil {
+		return fmt.Errorf("http.NewRequestWithContext(): %w", err)
+	}
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	if c.tenant != "" {
+		req.Header.Set(tenant.Header, c.tenant)
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
+	trace.Inject(ctx, req.Header)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("c.httpClient.Do(): %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return statusError(r, resp)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("json.NewDecoder(resp.Body).Decode(): %w", err)
+	}
+	return nil
+}
+
+func statusError(r request, resp *http.Response) *StatusError {
+	e := &StatusError{StatusCode: resp.StatusCode, Method: r.method, Path: r.path}
+
+	var p customErr.Problem
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err := json.Unmarshal(b, &p); err == nil {
+		e.Code, e.Detail, e.RequestID, e.Fields = p.Code, p.Detail, p.RequestID, p.Errors
+	} else {
+		e.Detail = strings.TrimSpace(string(b))
+	}
+
+	if e.err = customErr.SentinelOf(e.Code); e.err != nil {
+		return e
+	}
+	// generic codes and servers without problem details
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		e.err = depsmanager.ErrUnauthorized
+	case http.StatusForbidden:
+		e.err = depsmanager.ErrForbidden
+	case http.StatusNotFound:
+		e.err = r.notFound
+	case http.StatusConflict:
+		e.err = depsmanager.ErrDependencyAlreadyExists
+	}
+	return e
+}
+
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	// transport error, the context error is not worth retrying
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,364 @@
+package client
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/labels"
+	"depsmanager/pkg/requestid"
+	"depsmanager/pkg/tenant"
+	"depsmanager/pkg/trace"
+	"depsmanager/service"
+	"depsmanager/service/mocks"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setup(t *testing.T, opts ...Option) (*Client, *mocks.Service) {
+	t.Helper()
+	svc := new(mocks.Service)
+	api := service.NewAPI(svc)
+	srv := httptest.NewServer(api.GetHandler())
+	t.Cleanup(srv.Close)
+	return New(srv.URL, opts...), svc
+}
+
+func TestClient_Projects(t *testing.T) {
+	c, svc := setup(t)
+	ctx := context.Background()
+
+	svc.On("FetchAndStoreProjectDependencies", mock.Anything, "react", "18.3.1").Return(nil).Once()
+	svc.On("ListProjects", mock.Anything, mock.Anything).Return([]depsmanager.Project{{Name: "react", Version: "18.3.1"}}, nil).Once()
+	svc.On("ListProjectVersions", mock.Anything, "@babel/core").Return([]string{"7.0.0"}, nil).Once()
+	svc.On("DeleteProject", mock.Anything, "react", "18.3.1").Return(nil).Once()
+
+	require.NoError(t, c.FetchProject(ctx, "react", "18.3.1"))
+
+	projects, err := c.ListProjects(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []depsmanager.Project{{Name: "react", Version: "18.3.1"}}, projects)
+
+	versions, err := c.ProjectVersions(ctx, "@babel/core")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"7.0.0"}, versions)
+
+	require.NoError(t, c.DeleteProject(ctx, "react", "18.3.1"))
+	svc.AssertExpectations(t)
+}
+
+func TestClient_Dependencies(t *testing.T) {
+	c, svc := setup(t)
+	ctx := context.Background()
+
+	deps := depsmanager.ListDependenciesResponse{ProjectName: "react", Dependencies: []depsmanager.Dependency{{Name: "a", Score: 5}}}
+	svc.On("ListDependencies", mock.Anything, "react", "18.3.1").Return(deps, nil).Once()
+	svc.On("AddDependency", mock.Anything, "react", "18.3.1", mock.MatchedBy(func(d depsmanager.Dependency) bool {
+		return d.Name == "b" && d.Score == 7
+	})).Return(nil).Once()
+	svc.On("UpdateDependency", mock.Anything, "react", "18.3.1", mock.MatchedBy(func(d depsmanager.Dependency) bool {
+		return d.Name == "b" && d.Score == 8
+	})).Return(nil).Once()
+	svc.On("DeleteDependency", mock.Anything, "react", "18.3.1", "b").Return(nil).Once()
+	svc.On("ClearScoreOverride", mock.Anything, "react", "18.3.1", "@types/node").Return(nil).Once()
+	svc.On("GetProjectsByDependency", mock.Anything, "a", mock.Anything).Return([]depsmanager.Project{{Name: "react"}}, nil).Once()
+	svc.On("GetDependenciesByExactScore", mock.Anything, 5.0, mock.Anything).Return([]string{"a"}, nil).Once()
+
+	got, err := c.ListDependencies(ctx, "react", "18.3.1")
+	require.NoError(t, err)
+	assert.Equal(t, deps, got)
+
+	require.NoError(t, c.AddDependency(ctx, depsmanager.DependencyRequest{ProjectName: "react", Version: "18.3.1", DependencyName: "b", Score: 7}))
+	require.NoError(t, c.ModifyDependency(ctx, depsmanager.DependencyRequest{ProjectName: "react", Version: "18.3.1", DependencyName: "b", Score: 8}))
+	require.NoError(t, c.DeleteDependency(ctx, depsmanager.RemoveDependencyRequest{ProjectName: "react", Version: "18.3.1", DependencyName: "b"}))
+	require.NoError(t, c.ClearScoreOverride(ctx, "react", "18.3.1", "@types/node"))
+
+	projects, err := c.ProjectsByDependency(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []depsmanager.Project{{Name: "react"}}, projects)
+
+	names, err := c.DependenciesByScore(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, names)
+	svc.AssertExpectations(t)
+}
+
+func TestClient_PoliciesAndGate(t *testing.T) {
+	c, svc := setup(t)
+	ctx := context.Background()
+	doc := []byte("name: strict\nrules:\n  - type: max_unscored\n    value: 0\n")
+	minScore := 4.0
+
+	svc.On("SavePolicy", mock.Anything, doc).Return(depsmanager.Policy{Name: "strict"}, nil).Once()
+	svc.On("ListPolicies", mock.Anything, "react").Return([]depsmanager.Policy{{Name: "strict"}}, nil).Once()
+	svc.On("GetPolicyEvaluation", mock.Anything, "react", "18.3.1").Return(depsmanager.PolicyEvaluation{}, depsmanager.ErrPolicyEvaluationNotFound).Once()
+	svc.On("DeletePolicy", mock.Anything, "", "strict").Return(nil).Once()
+	svc.On("EvaluateLockfile", mock.Anything, []byte("{}"), depsmanager.GateRequest{
+		BaselineProject: "react",
+		BaselineVersion: "18.3.1",
+		Thresholds:      depsmanager.GateThresholds{MinScore: &minScore},
+	}).Return(depsmanager.GateResult{Verdict: depsmanager.VerdictPass}, nil).Once()
+
+	p, err := c.SavePolicy(ctx, doc)
+	require.NoError(t, err)
+	assert.Equal(t, "strict", p.Name)
+
+	policies, err := c.ListPolicies(ctx, "react")
+	require.NoError(t, err)
+	assert.Len(t, policies, 1)
+
+	_, err = c.PolicyEvaluation(ctx, "react", "18.3.1")
+	require.ErrorIs(t, err, depsmanager.ErrPolicyEvaluationNotFound)
+
+	require.NoError(t, c.DeletePolicy(ctx, "", "strict"))
+
+	res, err := c.Gate(ctx, []byte("{}"), depsmanager.GateRequest{
+		BaselineProject: "react",
+		BaselineVersion: "18.3.1",
+		Thresholds:      depsmanager.GateThresholds{MinScore: &minScore},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, depsmanager.VerdictPass, res.Verdict)
+	svc.AssertExpectations(t)
+}
+
+func TestClient_SentinelErrors(t *testing.T) {
+	c, svc := setup(t)
+	ctx := context.Background()
+
+	svc.On("ListDependencies", mock.Anything, "missing", "1.0.0").Return(depsmanager.ListDependenciesResponse{}, depsmanager.ErrProjectNotFound).Once()
+	svc.On("AddDependency", mock.Anything, "react", "18.3.1", mock.Anything).Return(depsmanager.ErrDependencyAlreadyExists).Once()
+	svc.On("DeletePolicy", mock.Anything, "", "missing").Return(depsmanager.ErrPolicyNotFound).Once()
+	svc.On("ListProjects", mock.Anything, mock.Anything).Return(nil, errors.New("boom")).Once()
+
+	_, err := c.ListDependencies(ctx, "missing", "1.0.0")
+	require.ErrorIs(t, err, depsmanager.ErrProjectNotFound)
+
+	err = c.AddDependency(ctx, depsmanager.DependencyRequest{ProjectName: "react", Version: "18.3.1", DependencyName: "a"})
+	require.ErrorIs(t, err, depsmanager.ErrDependencyAlreadyExists)
+
+	err = c.DeletePolicy(ctx, "", "missing")
+	require.ErrorIs(t, err, depsmanager.ErrPolicyNotFound)
+
+	err = c.FetchProject(ctx, "", "1.0.0")
+	require.ErrorIs(t, err, ErrBadRequest)
+
+	_, err = c.ListProjects(ctx, "")
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+	svc.AssertExpectations(t)
+}
+
+func TestClient_Retries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`["1.0.0"]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL, WithRetries(2, time.Millisecond), WithHTTPClient(srv.Client()))
+	versions, err := c.ProjectVersions(context.Background(), "react")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0"}, versions)
+	assert.Equal(t, int32(3), calls.Load())
+
+	calls.Store(0)
+	err = c.AddDependency(context.Background(), depsmanager.DependencyRequest{ProjectName: "react", Version: "1", DependencyName: "a"})
+	require.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load(), "non-idempotent request must not be retried")
+}
+
+func TestClient_PropagatesRequestContext(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL, WithHTTPClient(srv.Client()))
+	ctx, span := trace.NewTracer(nil).Start(context.Background(), "depsctl", trace.KindClient)
+	_, err := c.ProjectVersions(requestid.NewContext(ctx, "req-1"), "react")
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", got.Get(requestid.Header))
+	sc, ok := trace.Extract(got)
+	require.True(t, ok)
+	assert.Equal(t, span.SpanContext(), sc)
+}
+
+func TestClient_ProblemDetails(t *testing.T) {
+	c, _ := setup(t)
+
+	err := c.AddDependency(context.Background(), depsmanager.DependencyRequest{ProjectName: "react"})
+	require.ErrorIs(t, err, ErrBadRequest)
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, "validation_failed", statusErr.Code)
+	assert.NotEmpty(t, statusErr.RequestID)
+	assert.Len(t, statusErr.Fields, 2)
+	assert.Contains(t, err.Error(), "Version is required")
+}
+
+func TestClient_SentinelFromCode(t *testing.T) {
+	c, svc := setup(t)
+	svc.On("SavePolicy", mock.Anything, mock.Anything).Return(depsmanager.Policy{}, depsmanager.ErrInvalidPolicy).Once()
+	svc.On("UpdateDependency", mock.Anything, "react", "18.3.1", mock.Anything).Return(depsmanager.ErrDependencyNotFound).Once()
+
+	_, err := c.SavePolicy(context.Background(), []byte("name: x"))
+	require.ErrorIs(t, err, depsmanager.ErrInvalidPolicy)
+	require.ErrorIs(t, err, ErrBadRequest)
+
+	err = c.ModifyDependency(context.Background(), depsmanager.DependencyRequest{ProjectName: "react", Version: "18.3.1", DependencyName: "a"})
+	require.ErrorIs(t, err, depsmanager.ErrDependencyNotFound)
+	svc.AssertExpectations(t)
+}
+
+func TestClient_APIKeys(t *testing.T) {
+	svc := new(mocks.Service)
+	api := service.NewAPI(svc, service.WithAuth(true))
+	srv := httptest.NewServer(api.GetHandler())
+	t.Cleanup(srv.Close)
+	ctx := context.Background()
+
+	admin := depsmanager.Identity{Name: "bootstrap", Role: depsmanager.RoleAdmin}
+	svc.On("Authenticate", mock.Anything, "dm_admin").Return(admin, nil)
+	svc.On("Authenticate", mock.Anything, "dm_reader").Return(depsmanager.Identity{Name: "ci", Role: depsmanager.RoleReader}, nil)
+	svc.On("Authenticate", mock.Anything, "").Return(depsmanager.Identity{}, depsmanager.ErrUnauthorized)
+	svc.On("CreateAPIKey", mock.Anything, "ci", depsmanager.RoleReader, "").
+		Return(depsmanager.CreatedAPIKey{APIKey: depsmanager.APIKey{ID: 1, Name: "ci", Role: depsmanager.RoleReader}, Key: "dm_reader"}, nil).Once()
+	svc.On("ListAPIKeys", mock.Anything).Return([]depsmanager.APIKey{{ID: 1, Name: "ci", Role: depsmanager.RoleReader}}, nil).Once()
+	svc.On("RevokeAPIKey", mock.Anything, int64(1)).Return(nil).Once()
+	svc.On("RevokeAPIKey", mock.Anything, int64(2)).Return(depsmanager.ErrAPIKeyNotFound).Once()
+
+	c := New(srv.URL, WithAPIKey("dm_admin"))
+	key, err := c.CreateAPIKey(ctx, depsmanager.CreateAPIKeyRequest{Name: "ci", Role: depsmanager.RoleReader})
+	require.NoError(t, err)
+	assert.Equal(t, "dm_reader", key.Key)
+
+	keys, err := c.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	require.NoError(t, c.RevokeAPIKey(ctx, 1))
+	require.ErrorIs(t, c.RevokeAPIKey(ctx, 2), depsmanager.ErrAPIKeyNotFound)
+
+	_, err = New(srv.URL, WithAPIKey("dm_reader")).ListAPIKeys(ctx)
+	require.ErrorIs(t, err, depsmanager.ErrForbidden)
+
+	_, err = New(srv.URL).ListProjects(ctx, "")
+	require.ErrorIs(t, err, depsmanager.ErrUnauthorized)
+	svc.AssertExpectations(t)
+}
+
+func TestClient_Tenants(t *testing.T) {
+	c, svc := setup(t, WithTenant("team-a"))
+	ctx := context.Background()
+
+	svc.On("GetTenant", mock.Anything, "team-a").Return(depsmanager.Tenant{Name: "team-a"}, nil)
+	svc.On("CreateTenant", mock.Anything, "team-b").Return(depsmanager.Tenant{Name: "team-b"}, nil).Once()
+	svc.On("ListTenants", mock.Anything).Return([]depsmanager.Tenant{{Name: "team-a"}, {Name: "team-b"}}, nil).Once()
+	svc.On("ListProjects", mock.MatchedBy(func(ctx context.Context) bool { return tenant.FromContext(ctx) == "team-a" }), mock.Anything).
+		Return([]depsmanager.Project{{Tenant: "team-a", Name: "react"}}, nil).Once()
+
+	created, err := c.CreateTenant(ctx, "team-b")
+	require.NoError(t, err)
+	assert.Equal(t, "team-b", created.Name)
+
+	tenants, err := c.ListTenants(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tenants, 2)
+
+	projects, err := c.ListProjects(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, "team-a", projects[0].Tenant)
+	svc.AssertExpectations(t)
+}
+
+func TestClient_ListAudit(t *testing.T) {
+	c, svc := setup(t)
+	ctx := context.Background()
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC).Unix()
+	next := int64(3)
+
+	svc.On("ListAudit", mock.Anything, depsmanager.AuditFilter{ProjectName: "@babel/core", From: from, Limit: 2, BeforeID: 10}).
+		Return(depsmanager.AuditPage{Entries: []depsmanager.AuditEntry{{ID: 4}, {ID: 3}}, NextBeforeID: &next}, nil).Once()
+
+	page, err := c.ListAudit(ctx, depsmanager.AuditFilter{ProjectName: "@babel/core", From: from, Limit: 2, BeforeID: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Entries, 2)
+	assert.Equal(t, &next, page.NextBeforeID)
+	svc.AssertExpectations(t)
+}
+
+func TestClient_Suppressions(t *testing.T) {
+	c, svc := setup(t)
+	ctx := context.Background()
+	req := depsmanager.CreateSuppressionRequest{
+		DependencyName: "lodash", Justification: "not reachable", Approver: "security",
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}
+
+	svc.On("CreateSuppression", mock.Anything, req).Return(depsmanager.Suppression{ID: 1, DependencyName: "lodash"}, nil).Once()
+	svc.On("ListSuppressions", mock.Anything).Return([]depsmanager.Suppression{{ID: 1, DependencyName: "lodash"}}, nil).Once()
+	svc.On("DeleteSuppression", mock.Anything, int64(1)).Return(nil).Once()
+	svc.On("DeleteSuppression", mock.Anything, int64(2)).Return(depsmanager.ErrSuppressionNotFound).Once()
+
+	created, err := c.CreateSuppression(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), created.ID)
+
+	suppressions, err := c.ListSuppressions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, suppressions, 1)
+
+	require.NoError(t, c.DeleteSuppression(ctx, 1))
+	require.ErrorIs(t, c.DeleteSuppression(ctx, 2), depsmanager.ErrSuppressionNotFound)
+	svc.AssertExpectations(t)
+}
+
+func TestClient_ProjectMetadata(t *testing.T) {
+	c, svc := setup(t)
+	ctx := context.Background()
+	req := depsmanager.ProjectMetadataRequest{Owner: "babel", Labels: map[string]string{"team": "build"}}
+	saved := depsmanager.ProjectMetadata{ProjectName: "@babel/core", Owner: "babel", Labels: req.Labels, UpdatedAt: 1}
+	bySelector := mock.MatchedBy(func(s labels.Selector) bool { return s.String() == "team=build,env!=dev" })
+
+	svc.On("SetProjectMetadata", mock.Anything, "@babel/core", req).Return(saved, nil).Once()
+	svc.On("GetProjectMetadata", mock.Anything, "@babel/core").Return(saved, nil).Once()
+	svc.On("DeleteProjectMetadata", mock.Anything, "@babel/core").Return(nil).Once()
+	svc.On("GetProjectMetadata", mock.Anything, "missing").Return(depsmanager.ProjectMetadata{}, depsmanager.ErrProjectNotFound).Once()
+	svc.On("ListProjects", mock.Anything, bySelector).Return([]depsmanager.Project{{Name: "@babel/core", Labels: req.Labels}}, nil).Once()
+	svc.On("GetPortfolioSummary", mock.Anything, bySelector).Return(depsmanager.RiskSummary{DependencyCount: 3}, nil).Once()
+
+	got, err := c.SetProjectMetadata(ctx, "@babel/core", req)
+	require.NoError(t, err)
+	assert.Equal(t, saved, got)
+
+	got, err = c.GetProjectMetadata(ctx, "@babel/core")
+	require.NoError(t, err)
+	assert.Equal(t, saved, got)
+	require.NoError(t, c.DeleteProjectMetadata(ctx, "@babel/core"))
+	_, err = c.GetProjectMetadata(ctx, "missing")
+	require.ErrorIs(t, err, depsmanager.ErrProjectNotFound)
+
+	projects, err := c.ListProjects(ctx, "team=build,env!=dev")
+	require.NoError(t, err)
+	assert.Len(t, projects, 1)
+	summary, err := c.PortfolioSummary(ctx, "team=build,env!=dev")
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.DependencyCount)
+	svc.AssertExpectations(t)
+}
@@ -0,0 +1,420 @@
+package client
+
+import (
+	"context"
+	"depsmanager"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// FetchProject fetches dependencies of project version from deps.dev and stores them.
+func (c *Client) FetchProject(ctx context.Context, projectName, version string) error {
+	r, err := jsonRequest(http.MethodPost, "/v1/projects", depsmanager.ProjectRequest{ProjectName: projectName, Version: version}, depsmanager.ErrProjectNotFound)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, r, nil)
+}
+
+func (c *Client) DeleteProject(ctx context.Context, projectName, version string) error {
+	r, err := jsonRequest(http.MethodDelete, "/v1/projects", depsmanager.ProjectRequest{ProjectName: projectName, Version: version}, depsmanager.ErrProjectNotFound)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, r, nil)
+}
+
+// ListProjects lists stored projects, only those matching label selector (e.g. "team=payments,env=prod") when not empty.
+func (c *Client) ListProjects(ctx context.Context, selector string) ([]depsmanager.Project, error) {
+	r, _ := jsonRequest(http.MethodGet, "/v1/projects"+selectorQuery(selector), nil, depsmanager.ErrProjectNotFound)
+
+	projects := []depsmanager.Project{}
+	if err := c.do(ctx, r, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// ProjectVersions lists versions of project available in deps.dev.
+func (c *Client) ProjectVersions(ctx context.Context, projectName string) ([]string, error) {
+	r, _ := jsonRequest(http.MethodGet, "/v1/projects/versions?project_name="+url.QueryEscape(projectName), nil, depsmanager.ErrProjectNotFound)
+
+	versions := []string{}
+	if err := c.do(ctx, r, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (c *Client) ListDependencies(ctx context.Context, projectName, version string) (depsmanager.ListDependenciesResponse, error) {
+	r, err := jsonRequest(http.MethodPost, "/v1/dependencies", depsmanager.ProjectRequest{ProjectName: projectName, Version: version}, depsmanager.ErrProjectNotFound)
+	if err != nil {
+		return depsmanager.ListDependenciesResponse{}, err
+	}
+
+	var resp depsmanager.ListDependenciesResponse
+	if err := c.do(ctx, r, &resp); err != nil {
+		return depsmanager.ListDependenciesResponse{}, err
+	}
+	return resp, nil
+}
+
+func (c *Client) ProjectSummary(ctx context.Context, projectName, version string) (depsmanager.RiskSummary, error) {
+	r, err := jsonRequest(http.MethodPost, "/v1/dependencies/summary", depsmanager.ProjectRequest{ProjectName: projectName, Version: version}, depsmanager.ErrProjectNotFound)
+	if err != nil {
+		return depsmanager.RiskSummary{}, err
+	}
+
+	var resp depsmanager.RiskSummary
+	if err := c.do(ctx, r, &resp); err != nil {
+		return depsmanager.RiskSummary{}, err
+	}
+	return resp, nil
+}
+
+// PortfolioSummary summarizes dependencies of all projects, only those matching label selector when not empty.
+func (c *Client) PortfolioSummary(ctx context.Context, selector string) (depsmanager.RiskSummary, error) {
+	r, _ := jsonRequest(http.MethodGet, "/v1/dependencies/summary"+selectorQuery(selector), nil, depsmanager.ErrProjectNotFound)
+
+	var resp depsmanager.RiskSummary
+	if err := c.do(ctx, r, &resp); err != nil {
+		return depsmanager.RiskSummary{}, err
+	}
+	return resp, nil
+}
+
+// AddDependency adds dependency to project version, it is never retried.
+func (c *Client) AddDependency(ctx context.Context, req depsmanager.DependencyRequest) error {
+	r, err := jsonRequest(http.MethodPost, "/v1/dependencies/new", req, depsmanager.ErrProjectNotFound)
+	if err != nil {
+		return err
+	}
+	r.idempotent = false
+	return c.do(ctx, r, nil)
+}
+
+// ModifyDependency changes score of manual dependency or overrides score of fetched one, see req.ExpiresAt.
+func (c *Client) ModifyDependency(ctx context.Context, req depsmanager.DependencyRequest) error {
+	r, err := jsonRequest(http.MethodPatch, "/v1/dependencies/modify", req, depsmanager.ErrProjectNotFound)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, r, nil)
+}
+
+// ClearScoreOverride makes score of fetched dependency follow upstream again.
+func (c *Client) ClearScoreOverride(ctx context.Context, projectName, version, dependencyName string) error {
+	path := "/v2/projects/" + url.PathEscape(projectName) + "/versions/" + url.PathEscape(version) +
+		"/dependencies/" + url.PathEscape(dependencyName) + "/override"
+	r, _ := jsonRequest(http.MethodDelete, path, nil, depsmanager.ErrDependencyNotFound)
+	return c.do(ctx, r, nil)
+}
+
+func (c *Client) DeleteDependency(ctx context.Context, req depsmanager.RemoveDependencyRequest) error {
+	r, err := jsonRequest(http.MethodDelete, "/v1/dependencies/delete", req, depsmanager.ErrProjectNotFound)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, r, nil)
+}
+
+// ProjectsByDependency lists stored projects using dependency.
+func (c *Client) ProjectsByDependency(ctx context.Context, dependencyName string) ([]depsmanager.Project, error) {
+	r, err := jsonRequest(http.MethodPost, "/v1/dependencies/byprojectname", depsmanager.GetProjectNameByDepNameReq{DependencyName: dependencyName}, depsmanager.ErrProjectNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := []depsmanager.Project{}
+	if err := c.do(ctx, r, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// DependenciesByScore lists names of dependencies with exactly the given score.
+func (c *Client) DependenciesByScore(ctx context.Context, score float64) ([]string, error) {
+	r, err := jsonRequest(http.MethodPost, "/v1/dependencies/byscore", depsmanager.GetDependenciesByScore{Score: score}, depsmanager.ErrProjectNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	if err := c.do(ctx, r, &names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// SavePolicy creates or replaces policy defined by YAML document.
+// Invalid document is reported as ErrBadRequest.
+func (c *Client) SavePolicy(ctx context.Context, document []byte) (depsmanager.Policy, error) {
+	r := request{
+		method:      http.MethodPost,
+		path:        "/v1/policies",
+		contentType: "application/yaml",
+		body:        document,
+		idempotent:  true,
+		notFound:    depsmanager.ErrPolicyNotFound,
+	}
+
+	var p depsmanager.Policy
+	if err := c.do(ctx, r, &p); err != nil {
+		return depsmanager.Policy{}, err
+	}
+	return p, nil
+}
+
+// ListPolicies lists global policies and policies of projectName, if given.
+func (c *Client) ListPolicies(ctx context.Context, projectName string) ([]depsmanager.Policy, error) {
+	path := "/v1/policies"
+	if projectName != "" {
+		path += "?project_name=" + url.QueryEscape(projectName)
+	}
+	r, _ := jsonRequest(http.MethodGet, path, nil, depsmanager.ErrPolicyNotFound)
+
+	policies := []depsmanager.Policy{}
+	if err := c.do(ctx, r, &policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// DeletePolicy deletes policy of projectName, empty projectName deletes a global policy.
+func (c *Client) DeletePolicy(ctx context.Context, projectName, name string) error {
+	r, err := jsonRequest(http.MethodDelete, "/v1/policies", depsmanager.DeletePolicyRequest{Name: name, ProjectName: projectName}, depsmanager.ErrPolicyNotFound)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, r, nil)
+}
+
+func (c *Client) EvaluatePolicies(ctx context.Context, projectName, version string) (depsmanager.PolicyEvaluation, error) {
+	r, err := jsonRequest(http.MethodPost, "/v1/policies/evaluate", depsmanager.ProjectRequest{ProjectName: projectName, Version: version}, depsmanager.ErrProjectNotFound)
+	if err != nil {
+		return depsmanager.PolicyEvaluation{}, err
+	}
+
+	var eval depsmanager.PolicyEvaluation
+	if err := c.do(ctx, r, &eval); err != nil {
+		return depsmanager.PolicyEvaluation{}, err
+	}
+	return eval, nil
+}
+
+// PolicyEvaluation returns the latest policy evaluation of project version.
+// 404 is reported as depsmanager.ErrPolicyEvaluationNotFound, also when the project does not exist.
+func (c *Client) PolicyEvaluation(ctx context.Context, projectName, version string) (depsmanager.PolicyEvaluation, error) {
+	r, err := jsonRequest(http.MethodPost, "/v1/policies/evaluation", depsmanager.ProjectRequest{ProjectName: projectName, Version: version}, depsmanager.ErrPolicyEvaluationNotFound)
+	if err != nil {
+		return depsmanager.PolicyEvaluation{}, err
+	}
+
+	var eval depsmanager.PolicyEvaluation
+	if err := c.do(ctx, r, &eval); err != nil {
+		return depsmanager.PolicyEvaluation{}, err
+	}
+	return eval, nil
+}
+
+// Gate checks package-lock.json against thresholds, see depsmanager.GateRequest.
+func (c *Client) Gate(ctx context.Context, lockfile []byte, req depsmanager.GateRequest) (depsmanager.GateResult, error) {
+	q := url.Values{}
+	if req.BaselineProject != "" {
+		q.Set("baseline_project", req.BaselineProject)
+		q.Set("baseline_version", req.BaselineVersion)
+	}
+	if req.Thresholds.MinScore != nil {
+		q.Set("min_score", strconv.FormatFloat(*req.Thresholds.MinScore, 'f', -1, 64))
+	}
+	if req.Thresholds.MaxUnscored != nil {
+		q.Set("max_unscored", strconv.Itoa(*req.Thresholds.MaxUnscored))
+	}
+	if req.Thresholds.MaxScorecardAgeDays != nil {
+		q.Set("max_scorecard_age_days", strconv.Itoa(*req.Thresholds.MaxScorecardAgeDays))
+	}
+
+	path := "/v1/gate"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	r := request{
+		method:      http.MethodPost,
+		path:        path,
+		contentType: "application/json",
+		body:        lockfile,
+		idempotent:  true,
+		notFound:    depsmanager.ErrProjectNotFound,
+	}
+
+	var res depsmanager.GateResult
+	if err := c.do(ctx, r, &res); err != nil {
+		return depsmanager.GateResult{}, err
+	}
+	return res, nil
+}
+
+// CreateAPIKey creates api key, the key is returned only once. Requires admin role.
+func (c *Client) CreateAPIKey(ctx context.Context, req depsmanager.CreateAPIKeyRequest) (depsmanager.CreatedAPIKey, error) {
+	r, err := jsonRequest(http.MethodPost, "/v2/keys", req, depsmanager.ErrTenantNotFound)
+	if err != nil {
+		return depsmanager.CreatedAPIKey{}, err
+	}
+	r.idempotent = false
+
+	var key depsmanager.CreatedAPIKey
+	if err := c.do(ctx, r, &key); err != nil {
+		return depsmanager.CreatedAPIKey{}, err
+	}
+	return key, nil
+}
+
+func (c *Client) ListAPIKeys(ctx context.Context) ([]depsmanager.APIKey, error) {
+	r, _ := jsonRequest(http.MethodGet, "/v2/keys", nil, depsmanager.ErrAPIKeyNotFound)
+
+	keys := []depsmanager.APIKey{}
+	if err := c.do(ctx, r, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (c *Client) RevokeAPIKey(ctx context.Context, id int64) error {
+	r, _ := jsonRequest(http.MethodDelete, "/v2/keys/"+strconv.FormatInt(id, 10), nil, depsmanager.ErrAPIKeyNotFound)
+	return c.do(ctx, r, nil)
+}
+
+// CreateTenant creates tenant. Requires admin role.
+func (c *Client) CreateTenant(ctx context.Context, name string) (depsmanager.Tenant, error) {
+	r, err := jsonRequest(http.MethodPost, "/v2/tenants", depsmanager.CreateTenantRequest{Name: name}, depsmanager.ErrTenantNotFound)
+	if err != nil {
+		return depsmanager.Tenant{}, err
+	}
+	r.idempotent = false
+
+	var t depsmanager.Tenant
+	if err := c.do(ctx, r, &t); err != nil {
+		return depsmanager.Tenant{}, err
+	}
+	return t, nil
+}
+
+func (c *Client) ListTenants(ctx context.Context) ([]depsmanager.Tenant, error) {
+	r, _ := jsonRequest(http.MethodGet, "/v2/tenants", nil, depsmanager.ErrTenantNotFound)
+
+	tenants := []depsmanager.Tenant{}
+	if err := c.do(ctx, r, &tenants); err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+// ListAudit returns a page of audit entries matching filter, newest first. Requires admin role.
+func (c *Client) ListAudit(ctx context.Context, filter depsmanager.AuditFilter) (depsmanager.AuditPage, error) {
+	q := url.Values{}
+	for key, value := range map[string]string{
+		"actor":           filter.Actor,
+		"action":          filter.Action,
+		"project_name":    filter.ProjectName,
+		"version":         filter.Version,
+		"dependency_name": filter.DependencyName,
+	} {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	if filter.From > 0 {
+		q.Set("from", time.Unix(filter.From, 0).UTC().Format(time.RFC3339))
+	}
+	if filter.To > 0 {
+		q.Set("to", time.Unix(filter.To, 0).UTC().Format(time.RFC3339))
+	}
+	if filter.Limit > 0 {
+		q.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.BeforeID > 0 {
+		q.Set("before_id", strconv.FormatInt(filter.BeforeID, 10))
+	}
+
+	path := "/v2/audit"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	r, _ := jsonRequest(http.MethodGet, path, nil, depsmanager.ErrTenantNotFound)
+
+	var page depsmanager.AuditPage
+	if err := c.do(ctx, r, &page); err != nil {
+		return depsmanager.AuditPage{}, err
+	}
+	return page, nil
+}
+
+// CreateSuppression accepts risk of a dependency until req.ExpiresAt. Requires editor role.
+func (c *Client) CreateSuppression(ctx context.Context, req depsmanager.CreateSuppressionRequest) (depsmanager.Suppression, error) {
+	r, err := jsonRequest(http.MethodPost, "/v2/suppressions", req, depsmanager.ErrSuppressionNotFound)
+	if err != nil {
+		return depsmanager.Suppression{}, err
+	}
+	r.idempotent = false
+
+	var s depsmanager.Suppression
+	if err := c.do(ctx, r, &s); err != nil {
+		return depsmanager.Suppression{}, err
+	}
+	return s, nil
+}
+
+// ListSuppressions returns suppressions of tenant including expired ones.
+func (c *Client) ListSuppressions(ctx context.Context) ([]depsmanager.Suppression, error) {
+	r, _ := jsonRequest(http.MethodGet, "/v2/suppressions", nil, depsmanager.ErrSuppressionNotFound)
+
+	suppressions := []depsmanager.Suppression{}
+	if err := c.do(ctx, r, &suppressions); err != nil {
+		return nil, err
+	}
+	return suppressions, nil
+}
+
+func (c *Client) DeleteSuppression(ctx context.Context, id int64) error {
+	r, _ := jsonRequest(http.MethodDelete, "/v2/suppressions/"+strconv.FormatInt(id, 10), nil, depsmanager.ErrSuppressionNotFound)
+	return c.do(ctx, r, nil)
+}
+
+// GetProjectMetadata returns owner, description, repository URL and labels of project.
+func (c *Client) GetProjectMetadata(ctx context.Context, projectName string) (depsmanager.ProjectMetadata, error) {
+	r, _ := jsonRequest(http.MethodGet, "/v2/projects/"+url.PathEscape(projectName)+"/metadata", nil, depsmanager.ErrProjectNotFound)
+
+	var m depsmanager.ProjectMetadata
+	if err := c.do(ctx, r, &m); err != nil {
+		return depsmanager.ProjectMetadata{}, err
+	}
+	return m, nil
+}
+
+// SetProjectMetadata replaces metadata of project. Requires editor role.
+func (c *Client) SetProjectMetadata(ctx context.Context, projectName string, req depsmanager.ProjectMetadataRequest) (depsmanager.ProjectMetadata, error) {
+	r, err := jsonRequest(http.MethodPut, "/v2/projects/"+url.PathEscape(projectName)+"/metadata", req, depsmanager.ErrProjectNotFound)
+	if err != nil {
+		return depsmanager.ProjectMetadata{}, err
+	}
+
+	var m depsmanager.ProjectMetadata
+	if err := c.do(ctx, r, &m); err != nil {
+		return depsmanager.ProjectMetadata{}, err
+	}
+	return m, nil
+}
+
+func (c *Client) DeleteProjectMetadata(ctx context.Context, projectName string) error {
+	r, _ := jsonRequest(http.MethodDelete, "/v2/projects/"+url.PathEscape(projectName)+"/metadata", nil, depsmanager.ErrProjectNotFound)
+	return c.do(ctx, r, nil)
+}
+
+func selectorQuery(selector string) string {
+	if selector == "" {
+		return ""
+	}
+	return "?selector=" + url.QueryEscape(selector)
+}
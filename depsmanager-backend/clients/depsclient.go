@@ -3,140 +3,254 @@ package clients
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"depsmanager"
+	"depsmanager/pkg/trace"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
 )
 
+// defaultUserAgent is sent when config has none.
+const defaultUserAgent = "depsmanager"
+
 type DepsClient struct {
-	address string
-	client  *http.Client
+	// mirrors are base URLs without trailing slash, the configured address first
+	mirrors   []string
+	client    *http.Client
+	userAgent string
+	timeouts  depsmanager.DepsTimeouts
+	observe   func(mirror, result string)
 }
 
-func NewDepsClient(address string) *DepsClient {
-	return &DepsClient{address: address, client: &http.Client{}}
-}
+// Results of a mirror attempt passed to the mirror observer.
+const (
+	MirrorAnswered = "answered"
+	MirrorFailed   = "failed"
+)
 
-func (c *DepsClient) GetProjectVersions(ctx context.Context, project string) (*depsmanager.DepsGetVersionResp, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v3/systems/NPM/packages/%s", c.address, url.PathEscape(project)), nil)
-	if err != nil {
-		return nil, fmt.Errorf("http.NewRequestWithContext(): %v", err)
+// WithMirrorObserver calls observe after every attempt with the mirror base URL and MirrorAnswered
+// or MirrorFailed, failed attempts are followed by the next mirror.
+func WithMirrorObserver(observe func(mirror, result string)) func(c *DepsClient) {
+	return func(c *DepsClient) {
+		c.observe = observe
 	}
+}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("c.client.Do(): %v", err)
+// NewDepsClient returns client of conf. It sends traceparent of the span in request context, see trace.Transport.
+func NewDepsClient(conf depsmanager.DepsConfig, opts ...func(c *DepsClient)) (*DepsClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: conf.DepsTimeouts.Connect, KeepAlive: 30 * time.Second}).DialContext
+	if conf.DepsProxy != "" {
+		proxy, err := url.Parse(conf.DepsProxy)
+		if err != nil {
+			return nil, fmt.Errorf("url.Parse(): %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
 	}
-	switch resp.StatusCode {
-	case http.StatusOK:
-		var data depsmanager.DepsGetVersionResp
-		if err = json.NewDecoder(resp.Body).Decode(&data); err != nil {
-			return nil, fmt.Errorf("json.NewDecoder(): %v", err)
+	if len(conf.DepsCAFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
 		}
+		for _, name := range conf.DepsCAFiles {
+			b, err := os.ReadFile(name)
+			if err != nil {
+				return nil, fmt.Errorf("os.ReadFile(): %w", err)
+			}
+			if !pool.AppendCertsFromPEM(b) {
+				return nil, fmt.Errorf("no PEM certificates in %s", name)
+			}
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
 
-		return &data, nil
-	case http.StatusNotFound:
-		return nil, depsmanager.ErrProjectNotFound
+	c := &DepsClient{
+		client:    &http.Client{Transport: trace.Transport(transport)},
+		userAgent: conf.DepsUserAgent,
+		timeouts:  conf.DepsTimeouts,
+	}
+	if c.userAgent == "" {
+		c.userAgent = defaultUserAgent
+	}
+	for _, address := range append([]string{conf.DepsAddress}, conf.DepsMirrors...) {
+		c.mirrors = append(c.mirrors, strings.TrimSuffix(address, "/"))
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
 
-	return nil, fmt.Errorf("bad response: %d", resp.StatusCode)
+// Ping checks deps.dev or one of the mirrors answers, any HTTP response counts.
+func (c *DepsClient) Ping(ctx context.Context) error {
+	return c.do(ctx, c.timeouts.Ping, http.MethodHead, "", nil, nil)
+}
 
+func (c *DepsClient) GetProjectVersions(ctx context.Context, project string) (*depsmanager.DepsGetVersionResp, error) {
+	var data depsmanager.DepsGetVersionResp
+	path := fmt.Sprintf("/v3/systems/NPM/packages/%s", url.PathEscape(project))
+	if err := c.do(ctx, c.timeouts.Versions, http.MethodGet, path, nil, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
 }
 
 func (c *DepsClient) GetProjectDependencies(ctx context.Context, system, project, version string) (*depsmanager.DepsProjectDependenciesResp, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v3/systems/%s/packages/%s/versions/%s:dependencies", c.address, system, url.PathEscape(project), version), nil)
-	if err != nil {
-		return nil, fmt.Errorf("http.NewRequestWithContext(): %v", err)
+	var data depsmanager.DepsProjectDependenciesResp
+	path := fmt.Sprintf("/v3/systems/%s/packages/%s/versions/%s:dependencies", system, url.PathEscape(project), version)
+	if err := c.do(ctx, c.timeouts.Dependencies, http.MethodGet, path, nil, &data); err != nil {
+		return nil, err
 	}
+	return &data, nil
+}
 
-	resp, err := c.client.Do(req)
+func (c *DepsClient) GetVersionsBatch(ctx context.Context, projects []depsmanager.ProjectDependencies) (*depsmanager.DepsGetVersionsBatchResp, error) {
+	var v depsmanager.DepsGetVersionsBatchReq
+	for _, p := range projects {
+		v.Requests = append(v.Requests, depsmanager.DepsGetVersionKey{VersionKey: p})
+	}
+	jsonValue, err := json.Marshal(v)
 	if err != nil {
-		return nil, fmt.Errorf("c.client.Do(): %v", err)
+		return nil, fmt.Errorf("json.Marshal(): %v", err)
 	}
-	switch resp.StatusCode {
-	case http.StatusOK:
-		var data depsmanager.DepsProjectDependenciesResp
-		if err = json.NewDecoder(resp.Body).Decode(&data); err != nil {
-			return nil, fmt.Errorf("json.NewDecoder(): %v", err)
-		}
 
-		return &data, nil
-	case http.StatusNotFound:
-		return nil, depsmanager.ErrProjectNotFound
+	var data depsmanager.DepsGetVersionsBatchResp
+	if err := c.do(ctx, c.timeouts.Batch, http.MethodPost, "/v3alpha/versionbatch", jsonValue, &data); err != nil {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("bad response: %d", resp.StatusCode)
-
+	return &data, nil
 }
 
-func (c *DepsClient) GetVersionsBatch(ctx context.Context, projects []depsmanager.ProjectDependencies) (*depsmanager.DepsGetVersionsBatchResp, error) {
+func (c *DepsClient) GetProjectsBatch(ctx context.Context, projects []string) (*depsmanager.DepsGetProjectBatchResp, error) {
 	var v depsmanager.DepsGetVersionsBatchReq
-	for _, p := range projects {
-		v.Requests = append(v.Requests, depsmanager.DepsGetVersionKey{VersionKey: p})
+	for _, id := range projects {
+		v.Requests = append(v.Requests, depsmanager.DepsProjectBatch{ProjectKey: struct {
+			ID string `json:"id"`
+		}{ID: id}})
 	}
-
 	jsonValue, err := json.Marshal(v)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v3alpha/versionbatch", c.address), bytes.NewReader(jsonValue))
 	if err != nil {
-		return nil, fmt.Errorf("http.NewRequestWithContext(): %v", err)
+		return nil, fmt.Errorf("json.Marshal(): %v", err)
 	}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("c.client.Do(): %v", err)
+	var data depsmanager.DepsGetProjectBatchResp
+	if err := c.do(ctx, c.timeouts.Batch, http.MethodPost, "/v3alpha/projectbatch", jsonValue, &data); err != nil {
+		return nil, err
 	}
-	switch resp.StatusCode {
-	case http.StatusOK:
-		var data depsmanager.DepsGetVersionsBatchResp
+	return &data, nil
+}
 
-		if err = json.NewDecoder(resp.Body).Decode(&data); err != nil {
-			return nil, fmt.Errorf("json.NewDecoder(): %v", err)
-		}
+// statusError is an unexpected response status.
+type statusError struct {
+	code int
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("bad response: %d", e.code)
+}
 
-		return &data, nil
-	case http.StatusNotFound:
-		return nil, depsmanager.ErrProjectNotFound
+// failover reports whether the next mirror may answer err: the mirror is unreachable, timed out,
+// overloaded or failing. Not found and other client errors would be the same everywhere.
+func failover(err error) bool {
+	var se statusError
+	if errors.As(err, &se) {
+		return se.code >= http.StatusInternalServerError || se.code == http.StatusTooManyRequests
 	}
+	return !errors.Is(err, depsmanager.ErrProjectNotFound)
+}
 
-	return nil, fmt.Errorf("bad response: %d", resp.StatusCode)
+// do sends the request to the mirrors in order until one answers, out is decoded from 200 response.
+// Every attempt is bounded by timeout and decodes into a fresh value, so a mirror failing mid-body
+// leaves nothing in out. The answering mirror is set on the span of ctx as deps.mirror, logged at debug
+// level and, with a failover, at info level.
+func (c *DepsClient) do(ctx context.Context, timeout time.Duration, method, path string, body []byte, out any) error {
+	var errs []error
+	for i, mirror := range c.mirrors {
+		var fresh any
+		if out != nil {
+			fresh = reflect.New(reflect.TypeOf(out).Elem()).Interface()
+		}
+		err := c.attempt(ctx, timeout, method, mirror+path, body, fresh)
+		if err == nil || !failover(err) {
+			if err == nil && out != nil {
+				reflect.ValueOf(out).Elem().Set(reflect.ValueOf(fresh).Elem())
+			}
+			c.observeMirror(mirror, MirrorAnswered)
+			trace.SpanFromContext(ctx).SetAttributes(trace.String("deps.mirror", mirror))
+			level := slog.LevelDebug
+			if i > 0 {
+				level = slog.LevelInfo
+			}
+			slog.Log(ctx, level, "deps.dev mirror answered", "mirror", mirror, "path", path, "attempt", i+1)
+			return err
+		}
 
+		c.observeMirror(mirror, MirrorFailed)
+		errs = append(errs, fmt.Errorf("%s: %w", mirror, err))
+		if ctx.Err() != nil {
+			break
+		}
+		if i < len(c.mirrors)-1 {
+			slog.WarnContext(ctx, "deps.dev mirror failed, trying the next one", "mirror", mirror, "error", err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
-func (c *DepsClient) GetProjectsBatch(ctx context.Context, projects []string) (*depsmanager.DepsGetProjectBatchResp, error) {
-	var v depsmanager.DepsGetVersionsBatchReq
-	for _, id := range projects {
-		v.Requests = append(v.Requests, depsmanager.DepsProjectBatch{ProjectKey: struct {
-			ID string `json:"id"`
-		}{ID: id}})
+func (c *DepsClient) observeMirror(mirror, result string) {
+	if c.observe != nil {
+		c.observe(mirror, result)
 	}
+}
 
-	jsonValue, err := json.Marshal(v)
+func (c *DepsClient) attempt(ctx context.Context, timeout time.Duration, method, url string, body []byte, out any) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v3alpha/projectbatch", c.address), bytes.NewReader(jsonValue))
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
 	if err != nil {
-		return nil, fmt.Errorf("http.NewRequestWithContext(): %v", err)
+		return fmt.Errorf("http.NewRequestWithContext(): %v", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("c.client.Do(): %v", err)
+		return fmt.Errorf("c.client.Do(): %w", err)
 	}
-	switch resp.StatusCode {
-	case http.StatusOK:
-		var data depsmanager.DepsGetProjectBatchResp
+	defer resp.Body.Close()
 
-		if err = json.NewDecoder(resp.Body).Decode(&data); err != nil {
-			return nil, fmt.Errorf("json.NewDecoder(): %v", err)
+	switch {
+	case out == nil:
+		// ping, any answer counts
+		return nil
+	case resp.StatusCode == http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("json.NewDecoder(): %w", err)
 		}
-
-		return &data, nil
-	case http.StatusNotFound:
-		return nil, depsmanager.ErrProjectNotFound
+		return nil
+	case resp.StatusCode == http.StatusNotFound:
+		return depsmanager.ErrProjectNotFound
 	}
-
-	return nil, fmt.Errorf("bad response: %d", resp.StatusCode)
-
+	return statusError{code: resp.StatusCode}
 }
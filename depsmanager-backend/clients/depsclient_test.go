@@ -0,0 +1,195 @@
+package clients
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/trace"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deps answers GetProjectVersions with status, counting requests and keeping the last User-Agent.
+type deps struct {
+	*httptest.Server
+	requests  atomic.Int32
+	userAgent atomic.Value
+}
+
+func newDeps(t *testing.T, status int, delay time.Duration) *deps {
+	t.Helper()
+	d := &deps{}
+	d.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.requests.Add(1)
+		d.userAgent.Store(r.UserAgent())
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			_, _ = w.Write([]byte(`{"versions":[{"versionKey":{"version":"18.3.1"},"isDefault":true}]}`))
+		}
+	}))
+	t.Cleanup(d.Close)
+	return d
+}
+
+func conf(address string, mirrors ...string) depsmanager.DepsConfig {
+	c := depsmanager.DefaultConfig().DepsConfig
+	c.DepsAddress, c.DepsMirrors = address, mirrors
+	return c
+}
+
+func TestDepsClient_Failover(t *testing.T) {
+	tests := []struct {
+		name           string
+		primaryStatus  int
+		primaryDelay   time.Duration
+		wantErr        error
+		wantMirrorUsed bool
+	}{
+		{name: "primary answers", primaryStatus: http.StatusOK},
+		{name: "server error", primaryStatus: http.StatusBadGateway, wantMirrorUsed: true},
+		{name: "throttled", primaryStatus: http.StatusTooManyRequests, wantMirrorUsed: true},
+		{name: "timeout", primaryStatus: http.StatusOK, primaryDelay: time.Second, wantMirrorUsed: true},
+		{name: "not found is final", primaryStatus: http.StatusNotFound, wantErr: depsmanager.ErrProjectNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := newDeps(t, tt.primaryStatus, tt.primaryDelay)
+			mirror := newDeps(t, http.StatusOK, 0)
+			cfg := conf(primary.URL, mirror.URL+"/")
+			cfg.DepsTimeouts.Versions = 100 * time.Millisecond
+			c, err := NewDepsClient(cfg)
+			require.NoError(t, err)
+
+			exp := trace.NewInMemoryExporter()
+			ctx, span := trace.NewTracer(exp).Start(context.Background(), "call", trace.KindClient)
+			resp, err := c.GetProjectVersions(ctx, "react")
+			span.End()
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Zero(t, mirror.requests.Load())
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, resp.Versions, 1)
+
+			answered := primary.URL
+			if tt.wantMirrorUsed {
+				answered = mirror.URL
+				assert.Equal(t, int32(1), mirror.requests.Load())
+			} else {
+				assert.Zero(t, mirror.requests.Load())
+			}
+			data, _ := exp.Span("call")
+			assert.Equal(t, answered, data.Attribute("deps.mirror"))
+		})
+	}
+}
+
+func TestDepsClient_FailoverDecodesFreshValue(t *testing.T) {
+	body := func(s string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(s))
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	// the primary body decodes partly before the type error, the mirror knows no versions
+	primary := body(`{"versions":[{"versionKey":{"version":"0.0.1"},"isDefault":"yes"}]}`)
+	mirror := body(`{}`)
+
+	var observed []string
+	c, err := NewDepsClient(conf(primary.URL, mirror.URL), WithMirrorObserver(func(mirror, result string) {
+		observed = append(observed, mirror+" "+result)
+	}))
+	require.NoError(t, err)
+
+	resp, err := c.GetProjectVersions(context.Background(), "react")
+	require.NoError(t, err)
+	assert.Empty(t, resp.Versions)
+	assert.Equal(t, []string{primary.URL + " " + MirrorFailed, mirror.URL + " " + MirrorAnswered}, observed)
+}
+
+func TestDepsClient_AllMirrorsFail(t *testing.T) {
+	primary := newDeps(t, http.StatusServiceUnavailable, 0)
+	mirror := newDeps(t, http.StatusInternalServerError, 0)
+	c, err := NewDepsClient(conf(primary.URL, mirror.URL))
+	require.NoError(t, err)
+
+	_, err = c.GetProjectVersions(context.Background(), "react")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), primary.URL+": bad response: 503")
+	assert.Contains(t, err.Error(), mirror.URL+": bad response: 500")
+}
+
+func TestDepsClient_UserAgent(t *testing.T) {
+	d := newDeps(t, http.StatusOK, 0)
+	c, err := NewDepsClient(conf(d.URL))
+	require.NoError(t, err)
+	_, err = c.GetProjectVersions(context.Background(), "react")
+	require.NoError(t, err)
+	assert.Equal(t, "depsmanager", d.userAgent.Load())
+
+	cfg := conf(d.URL)
+	cfg.DepsUserAgent = "depsmanager/1.2.3"
+	c, err = NewDepsClient(cfg)
+	require.NoError(t, err)
+	require.NoError(t, c.Ping(context.Background()))
+	assert.Equal(t, "depsmanager/1.2.3", d.userAgent.Load())
+}
+
+func TestDepsClient_CAFiles(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewDepsClient(conf(srv.URL))
+	require.NoError(t, err)
+	_, err = c.GetProjectVersions(context.Background(), "react")
+	require.ErrorContains(t, err, "certificate", "not trusted without the bundle")
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+	cfg := conf(srv.URL)
+	cfg.DepsCAFiles = []string{bundle}
+	c, err = NewDepsClient(cfg)
+	require.NoError(t, err)
+	_, err = c.GetProjectVersions(context.Background(), "react")
+	require.NoError(t, err)
+
+	cfg.DepsCAFiles = []string{filepath.Join(t.TempDir(), "missing.pem")}
+	_, err = NewDepsClient(cfg)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestDepsClient_Proxy(t *testing.T) {
+	var proxied atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// requests to the proxy carry the absolute target URL
+		proxied.Store(r.URL.String())
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer proxy.Close()
+
+	cfg := conf("http://deps.internal")
+	cfg.DepsProxy = proxy.URL
+	c, err := NewDepsClient(cfg)
+	require.NoError(t, err)
+	_, err = c.GetProjectVersions(context.Background(), "react")
+	require.NoError(t, err)
+	assert.Equal(t, "http://deps.internal/v3/systems/NPM/packages/react", proxied.Load())
+}
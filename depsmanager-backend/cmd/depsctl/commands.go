@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/client"
+	"depsmanager/pkg/labels"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+func fetchCmd(ctx context.Context, c *client.Client, args []string) (*result, error) {
+	project, version, err := projectArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.FetchProject(ctx, project, version); err != nil {
+		return nil, err
+	}
+	return &result{message: fmt.Sprintf("fetched %s@%s", project, version)}, nil
+}
+
+func listCmd(ctx context.Context, c *client.Client, args []string) (*result, error) {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	selector := fs.String("l", "", "label selector, e.g. team=payments,env=prod")
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("%w: %s", errUsage, err)
+	}
+	if fs.NArg() != 0 {
+		return nil, fmt.Errorf("%w: unexpected arguments", errUsage)
+	}
+
+	projects, err := c.ListProjects(ctx, *selector)
+	if err != nil {
+		return nil, err
+	}
+	return projectsResult(projects), nil
+}
+
+func depsCmd(ctx context.Context, c *client.Client, args []string) (*result, error) {
+	project, version, err := projectArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.ListDependencies(ctx, project, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// score is the effective one, upstream_score differs from it while an override is active
+	res := &result{value: resp, header: []string{"name", "version", "score", "upstream_score", "source", "relation", "updated_at", "suppressed_by"}}
+	for _, d := range resp.Dependencies {
+		upstream, suppressedBy := "", ""
+		if d.UpstreamScore != nil {
+			upstream = formatScore(*d.UpstreamScore)
+		}
+		if d.SuppressedBy != nil {
+			suppressedBy = strconv.FormatInt(*d.SuppressedBy, 10)
+		}
+		res.rows = append(res.rows, []string{d.Name, d.Version, formatScore(d.Score), upstream, d.Source, d.Relation, formatTime(d.UpdatedAt), suppressedBy})
+	}
+	return res, nil
+}
+
+func addDepCmd(ctx context.Context, c *client.Client, args []string) (*result, error) {
+	req, err := dependencyArgs("add-dep", args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.AddDependency(ctx, req); err != nil {
+		return nil, err
+	}
+	return &result{message: fmt.Sprintf("added %s to %s@%s", req.DependencyName, req.ProjectName, req.Version)}, nil
+}
+
+func updateDepCmd(ctx context.Context, c *client.Client, args []string) (*result, error) {
+	req, err := dependencyArgs("update-dep", args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.ModifyDependency(ctx, req); err != nil {
+		return nil, err
+	}
+	return &result{message: fmt.Sprintf("updated %s in %s@%s", req.DependencyName, req.ProjectName, req.Version)}, nil
+}
+
+func rmDepCmd(ctx context.Context, c *client.Client, args []string) (*result, error) {
+	if len(args) != 3 {
+		return nil, fmt.Errorf("%w: project, version and dependency are required", errUsage)
+	}
+
+	req := depsmanager.RemoveDependencyRequest{ProjectName: args[0], Version: args[1], DependencyName: args[2]}
+	if err := c.DeleteDependency(ctx, req); err != nil {
+		return nil, err
+	}
+	return &result{message: fmt.Sprintf("removed %s from %s@%s", req.DependencyName, req.ProjectName, req.Version)}, nil
+}
+
+func byDependencyCmd(ctx context.Context, c *client.Client, args []string) (*result, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: dependency is required", errUsage)
+	}
+
+	projects, err := c.ProjectsByDependency(ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return projectsResult(projects), nil
+}
+
+func byScoreCmd(ctx context.Context, c *client.Client, args []string) (*result, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: score is required", errUsage)
+	}
+	score, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid score %q", errUsage, args[0])
+	}
+
+	names, err := c.DependenciesByScore(ctx, score)
+	if err != nil {
+		return nil, err
+	}
+	return namesResult(names), nil
+}
+
+func versionsCmd(ctx context.Context, c *client.Client, args []string) (*result, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: project is required", errUsage)
+	}
+
+	versions, err := c.ProjectVersions(ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	res := &result{value: versions, header: []string{"version"}}
+	for _, v := range versions {
+		res.rows = append(res.rows, []string{v})
+	}
+	return res, nil
+}
+
+func deleteCmd(ctx context.Context, c *client.Client, args []string) (*result, error) {
+	project, version, err := projectArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.DeleteProject(ctx, project, version); err != nil {
+		return nil, err
+	}
+	return &result{message: fmt.Sprintf("deleted %s@%s", project, version)}, nil
+}
+
+func projectArgs(args []string) (string, string, error) {
+	if len(args) != 2 {
+		return "", "", fmt.Errorf("%w: project and version are required", errUsage)
+	}
+	return args[0], args[1], nil
+}
+
+func dependencyArgs(name string, args []string) (depsmanager.DependencyRequest, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	score := fs.Float64("score", 0, "dependency score")
+	if err := fs.Parse(args); err != nil {
+		return depsmanager.DependencyRequest{}, fmt.Errorf("%w: %s", errUsage, err)
+	}
+	if fs.NArg() != 3 {
+		return depsmanager.DependencyRequest{}, fmt.Errorf("%w: project, version and dependency are required", errUsage)
+	}
+
+	return depsmanager.DependencyRequest{
+		ProjectName:    fs.Arg(0),
+		Version:        fs.Arg(1),
+		DependencyName: fs.Arg(2),
+		Score:          *score,
+	}, nil
+}
+
+func projectsResult(projects []depsmanager.Project) *result {
+	res := &result{value: projects, header: []string{"name", "version", "owner", "labels", "updated_at"}}
+	for _, p := range projects {
+		res.rows = append(res.rows, []string{p.Name, p.Version, p.Owner, labels.Format(p.Labels), formatTime(p.UpdatedAt)})
+	}
+	return res
+}
+
+func namesResult(names []string) *result {
+	res := &result{value: names, header: []string{"name"}}
+	for _, n := range names {
+		res.rows = append(res.rows, []string{n})
+	}
+	return res
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+func formatTime(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/client"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+)
+
+// Exit codes, scripts can rely on them.
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitUsage       = 2
+	exitNotFound    = 3
+	exitConflict    = 4
+	exitUnavailable = 5
+	exitDenied      = 6
+)
+
+const (
+	envServer     = "DEPSCTL_SERVER"
+	envAPIKey     = "DEPSCTL_API_KEY"
+	defaultServer = "http://localhost:8085"
+)
+
+var errUsage = errors.New("usage error")
+
+type command struct {
+	usage       string
+	description string
+	run         func(ctx context.Context, c *client.Client, args []string) (*result, error)
+}
+
+var commands = map[string]command{
+	"fetch":         {"fetch <project> <version>", "fetch dependencies from deps.dev and store them", fetchCmd},
+	"list":          {"list [-l selector]", "list stored projects, -l filters by labels", listCmd},
+	"deps":          {"deps <project> <version>", "list dependencies of project version", depsCmd},
+	"add-dep":       {"add-dep [-score N] <project> <version> <dependency>", "add dependency to project version", addDepCmd},
+	"update-dep":    {"update-dep [-score N] <project> <version> <dependency>", "update score of dependency", updateDepCmd},
+	"rm-dep":        {"rm-dep <project> <version> <dependency>", "remove dependency from project version", rmDepCmd},
+	"by-dependency": {"by-dependency <dependency>", "list projects using dependency", byDependencyCmd},
+	"by-score":      {"by-score <score>", "list dependencies with exact score", byScoreCmd},
+	"versions":      {"versions <project>", "list versions of project available in deps.dev", versionsCmd},
+	"delete":        {"delete <project> <version>", "delete project version with its dependencies", deleteCmd},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("depsctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	server := fs.String("server", serverFromEnv(), "server URL, defaults to $"+envServer)
+	output := fs.String("o", formatTable, "output format: table, json or csv")
+	timeout := fs.Duration("timeout", 2*time.Minute, "request timeout")
+	retries := fs.Int("retries", 2, "retries of idempotent requests when server is unavailable")
+	apiKey := fs.String("api-key", os.Getenv(envAPIKey), "api key, defaults to $"+envAPIKey)
+	tenant := fs.String("tenant", "", "tenant to act in, * reads across tenants, requires admin key")
+	fs.Usage = func() { usage(fs, stderr) }
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if !validFormat(*output) {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *output)
+		return exitUsage
+	}
+
+	if fs.NArg() == 0 {
+		usage(fs, stderr)
+		return exitUsage
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", fs.Arg(0))
+		usage(fs, stderr)
+		return exitUsage
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	c := client.New(*server,
+		client.WithRetries(*retries, 500*time.Millisecond),
+		client.WithAPIKey(*apiKey),
+		client.WithTenant(*tenant),
+	)
+	res, err := cmd.run(ctx, c, fs.Args()[1:])
+	if err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "%s\nusage: depsctl %s\n", err, cmd.usage)
+			return exitUsage
+		}
+		fmt.Fprintf(stderr, "depsctl %s: %s\n", fs.Arg(0), err)
+		return exitCode(err)
+	}
+
+	if err := res.write(stdout, *output); err != nil {
+		fmt.Fprintf(stderr, "write output: %s\n", err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+func serverFromEnv() string {
+	if s := os.Getenv(envServer); s != "" {
+		return s
+	}
+	return defaultServer
+}
+
+func usage(fs *flag.FlagSet, w io.Writer) {
+	fmt.Fprintln(w, "usage: depsctl [flags] <command> [arguments]")
+	fmt.Fprintln(w, "\ncommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-55s %s\n", commands[name].usage, commands[name].description)
+	}
+
+	fmt.Fprintln(w, "\nflags:")
+	fs.PrintDefaults()
+	fmt.Fprintf(w, "\nexit codes: %d ok, %d error, %d usage, %d not found, %d conflict, %d server unavailable, %d access denied\n",
+		exitOK, exitError, exitUsage, exitNotFound, exitConflict, exitUnavailable, exitDenied)
+}
+
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, client.ErrBadRequest):
+		return exitUsage
+	case errors.Is(err, depsmanager.ErrProjectNotFound), errors.Is(err, depsmanager.ErrDependencyNotFound):
+		return exitNotFound
+	case errors.Is(err, depsmanager.ErrDependencyAlreadyExists):
+		return exitConflict
+	case errors.Is(err, depsmanager.ErrUnauthorized), errors.Is(err, depsmanager.ErrForbidden):
+		return exitDenied
+	}
+
+	var statusErr *client.StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return exitUnavailable
+		}
+		return exitError
+	}
+
+	// transport errors: connection refused, timeouts, DNS
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return exitUnavailable
+	}
+	return exitError
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"depsmanager"
+	"depsmanager/pkg/labels"
+	"depsmanager/service"
+	"depsmanager/service/mocks"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setup(t *testing.T) (string, *mocks.Service) {
+	t.Helper()
+	svc := new(mocks.Service)
+	api := service.NewAPI(svc)
+	srv := httptest.NewServer(api.GetHandler())
+	t.Cleanup(srv.Close)
+	return srv.URL, svc
+}
+
+func runCmd(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_ListOutputFormats(t *testing.T) {
+	server, svc := setup(t)
+	svc.On("ListProjects", mock.Anything, mock.MatchedBy(func(s labels.Selector) bool { return s.String() == "team=payments" })).
+		Return([]depsmanager.Project{
+			{Name: "react", Version: "18.3.1", UpdatedAt: 1_700_000_000, Owner: "web", Labels: map[string]string{"team": "payments", "env": "prod"}},
+		}, nil)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "table", want: "NAME   VERSION  OWNER  LABELS                  UPDATED_AT\nreact  18.3.1   web    env=prod,team=payments  2023-11-14T22:13:20Z\n"},
+		{format: "csv", want: "name,version,owner,labels,updated_at\nreact,18.3.1,web,\"env=prod,team=payments\",2023-11-14T22:13:20Z\n"},
+		{format: "json", want: "[\n  {\n    \"name\": \"react\",\n    \"version\": \"18.3.1\",\n    \"updated_at\": 1700000000,\n    \"owner\": \"web\",\n    \"labels\": {\n      \"env\": \"prod\",\n      \"team\": \"payments\"\n    }\n  }\n]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			code, stdout, stderr := runCmd("-server", server, "-o", tt.format, "list", "-l", "team=payments")
+			require.Equal(t, exitOK, code, stderr)
+			assert.Equal(t, tt.want, stdout)
+		})
+	}
+}
+
+func TestRun_ServerFromEnv(t *testing.T) {
+	server, svc := setup(t)
+	t.Setenv(envServer, server)
+	svc.On("FetchAndStoreProjectDependencies", mock.Anything, "react", "18.3.1").Return(nil).Once()
+
+	code, stdout, stderr := runCmd("fetch", "react", "18.3.1")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "fetched react@18.3.1\n", stdout)
+	svc.AssertExpectations(t)
+}
+
+func TestRun_AddDependency(t *testing.T) {
+	server, svc := setup(t)
+	svc.On("AddDependency", mock.Anything, "react", "18.3.1", mock.MatchedBy(func(d depsmanager.Dependency) bool {
+		return d.Name == "lodash" && d.Score == 7.5
+	})).Return(nil).Once()
+
+	code, _, stderr := runCmd("-server", server, "add-dep", "-score", "7.5", "react", "18.3.1", "lodash")
+	require.Equal(t, exitOK, code, stderr)
+	svc.AssertExpectations(t)
+}
+
+func TestRun_ExitCodes(t *testing.T) {
+	server, svc := setup(t)
+	svc.On("ListDependencies", mock.Anything, "missing", "1.0.0").Return(depsmanager.ListDependenciesResponse{}, depsmanager.ErrProjectNotFound)
+	svc.On("AddDependency", mock.Anything, "react", "18.3.1", mock.Anything).Return(depsmanager.ErrDependencyAlreadyExists)
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "no command", args: []string{"-server", server}, want: exitUsage},
+		{name: "unknown command", args: []string{"-server", server, "nope"}, want: exitUsage},
+		{name: "unknown format", args: []string{"-server", server, "-o", "xml", "list"}, want: exitUsage},
+		{name: "missing arguments", args: []string{"-server", server, "deps", "react"}, want: exitUsage},
+		{name: "invalid score", args: []string{"-server", server, "by-score", "high"}, want: exitUsage},
+		{name: "not found", args: []string{"-server", server, "deps", "missing", "1.0.0"}, want: exitNotFound},
+		{name: "conflict", args: []string{"-server", server, "add-dep", "react", "18.3.1", "lodash"}, want: exitConflict},
+		{name: "unavailable", args: []string{"-server", "http://127.0.0.1:1", "-retries", "0", "list"}, want: exitUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, _ := runCmd(tt.args...)
+			assert.Equal(t, tt.want, code)
+		})
+	}
+}
+
+func TestRun_APIKey(t *testing.T) {
+	svc := new(mocks.Service)
+	api := service.NewAPI(svc, service.WithAuth(true))
+	srv := httptest.NewServer(api.GetHandler())
+	t.Cleanup(srv.Close)
+
+	svc.On("Authenticate", mock.Anything, "dm_reader").Return(depsmanager.Identity{Name: "ci", Role: depsmanager.RoleReader}, nil)
+	svc.On("Authenticate", mock.Anything, "").Return(depsmanager.Identity{}, depsmanager.ErrUnauthorized)
+	svc.On("ListProjects", mock.Anything, mock.Anything).Return([]depsmanager.Project{}, nil).Once()
+
+	t.Setenv(envAPIKey, "dm_reader")
+	code, _, stderr := runCmd("-server", srv.URL, "list")
+	require.Equal(t, exitOK, code, stderr)
+
+	code, _, _ = runCmd("-server", srv.URL, "-api-key", "", "list")
+	assert.Equal(t, exitDenied, code)
+
+	code, _, _ = runCmd("-server", srv.URL, "delete", "react", "18.3.1")
+	assert.Equal(t, exitDenied, code)
+	svc.AssertExpectations(t)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+func validFormat(format string) bool {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+		return true
+	}
+	return false
+}
+
+// result is the output of a command. value is written as JSON, header and rows as table or CSV.
+// message is written only in table format, commands without data leave everything else empty.
+type result struct {
+	value   any
+	header  []string
+	rows    [][]string
+	message string
+}
+
+func (r *result) write(w io.Writer, format string) error {
+	switch format {
+	case formatJSON:
+		if r.value == nil {
+			return nil
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r.value)
+	case formatCSV:
+		if r.header == nil {
+			return nil
+		}
+		cw := csv.NewWriter(w)
+		if err := cw.Write(r.header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(r.rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	default:
+		if r.message != "" {
+			_, err := fmt.Fprintln(w, r.message)
+			return err
+		}
+		if r.header == nil {
+			return nil
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(r.header, "\t")))
+		for _, row := range r.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+}
@@ -1,42 +1,258 @@
 package main
 
 import (
+	"context"
 	"depsmanager"
 	"depsmanager/clients"
+	"depsmanager/pkg/certs"
+	"depsmanager/pkg/config"
+	"depsmanager/pkg/logging"
+	"depsmanager/pkg/metrics"
+	"depsmanager/pkg/ratelimit"
+	"depsmanager/pkg/trace"
 	"depsmanager/service"
 	"depsmanager/storage"
 	"errors"
+	"flag"
 	"fmt"
-	"github.com/kelseyhightower/envconfig"
-	"log"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
+// VERSION is set by the Makefile with -ldflags "-X main.VERSION=...".
+var VERSION = "dev"
+
+// certCheckInterval is how often certificate files are checked for changes.
+const certCheckInterval = time.Minute
+
 func main() {
-	log.Println("Starting service")
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(configCmd(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	fs := flag.NewFlagSet("server", flag.ExitOnError)
+	configPath := fs.String("config", "", "YAML config file, defaults to $"+config.EnvFile)
+	_ = fs.Parse(os.Args[1:])
+
+	// fail fast, before anything is started
+	conf, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	level := new(slog.LevelVar)
+	level.Set(conf.LogLevel)
+	slog.SetDefault(logging.New(os.Stdout, level))
+	slog.Info("Starting service", "version", VERSION)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx, conf, *configPath, level); err != nil {
+		slog.Error("Service failed", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("Service stopped")
+}
+
+// configCmd runs "config check [-config path]", it loads and validates config like the server at startup.
+func configCmd(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprintln(stderr, "usage: server config check [-config path]")
+		return 2
+	}
+	fs := flag.NewFlagSet("config check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	path := fs.String("config", "", "YAML config file, defaults to $"+config.EnvFile)
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	conf, err := config.Load(*path)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "config OK: port %d, database %s, deps.dev %s, %d policies\n",
+		conf.HTTPPort, conf.DBPath, conf.DepsAddress, len(conf.Policies))
+	return 0
+}
+
+type reloader interface {
+	Reload(thresholds depsmanager.GateThresholds, policies []depsmanager.Policy)
+}
+
+// reload applies log level, gate thresholds and policies of the config, other changes need a restart.
+// An invalid config is logged and the running one is kept.
+func reload(running depsmanager.Config, path string, level *slog.LevelVar, r reloader) {
+	next, err := config.Load(path)
+	if err != nil {
+		slog.Error("Config reload failed, keeping the running config", "error", err)
+		return
+	}
 
-	var conf depsmanager.Config
-	envconfig.MustProcess("", &conf)
+	level.Set(next.LogLevel)
+	r.Reload(next.Thresholds(), next.Policies)
+	slog.Info("Config reloaded", "log_level", next.LogLevel.String(), "policies", len(next.Policies))
+	if config.RestartRequired(running, next) {
+		slog.Warn("Config changes other than log level, gate thresholds and policies apply after restart")
+	}
+}
 
+// run serves the API until ctx is done. In-flight requests are drained first, then background jobs
+// are cancelled and awaited, storage is closed last. SIGHUP reloads config of path.
+func run(ctx context.Context, conf depsmanager.Config, path string, level *slog.LevelVar) error {
 	db, err := storage.NewStorage(conf.SQLLiteConfig)
 	if err != nil {
-		log.Fatalf("storage.NewStorage: %s", err)
+		return fmt.Errorf("storage.NewStorage(): %w", err)
 	}
-	defer db.Close()
-	log.Println("Storage started")
+	defer func() {
+		if err := db.Close(); err != nil {
+			slog.Error("db.Close failed", "error", err)
+		}
+	}()
+	slog.Info("Storage started")
 
+	var tracer *trace.Tracer
+	if conf.OTLPEndpoint != "" {
+		exporter := trace.NewOTLPExporter(strings.TrimSuffix(conf.OTLPEndpoint, "/")+"/v1/traces", conf.ServiceName)
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := exporter.Shutdown(ctx); err != nil {
+				slog.Error("exporter.Shutdown failed", "error", err)
+			}
+		}()
+		tracer = trace.NewTracer(exporter)
+		slog.Info("Tracing enabled", "endpoint", conf.OTLPEndpoint)
+	}
+
+	m := service.NewMetrics(metrics.NewRegistry())
+	if conf.DepsUserAgent == "" {
+		conf.DepsUserAgent = "depsmanager/" + VERSION
+	}
+	depsClient, err := clients.NewDepsClient(conf.DepsConfig, clients.WithMirrorObserver(m.ObserveMirror))
+	if err != nil {
+		return fmt.Errorf("clients.NewDepsClient(): %w", err)
+	}
+	// rejected calls do not reach deps.dev, so they are not counted or traced as deps.dev calls
+	// calls take a limiter slot before the breaker lets them through, so half-open probes do not wait in the queue,
+	// an open breaker fails fast instead of queueing for the limiter
+	breaker := service.NewCircuitBreaker(service.InstrumentDepsClient(depsClient, m, tracer), conf.DepsBreaker, m, time.Now)
+	limiter := ratelimit.NewLimiter(conf.DepsLimits.Rate, conf.DepsLimits.Burst, conf.DepsLimits.MaxInFlight)
 	svg := service.NewService(
-		service.WithStorage(db),
-		service.WithDepsClient(clients.NewDepsClient(conf.DepsAddress)),
+		service.WithStorage(service.InstrumentStorage(db, m, tracer)),
+		service.WithDepsClient(service.LimitDepsClient(breaker, limiter, m, breaker.FailFast)),
 		service.WithTimeNow(time.Now),
+		service.WithGateThresholds(conf.Thresholds()),
+		service.WithConfigPolicies(conf.Policies),
+		service.WithBootstrapKey(conf.AuthBootstrapKey),
 	)
-	api := service.NewAPI(svg)
 
-	log.Printf("Starting http server on port %d", conf.HTTPPort)
-	httpServer := http.Server{Addr: fmt.Sprintf(":%d", conf.HTTPPort), Handler: api.GetHandler()}
+	// deps.dev calls of jobs wait behind the ones of requests
+	jobsCtx, cancelJobs := context.WithCancel(ratelimit.WithPriority(context.Background(), ratelimit.Background))
+	var jobs sync.WaitGroup
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		svg.RunPortfolioMetrics(jobsCtx, m, conf.PortfolioMetricsConfig)
+	}()
+	// after requests are drained, portfolio refresh only reads so it is cancelled rather than awaited
+	defer func() {
+		cancelJobs()
+		jobs.Wait()
+	}()
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				reload(conf, path, level, svg)
+			}
+		}
+	}()
+
+	if !conf.AuthEnabled {
+		slog.Warn("Authentication is disabled, set AUTH_ENABLED=true to require api keys")
+	}
+	api := service.NewAPI(service.InstrumentService(svg, m, tracer),
+		service.WithAuth(conf.AuthEnabled), service.WithMetrics(m), service.WithTracer(tracer),
+		service.WithVersion(VERSION), service.WithClientIdentities(conf.ClientIdentities),
+		service.WithRateLimit(conf.RateLimitConfig),
+		service.WithReadiness(
+			service.HealthCheck{Name: "database", Check: db.Ping},
+			service.HealthCheck{Name: "deps.dev", Check: service.CachedCheck(depsClient.Ping, 30*time.Second)},
+			service.HealthCheck{Name: "deps.dev circuit", Check: breaker.Check},
+		))
+
+	httpServer := &http.Server{
+		Handler:  api.GetHandler(),
+		ErrorLog: slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+	}
+	if conf.TLSCertFile != "" {
+		certReloader, err := certs.NewReloader(conf.TLSCertFile, conf.TLSKeyFile)
+		if err != nil {
+			return fmt.Errorf("certs.NewReloader(): %w", err)
+		}
+		if httpServer.TLSConfig, err = certs.ServerConfig(conf.TLSConfig, certReloader); err != nil {
+			return fmt.Errorf("certs.ServerConfig(): %w", err)
+		}
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			certReloader.Watch(jobsCtx, certCheckInterval)
+		}()
+	}
 
-	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		log.Fatalf("httpServer.ListenAndServe: %s", err)
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", conf.HTTPPort))
+	if err != nil {
+		return fmt.Errorf("net.Listen(): %w", err)
+	}
+	slog.Info("Starting http server", "port", conf.HTTPPort, "tls", httpServer.TLSConfig != nil,
+		"client_ca", conf.TLSClientCAFile != "")
+	return serve(ctx, httpServer, ln, conf.ShutdownTimeout)
+}
+
+// serve serves srv on ln until ctx is done, over TLS when srv.TLSConfig is set, then stops accepting connections and waits up to timeout
+// for in-flight requests. Requests still running after timeout are cancelled, their transactions roll back.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, timeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			errCh <- srv.ServeTLS(ln, "", "")
+			return
+		}
+		errCh <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("srv.Serve(): %w", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down http server", "timeout", timeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		_ = srv.Close()
+		return fmt.Errorf("srv.Shutdown(): %w", err)
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("srv.Serve(): %w", err)
 	}
+	return nil
 }
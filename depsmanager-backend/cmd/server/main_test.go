@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"depsmanager"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe_DrainsInFlightRequests(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte("stored"))
+	})}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, srv, ln, 5*time.Second) }()
+
+	type result struct {
+		body string
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		results <- result{body: string(b), err: err}
+	}()
+
+	<-started
+	cancel()
+	// shutdown waits for the request, new connections are refused meanwhile
+	require.Eventually(t, func() bool {
+		_, err := net.DialTimeout("tcp", ln.Addr().String(), 100*time.Millisecond)
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case err := <-served:
+		t.Fatalf("serve returned before the in-flight request completed: %v", err)
+	default:
+	}
+
+	close(release)
+	res := <-results
+	require.NoError(t, res.err)
+	assert.Equal(t, "stored", res.body)
+	require.NoError(t, <-served)
+}
+
+func TestServe_TLS(t *testing.T) {
+	// certificate and trusting client of httptest
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	ts.Close()
+
+	srv := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte("secure")) }),
+		TLSConfig: &tls.Config{Certificates: ts.TLS.Certificates},
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, srv, ln, time.Second) }()
+
+	resp, err := ts.Client().Get("https://" + ln.Addr().String())
+	require.NoError(t, err)
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "secure", string(b))
+	assert.NotNil(t, resp.TLS)
+
+	resp, err = http.Get("http://" + ln.Addr().String())
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "plain HTTP is rejected")
+
+	cancel()
+	require.NoError(t, <-served)
+}
+
+func TestServe_CancelsRequestsAfterTimeout(t *testing.T) {
+	started, cancelled := make(chan struct{}), make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+		close(cancelled)
+	})}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, srv, ln, 50*time.Millisecond) }()
+	go func() {
+		if resp, err := http.Get("http://" + ln.Addr().String()); err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+	require.ErrorIs(t, <-served, context.DeadlineExceeded)
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("request context was not cancelled after the shutdown timeout")
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "depsmanager.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestConfigCmd(t *testing.T) {
+	valid := writeConfig(t, "server:\n  http_port: 9000\npolicies:\n  - name: p\n    rules:\n      - type: max_unscored\n        value: 1\n")
+	invalid := writeConfig(t, "server:\n  http_port: 0\n")
+
+	tests := []struct {
+		name           string
+		args           []string
+		code           int
+		stdout, stderr string
+	}{
+		{name: "valid", args: []string{"check", "-config", valid}, code: 0,
+			stdout: "config OK: port 9000, database ./deps.db, deps.dev https://api.deps.dev, 1 policies\n"},
+		{name: "invalid", args: []string{"check", "-config", invalid}, code: 1,
+			stderr: "invalid config:\n  server.http_port (HTTP_PORT): must be between 1 and 65535\n"},
+		{name: "no subcommand", args: nil, code: 2, stderr: "usage: server config check [-config path]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, tt.code, configCmd(tt.args, &stdout, &stderr))
+			assert.Equal(t, tt.stdout, stdout.String())
+			assert.Equal(t, tt.stderr, stderr.String())
+		})
+	}
+}
+
+type fakeReloader struct {
+	thresholds depsmanager.GateThresholds
+	policies   []depsmanager.Policy
+	calls      int
+}
+
+func (r *fakeReloader) Reload(thresholds depsmanager.GateThresholds, policies []depsmanager.Policy) {
+	r.thresholds, r.policies = thresholds, policies
+	r.calls++
+}
+
+func TestReload(t *testing.T) {
+	path := writeConfig(t, "log:\n  level: info\n")
+	running := depsmanager.DefaultConfig()
+	level := new(slog.LevelVar)
+	r := &fakeReloader{}
+
+	require.NoError(t, os.WriteFile(path, []byte(`
+log:
+  level: debug
+gate:
+  min_score: 5
+policies:
+  - name: p
+    rules:
+      - type: max_unscored
+        value: 1
+`), 0o600))
+	reload(running, path, level, r)
+	assert.Equal(t, slog.LevelDebug, level.Level())
+	require.NotNil(t, r.thresholds.MinScore)
+	assert.Equal(t, 5.0, *r.thresholds.MinScore)
+	assert.Len(t, r.policies, 1)
+
+	// invalid config keeps the running one
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: loud\n"), 0o600))
+	reload(running, path, level, r)
+	assert.Equal(t, slog.LevelDebug, level.Level())
+	assert.Equal(t, 1, r.calls)
+}
@@ -1,12 +1,197 @@
 package depsmanager
 
+import (
+	"log/slog"
+	"time"
+)
+
+// Config is read by config.Load from defaults, then the YAML file, then environment variables.
+// Sections are the top-level YAML keys, env tags name the variables overriding them.
 type Config struct {
-	HTTPPort    int    `envconfig:"HTTP_PORT" default:"8085"`
-	DepsAddress string `envconfig:"DEPS_ADDRESS" default:"https://api.deps.dev"`
-	SQLLiteConfig
+	ServerConfig           `yaml:"server"`
+	DepsConfig             `yaml:"deps"`
+	SQLLiteConfig          `yaml:"storage"`
+	GateConfig             `yaml:"gate"`
+	AuthConfig             `yaml:"auth"`
+	PortfolioMetricsConfig `yaml:"scheduler"`
+	TracingConfig          `yaml:"tracing"`
+	LogConfig              `yaml:"log"`
+	TLSConfig              `yaml:"tls"`
+	RateLimitConfig        `yaml:"rate_limit"`
+	// Policies apply to all projects next to the stored ones, they can be set only in the YAML file.
+	Policies []Policy `yaml:"policies" ignored:"true"`
+}
+
+type ServerConfig struct {
+	HTTPPort int `yaml:"http_port" envconfig:"HTTP_PORT"`
+	// ShutdownTimeout bounds draining of in-flight requests on SIGTERM or SIGINT.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" envconfig:"SHUTDOWN_TIMEOUT"`
+}
+
+// DepsConfig configures the deps.dev client. Mirrors are base URLs tried in order after DepsAddress when it fails
+// or answers 5xx or 429. Without DepsProxy the proxy comes from HTTPS_PROXY and NO_PROXY, DepsCAFiles are PEM bundles
+// trusted next to the system roots, e.g. of a TLS-inspecting proxy.
+type DepsConfig struct {
+	DepsAddress   string       `yaml:"address" envconfig:"DEPS_ADDRESS"`
+	DepsMirrors   []string     `yaml:"mirrors" envconfig:"DEPS_MIRRORS"`
+	DepsProxy     string       `yaml:"proxy" envconfig:"DEPS_PROXY"`
+	DepsCAFiles   []string     `yaml:"ca_files" envconfig:"DEPS_CA_FILES"`
+	DepsUserAgent string       `yaml:"user_agent" envconfig:"DEPS_USER_AGENT"`
+	DepsTimeouts  DepsTimeouts `yaml:"timeouts" envconfig:"DEPS_TIMEOUT"`
+	DepsBreaker   DepsBreaker  `yaml:"breaker" envconfig:"DEPS_BREAKER"`
+	DepsLimits    DepsLimits   `yaml:"limits" envconfig:"DEPS_LIMIT"`
+}
+
+// DepsLimits are shared by all deps.dev calls: Rate calls per second with bursts of Burst calls
+// and at most MaxInFlight at once. Zero Rate or MaxInFlight is unlimited.
+type DepsLimits struct {
+	Rate        float64 `yaml:"rate" envconfig:"RATE"`
+	Burst       int     `yaml:"burst" envconfig:"BURST"`
+	MaxInFlight int     `yaml:"max_in_flight" envconfig:"MAX_IN_FLIGHT"`
+}
+
+// DepsBreaker configures the circuit breaker of deps.dev calls. Failures consecutive failed calls open it,
+// open calls fail fast for OpenTimeout, then HalfOpenProbes calls are let through: all of them succeeding
+// close it, any failing opens it again. Failures 0 disables the breaker.
+type DepsBreaker struct {
+	Failures       int           `yaml:"failures" envconfig:"FAILURES"`
+	OpenTimeout    time.Duration `yaml:"open_timeout" envconfig:"OPEN_TIMEOUT"`
+	HalfOpenProbes int           `yaml:"half_open_probes" envconfig:"HALF_OPEN_PROBES"`
+}
+
+// DepsTimeouts bound a single attempt of a call type, a timed out attempt fails over to the next mirror.
+// Connect bounds dialing, zero disables a timeout.
+type DepsTimeouts struct {
+	Connect      time.Duration `yaml:"connect" envconfig:"CONNECT"`
+	Versions     time.Duration `yaml:"versions" envconfig:"VERSIONS"`
+	Dependencies time.Duration `yaml:"dependencies" envconfig:"DEPENDENCIES"`
+	Batch        time.Duration `yaml:"batch" envconfig:"BATCH"`
+	Ping         time.Duration `yaml:"ping" envconfig:"PING"`
 }
 
 type SQLLiteConfig struct {
-	DBPath      string `envconfig:"DB_PATH" default:"./deps.db"`
-	BusyTimeout int64  `envconfig:"BUSY_TIMEOUT" default:"5000"`
+	DBPath      string `yaml:"db_path" envconfig:"DB_PATH"`
+	BusyTimeout int64  `yaml:"busy_timeout" envconfig:"BUSY_TIMEOUT"`
+}
+
+// GateConfig holds default thresholds of the CI gate, unset thresholds are not checked.
+type GateConfig struct {
+	GateMinScore            *float64 `yaml:"min_score" envconfig:"GATE_MIN_SCORE"`
+	GateMaxUnscored         *int     `yaml:"max_unscored" envconfig:"GATE_MAX_UNSCORED"`
+	GateMaxScorecardAgeDays *int     `yaml:"max_scorecard_age_days" envconfig:"GATE_MAX_SCORECARD_AGE_DAYS"`
+}
+
+func (c GateConfig) Thresholds() GateThresholds {
+	return GateThresholds{
+		MinScore:            c.GateMinScore,
+		MaxUnscored:         c.GateMaxUnscored,
+		MaxScorecardAgeDays: c.GateMaxScorecardAgeDays,
+	}
+}
+
+// AuthConfig enables api key authentication. Bootstrap key is an admin key kept only in config,
+// use it to create the first keys.
+type AuthConfig struct {
+	AuthEnabled      bool   `yaml:"enabled" envconfig:"AUTH_ENABLED"`
+	AuthBootstrapKey string `yaml:"bootstrap_key" envconfig:"AUTH_BOOTSTRAP_KEY"`
+}
+
+// PortfolioMetricsConfig controls risk gauges exported on /metrics. Interval 0 disables them,
+// MaxSeries limits exported project versions, the riskiest ones are kept, 0 is unlimited.
+type PortfolioMetricsConfig struct {
+	PortfolioMetricsInterval  time.Duration `yaml:"portfolio_metrics_interval" envconfig:"PORTFOLIO_METRICS_INTERVAL"`
+	PortfolioScoreThreshold   float64       `yaml:"portfolio_score_threshold" envconfig:"PORTFOLIO_SCORE_THRESHOLD"`
+	PortfolioMetricsMaxSeries int           `yaml:"portfolio_metrics_max_series" envconfig:"PORTFOLIO_METRICS_MAX_SERIES"`
+}
+
+// LogConfig sets the minimum level of JSON log lines: debug, info, warn or error.
+type LogConfig struct {
+	LogLevel slog.Level `yaml:"level" envconfig:"LOG_LEVEL"`
+}
+
+// TracingConfig enables tracing when the OTLP/HTTP endpoint of a collector is set, e.g. http://localhost:4318.
+// Spans are sent to <endpoint>/v1/traces.
+type TracingConfig struct {
+	OTLPEndpoint string `yaml:"otlp_endpoint" envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	ServiceName  string `yaml:"service_name" envconfig:"OTEL_SERVICE_NAME"`
+}
+
+// TLSConfig serves HTTPS when certificate and key files are set, changed files are loaded without restart.
+// With ClientCAFile client certificates signed by it are verified, TLSClientCertRequired rejects connections
+// without one. ClientIdentities authenticate verified certificates like api keys.
+type TLSConfig struct {
+	TLSCertFile           string `yaml:"cert_file" envconfig:"TLS_CERT_FILE"`
+	TLSKeyFile            string `yaml:"key_file" envconfig:"TLS_KEY_FILE"`
+	TLSClientCAFile       string `yaml:"client_ca_file" envconfig:"TLS_CLIENT_CA_FILE"`
+	TLSClientCertRequired bool   `yaml:"client_cert_required" envconfig:"TLS_CLIENT_CERT_REQUIRED"`
+	// ClientIdentities can be set only in the YAML file.
+	ClientIdentities []ClientIdentity `yaml:"client_identities" ignored:"true"`
+}
+
+// ClientIdentity maps subject of a client certificate, e.g. "CN=ci-runner,O=Acme", to role in Tenant.
+// Empty Tenant is the default one.
+type ClientIdentity struct {
+	Subject string `yaml:"subject"`
+	Role    Role   `yaml:"role"`
+	Tenant  string `yaml:"tenant,omitempty"`
+}
+
+// RateLimitConfig limits /api requests per api key, or per client address without one. Requests calling deps.dev
+// (fetch, project versions, gate) take a token of the Expensive quota, the others of the Default one. Before
+// authentication every request also takes one of the Address quota of its client address, so requests without
+// a valid key are limited too. Zero rate of a quota is unlimited.
+type RateLimitConfig struct {
+	RateLimitEnabled   bool           `yaml:"enabled" envconfig:"RATE_LIMIT_ENABLED"`
+	RateLimitDefault   RateLimitQuota `yaml:"default" envconfig:"RATE_LIMIT_DEFAULT"`
+	RateLimitExpensive RateLimitQuota `yaml:"expensive" envconfig:"RATE_LIMIT_EXPENSIVE"`
+	RateLimitAddress   RateLimitQuota `yaml:"address" envconfig:"RATE_LIMIT_ADDRESS"`
+	// RateLimitOverrides can be set only in the YAML file.
+	RateLimitOverrides []RateLimitOverride `yaml:"overrides" ignored:"true"`
+}
+
+// RateLimitQuota lets Rate requests per second through with bursts of Burst requests.
+type RateLimitQuota struct {
+	Rate  float64 `yaml:"rate" envconfig:"RATE"`
+	Burst int     `yaml:"burst" envconfig:"BURST"`
+}
+
+// RateLimitOverride replaces quotas of the api key with id APIKeyID, or sets quotas of Tenant shared by all its
+// keys on top of their own. Either is set, quotas left nil keep the default of a key and no limit of a tenant.
+type RateLimitOverride struct {
+	Tenant    string          `yaml:"tenant,omitempty"`
+	APIKeyID  int64           `yaml:"api_key_id,omitempty"`
+	Default   *RateLimitQuota `yaml:"default,omitempty"`
+	Expensive *RateLimitQuota `yaml:"expensive,omitempty"`
+}
+
+// DefaultConfig returns values used when neither the YAML file nor environment sets them.
+func DefaultConfig() Config {
+	return Config{
+		ServerConfig: ServerConfig{HTTPPort: 8085, ShutdownTimeout: 30 * time.Second},
+		DepsConfig: DepsConfig{
+			DepsAddress: "https://api.deps.dev",
+			DepsTimeouts: DepsTimeouts{
+				Connect:      5 * time.Second,
+				Versions:     10 * time.Second,
+				Dependencies: 30 * time.Second,
+				Batch:        time.Minute,
+				Ping:         5 * time.Second,
+			},
+			DepsBreaker: DepsBreaker{Failures: 5, OpenTimeout: 30 * time.Second, HalfOpenProbes: 1},
+			DepsLimits:  DepsLimits{Rate: 10, Burst: 10, MaxInFlight: 8},
+		},
+		RateLimitConfig: RateLimitConfig{
+			RateLimitDefault:   RateLimitQuota{Rate: 20, Burst: 40},
+			RateLimitExpensive: RateLimitQuota{Rate: 1, Burst: 10},
+			RateLimitAddress:   RateLimitQuota{Rate: 50, Burst: 100},
+		},
+		SQLLiteConfig: SQLLiteConfig{DBPath: "./deps.db", BusyTimeout: 5000},
+		PortfolioMetricsConfig: PortfolioMetricsConfig{
+			PortfolioMetricsInterval:  5 * time.Minute,
+			PortfolioScoreThreshold:   4,
+			PortfolioMetricsMaxSeries: 500,
+		},
+		TracingConfig: TracingConfig{ServiceName: "depsmanager"},
+		LogConfig:     LogConfig{LogLevel: slog.LevelInfo},
+	}
 }
@@ -1,14 +1,56 @@
 package depsmanager
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 )
 
 var (
-	ErrProjectNotFound         = errors.New("project not found")
-	ErrDependencyNotFound      = errors.New("dependency not found")
-	ErrDependencyAlreadyExists = errors.New("dependency already exists")
+	ErrProjectNotFound          = errors.New("project not found")
+	ErrDependencyNotFound       = errors.New("dependency not found")
+	ErrDependencyAlreadyExists  = errors.New("dependency already exists")
+	ErrPolicyNotFound           = errors.New("policy not found")
+	ErrInvalidPolicy            = errors.New("invalid policy")
+	ErrPolicyEvaluationNotFound = errors.New("policy evaluation not found")
+	ErrInvalidLockfile          = errors.New("invalid lockfile")
+	ErrAPIKeyNotFound           = errors.New("api key not found")
+	ErrUnauthorized             = errors.New("missing or invalid api key")
+	ErrForbidden                = errors.New("insufficient role")
+	ErrTenantNotFound           = errors.New("tenant not found")
+	ErrTenantAlreadyExists      = errors.New("tenant already exists")
+	ErrTenantRequired           = errors.New("single tenant required, cross-tenant scope is read-only")
+	ErrOverrideNotFound         = errors.New("score override not found")
+	ErrSuppressionNotFound      = errors.New("suppression not found")
+	ErrUpstreamUnavailable      = errors.New("deps.dev unavailable")
+	ErrRateLimited              = errors.New("rate limit exceeded")
+)
+
+// UpstreamUnavailableError is returned without calling deps.dev while its circuit breaker is open,
+// it matches ErrUpstreamUnavailable. RetryAfter is the time until the breaker lets a call through again.
+type UpstreamUnavailableError struct {
+	RetryAfter time.Duration
+}
+
+func (e *UpstreamUnavailableError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrUpstreamUnavailable, e.RetryAfter.Round(time.Second))
+}
+
+func (e *UpstreamUnavailableError) Unwrap() error {
+	return ErrUpstreamUnavailable
+}
+
+// Dependency relations as reported by deps.dev.
+const (
+	RelationDirect   = "DIRECT"
+	RelationIndirect = "INDIRECT"
+)
+
+// Dependency sources. Fetched dependencies are replaced by refreshes, manual ones are kept.
+const (
+	SourceFetched = "fetched"
+	SourceManual  = "manual"
 )
 
 type ProjectRequest struct {
@@ -33,11 +75,34 @@ type GetProjectNameByDepNameReq struct {
 type GetDependenciesByScore struct {
 	Score float64 `json:"score"`
 }
+
+// Dependency is a dependency of project version. Score is the effective score:
+// the active override if any, otherwise UpstreamScore for fetched dependencies.
 type Dependency struct {
-	ProjectID int64   `json:"-"`
+	ProjectID int64 `json:"-"`
+	// ProjectTenant, ProjectName and ProjectVersion identify project of the dependency when suppressions are matched.
+	ProjectTenant  string  `json:"-"`
+	ProjectName    string  `json:"-"`
+	ProjectVersion string  `json:"-"`
+	Score          float64 `json:"score"`
+	Name           string  `json:"name"`
+	// Version of the dependency resolved by deps.dev, empty when unknown.
+	Version   string `json:"version,omitempty"`
+	UpdatedAt int64  `json:"updated_at"`
+	Relation  string `json:"relation,omitempty"`
+	Source    string `json:"source,omitempty"`
+	// UpstreamScore is the score fetched from deps.dev, nil for manual dependencies.
+	UpstreamScore *float64       `json:"upstream_score,omitempty"`
+	Override      *ScoreOverride `json:"override,omitempty"`
+	// SuppressedBy is ID of the active suppression accepting risk of the dependency.
+	SuppressedBy *int64 `json:"suppressed_by,omitempty"`
+}
+
+// ScoreOverride pins score of a fetched dependency across refreshes until ExpiresAt or until it is cleared.
+type ScoreOverride struct {
 	Score     float64 `json:"score"`
-	Name      string  `json:"name"`
-	UpdatedAt int64   `json:"updated_at"`
+	SetAt     int64   `json:"set_at"`
+	ExpiresAt *int64  `json:"expires_at,omitempty"`
 }
 
 type DependencyRequest struct {
@@ -45,6 +110,15 @@ type DependencyRequest struct {
 	Version        string  `json:"Version"`
 	Score          float64 `json:"score"`
 	DependencyName string  `json:"dependency_name"`
+	// ExpiresAt ends score override of fetched dependency, unix seconds, never when empty.
+	ExpiresAt *int64 `json:"expires_at,omitempty"`
+}
+
+// PutDependencyRequest is the body of v2 dependency upsert, the rest comes from the path.
+type PutDependencyRequest struct {
+	Score float64 `json:"score"`
+	// ExpiresAt ends score override of fetched dependency, unix seconds, never when empty.
+	ExpiresAt *int64 `json:"expires_at,omitempty"`
 }
 
 type RemoveDependencyRequest struct {
@@ -53,10 +127,61 @@ type RemoveDependencyRequest struct {
 	DependencyName string `json:"dependency_name"`
 }
 
+// Project is a stored project version. Owner, Description, RepoURL and Labels come from
+// ProjectMetadata shared by every version of the project.
 type Project struct {
-	Name      string `json:"name"`
-	Version   string `json:"version"`
-	UpdatedAt int64  `json:"updated_at"`
+	Tenant      string            `json:"tenant,omitempty"`
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	UpdatedAt   int64             `json:"updated_at"`
+	Owner       string            `json:"owner,omitempty"`
+	Description string            `json:"description,omitempty"`
+	RepoURL     string            `json:"repo_url,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// ProjectMetadata describes a project independently of its versions, Labels group projects
+// and are matched by label selectors like "team=payments,env=prod".
+type ProjectMetadata struct {
+	ProjectName string            `json:"project_name"`
+	Owner       string            `json:"owner"`
+	Description string            `json:"description"`
+	RepoURL     string            `json:"repo_url"`
+	Labels      map[string]string `json:"labels"`
+	UpdatedAt   int64             `json:"updated_at"`
+}
+
+type ProjectMetadataRequest struct {
+	Owner       string            `json:"owner"`
+	Description string            `json:"description"`
+	RepoURL     string            `json:"repo_url"`
+	Labels      map[string]string `json:"labels"`
+}
+
+// ScoreBucket counts scored dependencies whose score falls in [From, To).
+// The last bucket is closed on both ends so that a perfect 10 is counted.
+type ScoreBucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// RiskSummary aggregates dependency scores of a single project version,
+// or of all stored projects when ProjectName and Version are empty.
+// Score statistics are computed over scored dependencies only,
+// dependencies without a scorecard are reported in UnscoredCount.
+type RiskSummary struct {
+	ProjectName       string        `json:"project_name,omitempty"`
+	Version           string        `json:"version,omitempty"`
+	DependencyCount   int           `json:"dependency_count"`
+	MeanScore         float64       `json:"mean_score"`
+	MedianScore       float64       `json:"median_score"`
+	MinScore          float64       `json:"min_score"`
+	Histogram         []ScoreBucket `json:"histogram"`
+	UnscoredCount     int           `json:"unscored_count"`
+	OldestScorecardAt int64         `json:"oldest_scorecard_at"`
+	// SuppressedCount dependencies are included in DependencyCount only.
+	SuppressedCount int `json:"suppressed_count"`
 }
 
 type ListProjectsResponse struct {
@@ -156,3 +281,252 @@ type DepsGetProjectBatchResp struct {
 		} `json:"project"`
 	} `json:"responses"`
 }
+
+// Policy rule types.
+const (
+	PolicyRuleMinScore            = "min_score"
+	PolicyRuleMaxScorecardAgeDays = "max_scorecard_age_days"
+	PolicyRuleMaxUnscored         = "max_unscored"
+)
+
+// Policy verdicts.
+const (
+	VerdictPass = "pass"
+	VerdictFail = "fail"
+)
+
+// Policy is a named set of rules evaluated against dependencies of a project.
+// Policy with empty ProjectName is global and applies to every project.
+type Policy struct {
+	Name        string       `yaml:"name" json:"name"`
+	ProjectName string       `yaml:"project_name,omitempty" json:"project_name,omitempty"`
+	Rules       []PolicyRule `yaml:"rules" json:"rules"`
+	Document    string       `yaml:"-" json:"-"`
+	UpdatedAt   int64        `yaml:"-" json:"updated_at"`
+	Tenant      string       `yaml:"-" json:"tenant,omitempty"`
+}
+
+// PolicyRule is a single check of a Policy. Relation limits min_score rule
+// to direct or indirect dependencies, empty Relation matches all of them.
+type PolicyRule struct {
+	Type     string  `yaml:"type" json:"type"`
+	Relation string  `yaml:"relation,omitempty" json:"relation,omitempty"`
+	Value    float64 `yaml:"value" json:"value"`
+}
+
+type PolicyViolation struct {
+	Policy         string `json:"policy"`
+	Rule           string `json:"rule"`
+	DependencyName string `json:"dependency_name,omitempty"`
+	Message        string `json:"message"`
+}
+
+type PolicyEvaluation struct {
+	ProjectName string            `json:"project_name"`
+	Version     string            `json:"version"`
+	Verdict     string            `json:"verdict"`
+	Violations  []PolicyViolation `json:"violations"`
+	EvaluatedAt int64             `json:"evaluated_at"`
+}
+
+type DeletePolicyRequest struct {
+	Name        string `json:"name"`
+	ProjectName string `json:"project_name"`
+}
+
+// GateThresholds are limits checked by the CI gate, nil threshold is not checked.
+type GateThresholds struct {
+	MinScore            *float64 `json:"min_score,omitempty"`
+	MaxUnscored         *int     `json:"max_unscored,omitempty"`
+	MaxScorecardAgeDays *int     `json:"max_scorecard_age_days,omitempty"`
+}
+
+// GateRequest describes a CI gate run. When BaselineProject is set only packages
+// missing in the stored baseline project version or stored with another version are scored and checked.
+type GateRequest struct {
+	BaselineProject string
+	BaselineVersion string
+	Thresholds      GateThresholds
+}
+
+// Package changes reported by the CI gate.
+const (
+	GateChangeAdded = "added"
+	// GateChangeChanged is a baseline package in another version, the stored one is PreviousVersion.
+	GateChangeChanged = "changed"
+)
+
+type GatePackage struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Relation string `json:"relation,omitempty"`
+	Change   string `json:"change"`
+	// PreviousVersion is the baseline version of a changed package.
+	PreviousVersion string  `json:"previous_version,omitempty"`
+	Score           float64 `json:"score"`
+	UpdatedAt       int64   `json:"updated_at"`
+	// SuppressedBy is ID of the active suppression, suppressed packages do not fail the gate.
+	SuppressedBy *int64 `json:"suppressed_by,omitempty"`
+}
+
+// GateResult is the verdict of the CI gate. ExitCode is a hint for CI scripts:
+// 0 when verdict is pass, 1 when it is fail.
+type GateResult struct {
+	Verdict    string            `json:"verdict"`
+	ExitCode   int               `json:"exit_code"`
+	Baseline   *ProjectRequest   `json:"baseline,omitempty"`
+	Thresholds GateThresholds    `json:"thresholds"`
+	Packages   []GatePackage     `json:"packages"`
+	Violations []PolicyViolation `json:"violations"`
+}
+
+// Role grants access to API routes, every role includes permissions of the roles before it.
+type Role string
+
+const (
+	RoleReader Role = "reader" // read projects, dependencies, policies and run the CI gate
+	RoleEditor Role = "editor" // fetch, delete and edit projects, dependencies and policies
+	RoleAdmin  Role = "admin"  // manage api keys
+)
+
+var roleLevels = map[Role]int{RoleReader: 1, RoleEditor: 2, RoleAdmin: 3}
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	return roleLevels[r] > 0
+}
+
+// Allows reports whether r grants access to routes requiring role required.
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && roleLevels[r] >= roleLevels[required]
+}
+
+// APIKey is stored without the key itself, only its SHA-256 hash is kept.
+type APIKey struct {
+	ID        int64  `json:"id"`
+	Tenant    string `json:"tenant"`
+	Name      string `json:"name"`
+	Role      Role   `json:"role"`
+	Prefix    string `json:"prefix"`
+	CreatedAt int64  `json:"created_at"`
+	RevokedAt *int64 `json:"revoked_at,omitempty"`
+	KeyHash   string `json:"-"`
+}
+
+// CreateAPIKeyRequest without Tenant creates key in the tenant of the caller.
+type CreateAPIKeyRequest struct {
+	Name   string `json:"name"`
+	Role   Role   `json:"role"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// CreatedAPIKey contains the plain key, it is returned only once.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// Identity is the caller authenticated by api key, it acts in its Tenant. Names of api keys are not unique,
+// KeyID tells them apart and is zero for the bootstrap key and client certificates.
+type Identity struct {
+	Name   string `json:"name"`
+	Role   Role   `json:"role"`
+	Tenant string `json:"tenant"`
+	KeyID  int64  `json:"key_id,omitempty"`
+}
+
+// Tenant is an organization or team owning projects, policies and api keys.
+type Tenant struct {
+	Name      string `json:"name"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type CreateTenantRequest struct {
+	Name string `json:"name"`
+}
+
+// Audit actions, one per mutating operation.
+const (
+	AuditProjectFetch      = "project.fetch"
+	AuditProjectDelete     = "project.delete"
+	AuditMetadataSave      = "project.metadata_save"
+	AuditMetadataDelete    = "project.metadata_delete"
+	AuditDependencyAdd     = "dependency.add"
+	AuditDependencyUpdate  = "dependency.update"
+	AuditDependencyDelete  = "dependency.delete"
+	AuditOverrideClear     = "dependency.override_clear"
+	AuditPolicySave        = "policy.save"
+	AuditPolicyDelete      = "policy.delete"
+	AuditAPIKeyCreate      = "api_key.create"
+	AuditAPIKeyRevoke      = "api_key.revoke"
+	AuditTenantCreate      = "tenant.create"
+	AuditSuppressionCreate = "suppression.create"
+	AuditSuppressionDelete = "suppression.delete"
+)
+
+// AuditEntry records a single change. Target names policy, api key or tenant,
+// Before and After are JSON values, null when there was nothing before or after.
+type AuditEntry struct {
+	ID             int64           `json:"id"`
+	Tenant         string          `json:"tenant"`
+	Actor          string          `json:"actor"`
+	Action         string          `json:"action"`
+	ProjectName    string          `json:"project_name,omitempty"`
+	Version        string          `json:"version,omitempty"`
+	DependencyName string          `json:"dependency_name,omitempty"`
+	Target         string          `json:"target,omitempty"`
+	Before         json.RawMessage `json:"before"`
+	After          json.RawMessage `json:"after"`
+	RequestID      string          `json:"request_id,omitempty"`
+	CreatedAt      int64           `json:"created_at"`
+}
+
+// AuditFilter selects audit entries, empty fields match everything.
+// Entries are returned newest first, BeforeID continues from the last entry of the previous page.
+type AuditFilter struct {
+	Actor          string
+	Action         string
+	ProjectName    string
+	Version        string
+	DependencyName string
+	From           int64
+	To             int64
+	BeforeID       int64
+	Limit          int
+}
+
+type AuditPage struct {
+	Entries []AuditEntry `json:"entries"`
+	// NextBeforeID is set when there may be more entries, pass it as before_id.
+	NextBeforeID *int64 `json:"next_before_id,omitempty"`
+}
+
+// Suppression accepts risk of a dependency until ExpiresAt. It applies to every project
+// unless ProjectName is set, to every version of the project unless ProjectVersion is set
+// and to every version of the dependency unless VersionRange, an npm range like "^1.2.0", is set.
+// Suppressed dependencies are flagged in listings and left out of score queries, summaries and policies.
+type Suppression struct {
+	ID             int64  `json:"id"`
+	Tenant         string `json:"tenant,omitempty"`
+	DependencyName string `json:"dependency_name"`
+	ProjectName    string `json:"project_name,omitempty"`
+	ProjectVersion string `json:"project_version,omitempty"`
+	VersionRange   string `json:"version_range,omitempty"`
+	Justification  string `json:"justification"`
+	Approver       string `json:"approver"`
+	ExpiresAt      int64  `json:"expires_at"`
+	CreatedAt      int64  `json:"created_at"`
+	// Expired suppressions are kept for the record, their findings are active again.
+	Expired bool `json:"expired"`
+}
+
+type CreateSuppressionRequest struct {
+	DependencyName string `json:"dependency_name"`
+	ProjectName    string `json:"project_name,omitempty"`
+	ProjectVersion string `json:"project_version,omitempty"`
+	VersionRange   string `json:"version_range,omitempty"`
+	Justification  string `json:"justification"`
+	Approver       string `json:"approver"`
+	// ExpiresAt is unix seconds, it is required and must be in the future.
+	ExpiresAt int64 `json:"expires_at"`
+}
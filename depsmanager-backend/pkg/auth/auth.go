@@ -0,0 +1,68 @@
+// Package auth generates and hashes api keys and carries the authenticated identity in context.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"depsmanager"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Header is an alternative to "Authorization: Bearer <key>".
+const Header = "X-API-Key"
+
+// keyPrefix makes keys recognizable, e.g. by secret scanners.
+const keyPrefix = "dm_"
+
+// PrefixLength is the number of leading key characters stored to tell keys apart.
+const PrefixLength = len(keyPrefix) + 8
+
+type ctxKey struct{}
+
+func NewContext(ctx context.Context, id depsmanager.Identity) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns identity of ctx, ok is false for unauthenticated requests.
+func FromContext(ctx context.Context) (depsmanager.Identity, bool) {
+	id, ok := ctx.Value(ctxKey{}).(depsmanager.Identity)
+	return id, ok
+}
+
+// GenerateKey returns a new random key with 256 bits of entropy.
+func GenerateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return keyPrefix + hex.EncodeToString(b), nil
+}
+
+// Hash returns hex encoded SHA-256 of key. Keys are random, so a fast unsalted hash is enough.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Prefix returns the stored part of key.
+func Prefix(key string) string {
+	if len(key) < PrefixLength {
+		return key
+	}
+	return key[:PrefixLength]
+}
+
+// KeyFromRequest returns key from Authorization bearer token or X-API-Key header.
+func KeyFromRequest(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); h != "" {
+		scheme, token, ok := strings.Cut(h, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return r.Header.Get(Header)
+}
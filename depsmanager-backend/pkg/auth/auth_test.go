@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"depsmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGenerateKey(t *testing.T) {
+	a, err := GenerateKey()
+	require.NoError(t, err)
+	b, err := GenerateKey()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, a, b)
+	assert.True(t, strings.HasPrefix(a, "dm_"))
+	assert.Len(t, a, 3+64)
+	assert.Equal(t, a[:11], Prefix(a))
+}
+
+func TestHash(t *testing.T) {
+	assert.Equal(t, Hash("dm_abc"), Hash("dm_abc"))
+	assert.NotEqual(t, Hash("dm_abc"), Hash("dm_abd"))
+	assert.Len(t, Hash("dm_abc"), 64)
+}
+
+func TestKeyFromRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"bearer", map[string]string{"Authorization": "Bearer dm_1"}, "dm_1"},
+		{"bearer lowercase", map[string]string{"Authorization": "bearer dm_1"}, "dm_1"},
+		{"other scheme", map[string]string{"Authorization": "Basic dXNlcg=="}, ""},
+		{"api key header", map[string]string{"X-API-Key": "dm_2"}, "dm_2"},
+		{"none", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			assert.Equal(t, tt.want, KeyFromRequest(r))
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	want := depsmanager.Identity{Name: "ci", Role: depsmanager.RoleReader}
+	got, ok := FromContext(NewContext(context.Background(), want))
+	assert.True(t, ok)
+	assert.Equal(t, want, got)
+}
+
+func TestRoleAllows(t *testing.T) {
+	assert.True(t, depsmanager.RoleAdmin.Allows(depsmanager.RoleEditor))
+	assert.True(t, depsmanager.RoleEditor.Allows(depsmanager.RoleReader))
+	assert.False(t, depsmanager.RoleReader.Allows(depsmanager.RoleEditor))
+	assert.False(t, depsmanager.Role("root").Allows(depsmanager.RoleReader))
+}
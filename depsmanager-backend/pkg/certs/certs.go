@@ -0,0 +1,136 @@
+// Package certs loads the TLS certificate of the server and reloads it when its files change,
+// so renewed certificates are served without restart.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"depsmanager"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader serves the certificate loaded last, see Watch.
+type Reloader struct {
+	certFile, keyFile string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+	failed  time.Time
+}
+
+// NewReloader loads the key pair of certFile and keyFile, both PEM encoded.
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the key pair again, on error the current certificate is kept.
+func (r *Reloader) Reload() error {
+	modTime, err := r.stat()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		r.mu.Lock()
+		r.failed = modTime
+		r.mu.Unlock()
+		return fmt.Errorf("tls.LoadX509KeyPair(): %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.modTime = &cert, modTime
+	return nil
+}
+
+// GetCertificate is tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Watch checks modification times of the files every interval until ctx is done and reloads them
+// after a change. A pair failing to load, e.g. while only one file is replaced, is retried after the next change.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		modTime, err := r.stat()
+		if err != nil {
+			slog.Warn("Certificate files not readable, keeping the current certificate", "error", err)
+			continue
+		}
+		r.mu.RLock()
+		unchanged := modTime.Equal(r.modTime) || modTime.Equal(r.failed)
+		r.mu.RUnlock()
+		if unchanged {
+			continue
+		}
+
+		if err := r.Reload(); err != nil {
+			slog.Error("Certificate reload failed, keeping the current certificate", "error", err)
+			continue
+		}
+		cert, _ := r.GetCertificate(nil)
+		slog.Info("Certificate reloaded", "subject", cert.Leaf.Subject.String(), "not_after", cert.Leaf.NotAfter)
+	}
+}
+
+// stat returns the latest modification time of both files.
+func (r *Reloader) stat() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("os.Stat(): %w", err)
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// ServerConfig returns TLS config serving certificates of r. With conf.TLSClientCAFile client certificates
+// are verified against it, connections without one are rejected only when conf.TLSClientCertRequired.
+func ServerConfig(conf depsmanager.TLSConfig, r *Reloader) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+	if conf.TLSClientCAFile == "" {
+		return cfg, nil
+	}
+
+	b, err := os.ReadFile(conf.TLSClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile(): %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("no PEM certificates in %s", conf.TLSClientCAFile)
+	}
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	if conf.TLSClientCertRequired {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
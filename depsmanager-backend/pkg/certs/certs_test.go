@@ -0,0 +1,210 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"depsmanager"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type keyPair struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+var serial int64
+
+// issue returns certificate for cn signed by parent, self-signed CA when parent is nil.
+func issue(t *testing.T, cn string, parent *keyPair) keyPair {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial++
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"Acme"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return keyPair{cert: cert, key: key}
+}
+
+// write stores the pair as PEM files in dir, modTime makes changes visible regardless of timestamp resolution.
+func (p keyPair) write(t *testing.T, dir string, modTime time.Time) (certFile, keyFile string) {
+	t.Helper()
+	keyDER, err := x509.MarshalECPrivateKey(p.key)
+	require.NoError(t, err)
+	certFile, keyFile = filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: p.cert.Raw}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	for _, f := range []string{certFile, keyFile} {
+		require.NoError(t, os.Chtimes(f, modTime, modTime))
+	}
+	return certFile, keyFile
+}
+
+func (p keyPair) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{p.cert.Raw}, PrivateKey: p.key}
+}
+
+// startServer returns URL of a server answering with subject of the verified client certificate.
+// httptest.Server is not used, it replaces GetCertificate with its own certificate.
+func startServer(t *testing.T, cfg *tls.Config) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(r.TLS.VerifiedChains) > 0 {
+				_, _ = w.Write([]byte(r.TLS.VerifiedChains[0][0].Subject.String()))
+			}
+		}),
+		TLSConfig: cfg,
+		ErrorLog:  log.New(io.Discard, "", 0),
+	}
+	go func() { _ = srv.ServeTLS(ln, "", "") }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return "https://" + ln.Addr().String()
+}
+
+// client trusts ca and presents certs even when the server asks for certificates of another CA.
+func client(ca *x509.Certificate, certs ...tls.Certificate) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{
+			RootCAs: pool,
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				if len(certs) == 0 {
+					return &tls.Certificate{}, nil
+				}
+				return &certs[0], nil
+			},
+		},
+		DisableKeepAlives: true,
+	}}
+}
+
+func servedSerial(t *testing.T, c *http.Client, url string) int64 {
+	t.Helper()
+	resp, err := c.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+}
+
+func TestReloader_Watch(t *testing.T) {
+	ca := issue(t, "ca", nil)
+	first := issue(t, "localhost", &ca)
+	dir := t.TempDir()
+	now := time.Now()
+	certFile, keyFile := first.write(t, dir, now.Add(-time.Minute))
+
+	r, err := NewReloader(certFile, keyFile)
+	require.NoError(t, err)
+	cfg, err := ServerConfig(depsmanager.TLSConfig{}, r)
+	require.NoError(t, err)
+	srv := startServer(t, cfg)
+	c := client(ca.cert)
+	assert.Equal(t, first.cert.SerialNumber.Int64(), servedSerial(t, c, srv))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 10*time.Millisecond)
+
+	// a broken pair keeps the current certificate
+	require.NoError(t, os.WriteFile(keyFile, []byte("garbage"), 0o600))
+	require.NoError(t, os.Chtimes(keyFile, now, now))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, first.cert.SerialNumber.Int64(), servedSerial(t, c, srv))
+
+	second := issue(t, "localhost", &ca)
+	second.write(t, dir, now.Add(time.Minute))
+	assert.Eventually(t, func() bool {
+		return servedSerial(t, c, srv) == second.cert.SerialNumber.Int64()
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestNewReloader_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewReloader(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "missing.key"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	ca := issue(t, "ca", nil)
+	certFile, _ := issue(t, "localhost", &ca).write(t, dir, time.Now())
+	_, keyFile := issue(t, "localhost", &ca).write(t, t.TempDir(), time.Now())
+	_, err = NewReloader(certFile, keyFile)
+	require.Error(t, err, "key of another certificate")
+}
+
+func TestServerConfig_ClientCertificates(t *testing.T) {
+	ca := issue(t, "ca", nil)
+	otherCA := issue(t, "other", nil)
+	dir := t.TempDir()
+	certFile, keyFile := issue(t, "localhost", &ca).write(t, dir, time.Now())
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0o600))
+
+	r, err := NewReloader(certFile, keyFile)
+	require.NoError(t, err)
+	ci := issue(t, "ci-runner", &ca).tlsCertificate()
+	forged := issue(t, "ci-runner", &otherCA).tlsCertificate()
+
+	for _, required := range []bool{false, true} {
+		cfg, err := ServerConfig(depsmanager.TLSConfig{TLSClientCAFile: caFile, TLSClientCertRequired: required}, r)
+		require.NoError(t, err)
+		srv := startServer(t, cfg)
+
+		resp, err := client(ca.cert, ci).Get(srv)
+		require.NoError(t, err)
+		body := make([]byte, 64)
+		n, _ := resp.Body.Read(body)
+		resp.Body.Close()
+		assert.Equal(t, "CN=ci-runner,O=Acme", string(body[:n]))
+
+		_, err = client(ca.cert, forged).Get(srv)
+		assert.Error(t, err, "certificate of unknown CA")
+
+		resp, err = client(ca.cert).Get(srv)
+		if required {
+			assert.Error(t, err, "no certificate")
+		} else {
+			require.NoError(t, err)
+			resp.Body.Close()
+		}
+	}
+
+	_, err = ServerConfig(depsmanager.TLSConfig{TLSClientCAFile: keyFile}, r)
+	require.ErrorContains(t, err, "no PEM certificates")
+}
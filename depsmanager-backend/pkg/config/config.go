@@ -0,0 +1,303 @@
+// Package config loads depsmanager.Config: defaults first, then the YAML file, then environment variables,
+// so a variable overrides the file and the file overrides the default. The result is validated.
+//
+//	server:
+//	  http_port: 8085
+//	  shutdown_timeout: 30s
+//	storage:
+//	  db_path: /data/storage.db
+//	gate:
+//	  min_score: 4
+//	log:
+//	  level: info
+//	policies:
+//	  - name: baseline
+//	    rules:
+//	      - type: min_score
+//	        value: 3
+package config
+
+import (
+	"bytes"
+	"depsmanager"
+	"depsmanager/pkg/policy"
+	"depsmanager/pkg/tenant"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvFile names the variable with path of the YAML file, used when no path is passed.
+const EnvFile = "CONFIG_FILE"
+
+// Load returns config of the YAML file at path merged with defaults and environment variables.
+// Empty path falls back to CONFIG_FILE, without both only defaults and environment are used.
+func Load(path string) (depsmanager.Config, error) {
+	if path == "" {
+		path = os.Getenv(EnvFile)
+	}
+
+	conf := depsmanager.DefaultConfig()
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return depsmanager.Config{}, fmt.Errorf("os.ReadFile(): %w", err)
+		}
+		if err := decode(b, &conf); err != nil {
+			return depsmanager.Config{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	// variables without default tags leave values of the file untouched
+	if err := envconfig.Process("", &conf); err != nil {
+		return depsmanager.Config{}, fmt.Errorf("envconfig.Process(): %w", err)
+	}
+
+	if err := Validate(conf); err != nil {
+		return depsmanager.Config{}, err
+	}
+	return conf, nil
+}
+
+// decode rejects unknown keys, so a typo does not silently keep the default.
+func decode(b []byte, conf *depsmanager.Config) error {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(conf); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// Validate returns all problems of conf at once, each names the YAML key and the variable.
+func Validate(conf depsmanager.Config) error {
+	var problems []string
+	add := func(key, env, msg string) {
+		if env != "" {
+			key += " (" + env + ")"
+		}
+		problems = append(problems, key+": "+msg)
+	}
+
+	if conf.HTTPPort < 1 || conf.HTTPPort > 65535 {
+		add("server.http_port", "HTTP_PORT", "must be between 1 and 65535")
+	}
+	if conf.ShutdownTimeout <= 0 {
+		add("server.shutdown_timeout", "SHUTDOWN_TIMEOUT", "must be positive")
+	}
+	if !validURL(conf.DepsAddress) {
+		add("deps.address", "DEPS_ADDRESS", "must be an absolute http(s) URL")
+	}
+	for i, m := range conf.DepsMirrors {
+		if !validURL(m) {
+			add(fmt.Sprintf("deps.mirrors[%d]", i), "DEPS_MIRRORS", "must be an absolute http(s) URL")
+		}
+	}
+	if conf.DepsProxy != "" && !validURL(conf.DepsProxy) {
+		add("deps.proxy", "DEPS_PROXY", "must be an absolute http(s) URL")
+	}
+	for i, f := range conf.DepsCAFiles {
+		if _, err := os.Stat(f); err != nil {
+			add(fmt.Sprintf("deps.ca_files[%d]", i), "DEPS_CA_FILES", "cannot be read: "+err.Error())
+		}
+	}
+	for _, d := range []struct {
+		key string
+		v   time.Duration
+	}{
+		{"connect", conf.DepsTimeouts.Connect},
+		{"versions", conf.DepsTimeouts.Versions},
+		{"dependencies", conf.DepsTimeouts.Dependencies},
+		{"batch", conf.DepsTimeouts.Batch},
+		{"ping", conf.DepsTimeouts.Ping},
+	} {
+		if d.v < 0 {
+			add("deps.timeouts."+d.key, "DEPS_TIMEOUT_"+strings.ToUpper(d.key), "must not be negative")
+		}
+	}
+	if b := conf.DepsBreaker; b.Failures < 0 {
+		add("deps.breaker.failures", "DEPS_BREAKER_FAILURES", "must not be negative")
+	} else if b.Failures > 0 {
+		if b.OpenTimeout <= 0 {
+			add("deps.breaker.open_timeout", "DEPS_BREAKER_OPEN_TIMEOUT", "must be positive")
+		}
+		if b.HalfOpenProbes < 1 {
+			add("deps.breaker.half_open_probes", "DEPS_BREAKER_HALF_OPEN_PROBES", "must be at least 1")
+		}
+	}
+	if l := conf.DepsLimits; l.Rate < 0 {
+		add("deps.limits.rate", "DEPS_LIMIT_RATE", "must not be negative")
+	} else if l.Rate > 0 && l.Burst < 1 {
+		add("deps.limits.burst", "DEPS_LIMIT_BURST", "must be at least 1 with a rate")
+	}
+	if conf.DepsLimits.MaxInFlight < 0 {
+		add("deps.limits.max_in_flight", "DEPS_LIMIT_MAX_IN_FLIGHT", "must not be negative")
+	}
+	if conf.DBPath == "" {
+		add("storage.db_path", "DB_PATH", "is required")
+	}
+	if conf.BusyTimeout < 0 {
+		add("storage.busy_timeout", "BUSY_TIMEOUT", "must not be negative")
+	}
+	if s := conf.GateMinScore; s != nil && (*s < 0 || *s > 10) {
+		add("gate.min_score", "GATE_MIN_SCORE", "must be between 0 and 10")
+	}
+	if n := conf.GateMaxUnscored; n != nil && *n < 0 {
+		add("gate.max_unscored", "GATE_MAX_UNSCORED", "must not be negative")
+	}
+	if n := conf.GateMaxScorecardAgeDays; n != nil && *n < 0 {
+		add("gate.max_scorecard_age_days", "GATE_MAX_SCORECARD_AGE_DAYS", "must not be negative")
+	}
+	if conf.PortfolioMetricsInterval < 0 {
+		add("scheduler.portfolio_metrics_interval", "PORTFOLIO_METRICS_INTERVAL", "must not be negative")
+	}
+	if s := conf.PortfolioScoreThreshold; s < 0 || s > 10 {
+		add("scheduler.portfolio_score_threshold", "PORTFOLIO_SCORE_THRESHOLD", "must be between 0 and 10")
+	}
+	if conf.PortfolioMetricsMaxSeries < 0 {
+		add("scheduler.portfolio_metrics_max_series", "PORTFOLIO_METRICS_MAX_SERIES", "must not be negative")
+	}
+	if conf.OTLPEndpoint != "" && !validURL(conf.OTLPEndpoint) {
+		add("tracing.otlp_endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT", "must be an absolute http(s) URL")
+	}
+
+	validateTLS(conf, add)
+	validateRateLimit(conf.RateLimitConfig, add)
+
+	seen := make(map[string]bool, len(conf.Policies))
+	for i, p := range conf.Policies {
+		key := fmt.Sprintf("policies[%d]", i)
+		if err := policy.Validate(p); err != nil {
+			add(key, "", strings.TrimPrefix(err.Error(), depsmanager.ErrInvalidPolicy.Error()+": "))
+		}
+		if id := p.ProjectName + "/" + p.Name; seen[id] {
+			add(key, "", fmt.Sprintf("duplicate policy %q", p.Name))
+		} else {
+			seen[id] = true
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+func validateTLS(conf depsmanager.Config, add func(key, env, msg string)) {
+	t := conf.TLSConfig
+	if (t.TLSCertFile == "") != (t.TLSKeyFile == "") {
+		add("tls.cert_file, tls.key_file", "TLS_CERT_FILE, TLS_KEY_FILE", "must be set together")
+	}
+	for _, f := range []struct{ key, env, path string }{
+		{"tls.cert_file", "TLS_CERT_FILE", t.TLSCertFile},
+		{"tls.key_file", "TLS_KEY_FILE", t.TLSKeyFile},
+		{"tls.client_ca_file", "TLS_CLIENT_CA_FILE", t.TLSClientCAFile},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			add(f.key, f.env, "cannot be read: "+err.Error())
+		}
+	}
+	if t.TLSClientCAFile != "" && t.TLSCertFile == "" {
+		add("tls.client_ca_file", "TLS_CLIENT_CA_FILE", "requires tls.cert_file")
+	}
+	if t.TLSClientCertRequired && t.TLSClientCAFile == "" {
+		add("tls.client_cert_required", "TLS_CLIENT_CERT_REQUIRED", "requires tls.client_ca_file")
+	}
+	if len(t.ClientIdentities) > 0 && (t.TLSClientCAFile == "" || !conf.AuthEnabled) {
+		add("tls.client_identities", "", "require tls.client_ca_file and auth.enabled")
+	}
+
+	subjects := make(map[string]bool, len(t.ClientIdentities))
+	for i, id := range t.ClientIdentities {
+		key := fmt.Sprintf("tls.client_identities[%d]", i)
+		if id.Subject == "" {
+			add(key, "", "subject is required")
+		} else if subjects[id.Subject] {
+			add(key, "", fmt.Sprintf("duplicate subject %q", id.Subject))
+		}
+		subjects[id.Subject] = true
+		if !id.Role.Valid() {
+			add(key, "", "role must be one of reader, editor, admin")
+		}
+		if id.Tenant != "" && !tenant.ValidName(id.Tenant) {
+			add(key, "", "tenant must be lowercase letters, digits and dashes")
+		}
+	}
+}
+
+func validateRateLimit(rl depsmanager.RateLimitConfig, add func(key, env, msg string)) {
+	// env is empty for overrides, they have no variables
+	quota := func(key, env string, q depsmanager.RateLimitQuota) {
+		rateEnv, burstEnv := "", ""
+		if env != "" {
+			rateEnv, burstEnv = env+"_RATE", env+"_BURST"
+		}
+		if q.Rate < 0 {
+			add(key+".rate", rateEnv, "must not be negative")
+		} else if q.Rate > 0 && q.Burst < 1 {
+			add(key+".burst", burstEnv, "must be at least 1 with a rate")
+		}
+	}
+	quota("rate_limit.default", "RATE_LIMIT_DEFAULT", rl.RateLimitDefault)
+	quota("rate_limit.expensive", "RATE_LIMIT_EXPENSIVE", rl.RateLimitExpensive)
+	quota("rate_limit.address", "RATE_LIMIT_ADDRESS", rl.RateLimitAddress)
+
+	seen := make(map[string]bool, len(rl.RateLimitOverrides))
+	for i, o := range rl.RateLimitOverrides {
+		key := fmt.Sprintf("rate_limit.overrides[%d]", i)
+		switch {
+		case o.Tenant == "" && o.APIKeyID == 0:
+			add(key, "", "tenant or api_key_id is required")
+		case o.Tenant != "" && o.APIKeyID != 0:
+			add(key, "", "tenant and api_key_id are exclusive")
+		case o.APIKeyID < 0:
+			add(key, "", "api_key_id must be positive")
+		}
+		if o.Tenant != "" && !tenant.ValidName(o.Tenant) {
+			add(key, "", "tenant must be lowercase letters, digits and dashes")
+		}
+		if id := fmt.Sprintf("%s/%d", o.Tenant, o.APIKeyID); seen[id] {
+			add(key, "", "duplicate override")
+		} else {
+			seen[id] = true
+		}
+		if o.Default == nil && o.Expensive == nil {
+			add(key, "", "default or expensive quota is required")
+		}
+		for _, q := range []struct {
+			name  string
+			quota *depsmanager.RateLimitQuota
+		}{{"default", o.Default}, {"expensive", o.Expensive}} {
+			if q.quota != nil {
+				quota(key+"."+q.name, "", *q.quota)
+			}
+		}
+	}
+}
+
+// RestartRequired reports whether next differs from current outside the settings applied on reload:
+// log level, gate thresholds and policies.
+func RestartRequired(current, next depsmanager.Config) bool {
+	for _, c := range []*depsmanager.Config{&current, &next} {
+		c.LogConfig = depsmanager.LogConfig{}
+		c.GateConfig = depsmanager.GateConfig{}
+		c.Policies = nil
+	}
+	return !reflect.DeepEqual(current, next)
+}
+
+func validURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"depsmanager"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "depsmanager.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, `
+server:
+  http_port: 9000
+  shutdown_timeout: 10s
+storage:
+  db_path: /data/file.db
+gate:
+  min_score: 4.5
+log:
+  level: debug
+policies:
+  - name: baseline
+    rules:
+      - type: min_score
+        value: 3
+`)
+	t.Setenv("DB_PATH", "/data/env.db")
+	t.Setenv("GATE_MAX_UNSCORED", "2")
+	t.Setenv("DEPS_TIMEOUT_BATCH", "2m")
+
+	conf, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 9000, conf.HTTPPort, "file overrides default")
+	assert.Equal(t, 10*time.Second, conf.ShutdownTimeout)
+	assert.Equal(t, "/data/env.db", conf.DBPath, "env overrides file")
+	assert.Equal(t, int64(5000), conf.BusyTimeout, "default kept")
+	assert.Equal(t, "https://api.deps.dev", conf.DepsAddress)
+	assert.Equal(t, 2*time.Minute, conf.DepsTimeouts.Batch, "nested env")
+	assert.Equal(t, 30*time.Second, conf.DepsTimeouts.Dependencies)
+	require.NotNil(t, conf.GateMinScore)
+	assert.Equal(t, 4.5, *conf.GateMinScore)
+	require.NotNil(t, conf.GateMaxUnscored)
+	assert.Equal(t, 2, *conf.GateMaxUnscored)
+	assert.Equal(t, slog.LevelDebug, conf.LogLevel)
+	require.Len(t, conf.Policies, 1)
+	assert.Equal(t, "baseline", conf.Policies[0].Name)
+}
+
+func TestLoad_EnvFile(t *testing.T) {
+	t.Setenv(EnvFile, writeFile(t, "server:\n  http_port: 9001\n"))
+	conf, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, 9001, conf.HTTPPort)
+
+	t.Setenv(EnvFile, "")
+	conf, err = Load("")
+	require.NoError(t, err)
+	assert.Equal(t, depsmanager.DefaultConfig(), conf)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name, file string
+		env        map[string]string
+		errs       []string
+	}{
+		{
+			name: "unknown key",
+			file: "server:\n  http_prot: 9000\n",
+			errs: []string{"field http_prot not found"},
+		},
+		{
+			name: "bad env value",
+			env:  map[string]string{"HTTP_PORT": "eighty"},
+			errs: []string{"HTTP_PORT"},
+		},
+		{
+			name: "tls",
+			file: `
+tls:
+  cert_file: /missing/tls.crt
+  client_cert_required: true
+  client_identities:
+    - subject: CN=ci
+      role: root
+    - subject: CN=ci
+      role: reader
+      tenant: Payments
+`,
+			errs: []string{
+				"tls.cert_file, tls.key_file (TLS_CERT_FILE, TLS_KEY_FILE): must be set together",
+				"tls.cert_file (TLS_CERT_FILE): cannot be read",
+				"tls.client_cert_required (TLS_CLIENT_CERT_REQUIRED): requires tls.client_ca_file",
+				"tls.client_identities: require tls.client_ca_file and auth.enabled",
+				"tls.client_identities[0]: role must be one of reader, editor, admin",
+				`tls.client_identities[1]: duplicate subject "CN=ci"`,
+				"tls.client_identities[1]: tenant must be lowercase letters, digits and dashes",
+			},
+		},
+		{
+			name: "rate limit",
+			file: `
+rate_limit:
+  default:
+    rate: -1
+  expensive:
+    rate: 2
+    burst: 0
+  address:
+    rate: -1
+  overrides:
+    - default:
+        rate: 5
+        burst: 5
+    - tenant: Payments
+      api_key_id: 7
+    - api_key_id: -1
+      default:
+        rate: 1
+        burst: 1
+    - tenant: team-a
+      expensive:
+        rate: 1
+        burst: 0
+    - tenant: team-a
+      default:
+        rate: 1
+        burst: 1
+`,
+			errs: []string{
+				"rate_limit.default.rate (RATE_LIMIT_DEFAULT_RATE): must not be negative",
+				"rate_limit.expensive.burst (RATE_LIMIT_EXPENSIVE_BURST): must be at least 1 with a rate",
+				"rate_limit.address.rate (RATE_LIMIT_ADDRESS_RATE): must not be negative",
+				"rate_limit.overrides[0]: tenant or api_key_id is required",
+				"rate_limit.overrides[1]: tenant and api_key_id are exclusive",
+				"rate_limit.overrides[1]: tenant must be lowercase letters, digits and dashes",
+				"rate_limit.overrides[1]: default or expensive quota is required",
+				"rate_limit.overrides[2]: api_key_id must be positive",
+				"rate_limit.overrides[3].expensive.burst: must be at least 1 with a rate",
+				"rate_limit.overrides[4]: duplicate override",
+			},
+		},
+		{
+			name: "all problems at once",
+			file: `
+server:
+  http_port: 70000
+deps:
+  address: api.deps.dev
+  mirrors: [https://mirror.example.com, mirror.example.com]
+  timeouts:
+    batch: -1s
+  breaker:
+    open_timeout: 0s
+  limits:
+    rate: 5
+    burst: 0
+gate:
+  min_score: 11
+policies:
+  - name: a
+    rules:
+      - type: max_age
+        value: 1
+  - name: a
+    rules:
+      - type: max_unscored
+        value: 1
+`,
+			env: map[string]string{"SHUTDOWN_TIMEOUT": "0s"},
+			errs: []string{
+				"server.http_port (HTTP_PORT): must be between 1 and 65535",
+				"server.shutdown_timeout (SHUTDOWN_TIMEOUT): must be positive",
+				"deps.address (DEPS_ADDRESS): must be an absolute http(s) URL",
+				"deps.mirrors[1] (DEPS_MIRRORS): must be an absolute http(s) URL",
+				"deps.timeouts.batch (DEPS_TIMEOUT_BATCH): must not be negative",
+				"deps.breaker.open_timeout (DEPS_BREAKER_OPEN_TIMEOUT): must be positive",
+				"deps.limits.burst (DEPS_LIMIT_BURST): must be at least 1 with a rate",
+				"gate.min_score (GATE_MIN_SCORE): must be between 0 and 10",
+				`policies[0]: rules[0]: unknown type "max_age"`,
+				`policies[1]: duplicate policy "a"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			path := ""
+			if tt.file != "" {
+				path = writeFile(t, tt.file)
+			}
+			_, err := Load(path)
+			require.Error(t, err)
+			for _, e := range tt.errs {
+				assert.Contains(t, err.Error(), e)
+			}
+		})
+	}
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRestartRequired(t *testing.T) {
+	current := depsmanager.DefaultConfig()
+	next := depsmanager.DefaultConfig()
+	score := 5.0
+	next.LogLevel = slog.LevelWarn
+	next.GateMinScore = &score
+	next.Policies = []depsmanager.Policy{{Name: "p"}}
+	assert.False(t, RestartRequired(current, next))
+
+	next.HTTPPort = 9000
+	assert.True(t, RestartRequired(current, next))
+}
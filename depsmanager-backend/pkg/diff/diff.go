@@ -34,7 +34,9 @@ func DiffDependencies(a, b []depsmanager.Dependency) (onlyA, onlyB []depsmanager
 func equalDep(a, b depsmanager.Dependency) bool {
 	return a.Name == b.Name &&
 		floatEq(a.Score, b.Score) &&
-		a.UpdatedAt == b.UpdatedAt
+		a.UpdatedAt == b.UpdatedAt &&
+		a.Relation == b.Relation &&
+		a.Version == b.Version
 }
 
 func floatEq(a, b float64) bool {
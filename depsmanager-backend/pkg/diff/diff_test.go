@@ -98,6 +98,23 @@ func TestDiffDependencies(t *testing.T) {
 				{Name: "x", Score: 1.00001, UpdatedAt: 100},
 			},
 		},
+		{
+			name: "same name, other version - both sides differ",
+			args: args{
+				a: []depsmanager.Dependency{
+					{Name: "x", Version: "1.0.0", Score: 1.0, UpdatedAt: 100},
+				},
+				b: []depsmanager.Dependency{
+					{Name: "x", Version: "1.0.1", Score: 1.0, UpdatedAt: 100},
+				},
+			},
+			wantOnlyA: []depsmanager.Dependency{
+				{Name: "x", Version: "1.0.0", Score: 1.0, UpdatedAt: 100},
+			},
+			wantOnlyB: []depsmanager.Dependency{
+				{Name: "x", Version: "1.0.1", Score: 1.0, UpdatedAt: 100},
+			},
+		},
 		{
 			name: "mix: one equal, one onlyA, one onlyB, one mismatch",
 			args: args{
@@ -0,0 +1,70 @@
+package errors
+
+import "depsmanager"
+
+// Error codes returned in Problem.Code, they are stable and safe to match on.
+// Generic codes follow the error type, specific codes the wrapped sentinel error.
+const (
+	CodeInternal     = "internal_error" // Internal and any unknown error
+	CodeBadRequest   = "bad_request"    // BadRequest
+	CodeNotFound     = "not_found"      // NotFoundRequest
+	CodeConflict     = "conflict"       // ConflictRequest
+	CodeUnauthorized = "unauthorized"   // Unauthorized
+	CodeForbidden    = "forbidden"      // Forbidden
+	CodeRateLimited  = "rate_limited"   // TooManyRequests, retry after Retry-After seconds
+
+	CodeValidationFailed         = "validation_failed" // BadRequest with ValidationError, see Problem.Errors
+	CodeProjectNotFound          = "project_not_found"
+	CodeDependencyNotFound       = "dependency_not_found"
+	CodeDependencyAlreadyExists  = "dependency_already_exists"
+	CodePolicyNotFound           = "policy_not_found"
+	CodeInvalidPolicy            = "invalid_policy"
+	CodePolicyEvaluationNotFound = "policy_evaluation_not_found"
+	CodeInvalidLockfile          = "invalid_lockfile"
+	CodeAPIKeyNotFound           = "api_key_not_found"
+	CodeTenantNotFound           = "tenant_not_found"
+	CodeTenantAlreadyExists      = "tenant_already_exists"
+	CodeTenantRequired           = "tenant_required"
+	CodeOverrideNotFound         = "override_not_found"
+	CodeSuppressionNotFound      = "suppression_not_found"
+	CodeUpstreamUnavailable      = "upstream_unavailable" // 503 with Retry-After, see depsmanager.UpstreamUnavailableError
+)
+
+const typeURIPrefix = "urn:depsmanager:error:"
+
+// sentinelCodes maps sentinel errors to specific codes, checked in order.
+var sentinelCodes = []struct {
+	err  error
+	code string
+}{
+	{depsmanager.ErrPolicyEvaluationNotFound, CodePolicyEvaluationNotFound},
+	{depsmanager.ErrProjectNotFound, CodeProjectNotFound},
+	{depsmanager.ErrDependencyNotFound, CodeDependencyNotFound},
+	{depsmanager.ErrDependencyAlreadyExists, CodeDependencyAlreadyExists},
+	{depsmanager.ErrPolicyNotFound, CodePolicyNotFound},
+	{depsmanager.ErrInvalidPolicy, CodeInvalidPolicy},
+	{depsmanager.ErrInvalidLockfile, CodeInvalidLockfile},
+	{depsmanager.ErrAPIKeyNotFound, CodeAPIKeyNotFound},
+	{depsmanager.ErrTenantNotFound, CodeTenantNotFound},
+	{depsmanager.ErrTenantAlreadyExists, CodeTenantAlreadyExists},
+	{depsmanager.ErrTenantRequired, CodeTenantRequired},
+	{depsmanager.ErrOverrideNotFound, CodeOverrideNotFound},
+	{depsmanager.ErrSuppressionNotFound, CodeSuppressionNotFound},
+	{depsmanager.ErrUpstreamUnavailable, CodeUpstreamUnavailable},
+	{depsmanager.ErrRateLimited, CodeRateLimited},
+}
+
+// TypeURI returns Problem.Type of code.
+func TypeURI(code string) string {
+	return typeURIPrefix + code
+}
+
+// SentinelOf returns sentinel error of code, nil for generic codes.
+func SentinelOf(code string) error {
+	for _, s := range sentinelCodes {
+		if s.code == code {
+			return s.err
+		}
+	}
+	return nil
+}
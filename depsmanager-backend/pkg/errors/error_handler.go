@@ -1,11 +1,19 @@
 package errors
 
 import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/requestid"
+	"encoding/json"
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 )
 
+const ContentTypeProblem = "application/problem+json"
+
 type InternalErr interface {
 	Internal()
 }
@@ -22,8 +30,36 @@ type ConflictErr interface {
 	ConflictRequest()
 }
 
-func logCode(code int, error error) {
-	log.Printf("api log: %s, statusCode: %v", error.Error(), code)
+type UnauthorizedErr interface {
+	Unauthorized()
+}
+
+type ForbiddenErr interface {
+	Forbidden()
+}
+
+type TooManyRequestsErr interface {
+	TooManyRequests()
+}
+
+// Problem is RFC 7807 problem details body written for every error response.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// logCode logs failed request, server errors on error level. Request ID is added from ctx by the log handler.
+func logCode(ctx context.Context, code int, err error) {
+	level := slog.LevelInfo
+	if code >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	slog.Log(ctx, level, "request failed", "status", code, "error", err.Error())
 }
 
 func HandleError(h func(http.ResponseWriter, *http.Request) error) func(http.ResponseWriter, *http.Request) {
@@ -33,42 +69,112 @@ func HandleError(h func(http.ResponseWriter, *http.Request) error) func(http.Res
 			return
 		}
 
-		// unwrap return nil if error is not wrapped
-		wrappedErr := errors.Unwrap(err)
-		if wrappedErr == nil {
-			wrappedErr = err
-		}
+		status, code := classify(err)
+		requestID := requestid.FromContext(r.Context())
+		logCode(r.Context(), status, err)
 
-		var internal InternalErr
-		if errors.As(wrappedErr, &internal) {
-			logCode(http.StatusInternalServerError, err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
+		p := Problem{
+			Type:      TypeURI(code),
+			Title:     http.StatusText(status),
+			Status:    status,
+			Code:      code,
+			RequestID: requestID,
 		}
-
-		var badRequest BadRequestErr
-		if errors.As(wrappedErr, &badRequest) {
-			logCode(http.StatusBadRequest, err)
-			w.WriteHeader(http.StatusBadRequest)
-			return
+		// internal errors may contain paths and queries, detail stays in the log only
+		if status != http.StatusInternalServerError {
+			p.Detail = detail(err)
 		}
-
-		var notFoundRequest NotFoundErr
-		if errors.As(wrappedErr, &notFoundRequest) {
-			logCode(http.StatusNotFound, err)
-			w.WriteHeader(http.StatusNotFound)
-			return
+		if status == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", "Bearer")
 		}
-
-		var conflictRequest ConflictErr
-		if errors.As(wrappedErr, &conflictRequest) {
-			logCode(http.StatusConflict, err)
-			w.WriteHeader(http.StatusConflict)
-			return
+		var unavailable *depsmanager.UpstreamUnavailableError
+		if errors.As(err, &unavailable) {
+			p.Detail = unavailable.Error()
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(unavailable.RetryAfter)))
+		}
+		if code == CodeTenantRequired {
+			p.Detail = depsmanager.ErrTenantRequired.Error()
 		}
+		var validation *ValidationError
+		if errors.As(err, &validation) {
+			p.Errors = validation.Fields
+		}
+
+		WriteProblem(w, p)
+	}
+}
+
+// WriteProblem writes p as application/problem+json response.
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		slog.Error("json.NewEncoder(w).Encode(problem) failed", "error", err)
+	}
+}
+
+// classify returns response status and error code of err. Unavailable deps.dev is 503 however handlers wrap it.
+func classify(err error) (int, string) {
+	if errors.Is(err, depsmanager.ErrUpstreamUnavailable) {
+		return http.StatusServiceUnavailable, CodeUpstreamUnavailable
+	}
+	// storage rejects writes and single project reads across tenants wherever they come from
+	if errors.Is(err, depsmanager.ErrTenantRequired) {
+		return http.StatusBadRequest, CodeTenantRequired
+	}
+	status, code := http.StatusInternalServerError, CodeInternal
 
+	var (
+		internal        InternalErr
+		badRequest      BadRequestErr
+		notFoundRequest NotFoundErr
+		conflictRequest ConflictErr
+		unauthorized    UnauthorizedErr
+		forbidden       ForbiddenErr
+		tooMany         TooManyRequestsErr
+	)
+	switch {
+	case errors.As(err, &internal):
+		return status, code
+	case errors.As(err, &badRequest):
+		status, code = http.StatusBadRequest, CodeBadRequest
+	case errors.As(err, &notFoundRequest):
+		status, code = http.StatusNotFound, CodeNotFound
+	case errors.As(err, &conflictRequest):
+		status, code = http.StatusConflict, CodeConflict
+	case errors.As(err, &unauthorized):
+		status, code = http.StatusUnauthorized, CodeUnauthorized
+	case errors.As(err, &forbidden):
+		status, code = http.StatusForbidden, CodeForbidden
+	case errors.As(err, &tooMany):
+		status, code = http.StatusTooManyRequests, CodeRateLimited
+	default:
 		// unknown error
-		logCode(http.StatusInternalServerError, err)
-		w.WriteHeader(http.StatusInternalServerError)
+		return status, code
+	}
+
+	var validation *ValidationError
+	if errors.As(err, &validation) {
+		return status, CodeValidationFailed
+	}
+	for _, s := range sentinelCodes {
+		if errors.Is(err, s.err) {
+			return status, s.code
+		}
+	}
+	return status, code
+}
+
+// retryAfterSeconds rounds d up to whole seconds, at least one.
+func retryAfterSeconds(d time.Duration) int {
+	return max(1, int((d+time.Second-1)/time.Second))
+}
+
+// detail returns message of the error wrapped by custom error type.
+func detail(err error) string {
+	var base interface{ Cause() error }
+	if errors.As(err, &base) && base.Cause() != nil {
+		return base.Cause().Error()
 	}
+	return err.Error()
 }
@@ -0,0 +1,134 @@
+package errors
+
+import (
+	"depsmanager"
+	"depsmanager/pkg/requestid"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleError(t *testing.T) {
+	var validation ValidationError
+	validation.Required("project_name", "")
+	validation.Required("version", "1.0.0")
+	validation.Add("score", "must be between 0 and 10")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+		wantFields []FieldError
+	}{
+		{
+			name:       "internal hides detail",
+			err:        NewInternal(fmt.Errorf("db.Exec(SELECT secret): %w", errors.New("locked"))),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   CodeInternal,
+		},
+		{
+			name:       "unknown error",
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   CodeInternal,
+		},
+		{
+			name:       "bad request",
+			err:        NewBadRequest(errors.New("cannot decode")),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeBadRequest,
+			wantDetail: "cannot decode",
+		},
+		{
+			name:       "validation",
+			err:        NewBadRequest(validation.Err()),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeValidationFailed,
+			wantDetail: "project_name is required, score must be between 0 and 10",
+			wantFields: []FieldError{{Field: "project_name", Message: "is required"}, {Field: "score", Message: "must be between 0 and 10"}},
+		},
+		{
+			name:       "not found sentinel",
+			err:        NewNotFound(fmt.Errorf("s.getProjectID(): %w", depsmanager.ErrProjectNotFound)),
+			wantStatus: http.StatusNotFound,
+			wantCode:   CodeProjectNotFound,
+			wantDetail: "s.getProjectID(): project not found",
+		},
+		{
+			name:       "not found generic",
+			err:        NewNotFound(errors.New("nothing here")),
+			wantStatus: http.StatusNotFound,
+			wantCode:   CodeNotFound,
+			wantDetail: "nothing here",
+		},
+		{
+			name:       "conflict wrapped by handler",
+			err:        fmt.Errorf("handler: %w", NewConflict(depsmanager.ErrDependencyAlreadyExists)),
+			wantStatus: http.StatusConflict,
+			wantCode:   CodeDependencyAlreadyExists,
+			wantDetail: "dependency already exists",
+		},
+		{
+			name:       "tenant required wrapped as internal",
+			err:        NewInternal(fmt.Errorf("service.UpsertDependency: %w", depsmanager.ErrTenantRequired)),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeTenantRequired,
+			wantDetail: "single tenant required, cross-tenant scope is read-only",
+		},
+		{
+			name:       "unauthorized",
+			err:        NewUnauthorized(depsmanager.ErrUnauthorized),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   CodeUnauthorized,
+			wantDetail: "missing or invalid api key",
+		},
+		{
+			name:       "forbidden",
+			err:        NewForbidden(depsmanager.ErrForbidden),
+			wantStatus: http.StatusForbidden,
+			wantCode:   CodeForbidden,
+			wantDetail: "insufficient role",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := requestid.Middleware(http.HandlerFunc(HandleError(func(http.ResponseWriter, *http.Request) error {
+				return tt.err
+			})))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(requestid.Header, "req-1")
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, ContentTypeProblem, rr.Header().Get("Content-Type"))
+
+			var p Problem
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p))
+			assert.Equal(t, Problem{
+				Type:      TypeURI(tt.wantCode),
+				Title:     http.StatusText(tt.wantStatus),
+				Status:    tt.wantStatus,
+				Detail:    tt.wantDetail,
+				Code:      tt.wantCode,
+				RequestID: "req-1",
+				Errors:    tt.wantFields,
+			}, p)
+		})
+	}
+}
+
+func TestSentinelOf(t *testing.T) {
+	for _, s := range sentinelCodes {
+		assert.Equal(t, s.err, SentinelOf(s.code))
+	}
+	assert.Nil(t, SentinelOf(CodeNotFound))
+}
@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 type BaseError struct {
@@ -17,6 +18,10 @@ func (e *BaseError) Cause() error {
 	return e.Err
 }
 
+func (e *BaseError) Unwrap() error {
+	return e.Err
+}
+
 func (e *BaseError) Is(target error) bool {
 	return errors.Is(e.Err, target)
 }
@@ -56,3 +61,74 @@ func NewConflict(err error) *ConflictRequest {
 	return &ConflictRequest{BaseError: BaseError{Err: err}}
 }
 func (e ConflictRequest) ConflictRequest() {}
+
+type Unauthorized struct {
+	BaseError
+}
+
+func NewUnauthorized(err error) *Unauthorized {
+	return &Unauthorized{BaseError: BaseError{Err: err}}
+}
+func (e Unauthorized) Unauthorized() {}
+
+type Forbidden struct {
+	BaseError
+}
+
+func NewForbidden(err error) *Forbidden {
+	return &Forbidden{BaseError: BaseError{Err: err}}
+}
+func (e Forbidden) Forbidden() {}
+
+type TooManyRequests struct {
+	BaseError
+}
+
+func NewTooManyRequests(err error) *TooManyRequests {
+	return &TooManyRequests{BaseError: BaseError{Err: err}}
+}
+func (e TooManyRequests) TooManyRequests() {}
+
+// FieldError describes invalid field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects field errors of a request, wrap it in BadRequest:
+//
+//	var v customErr.ValidationError
+//	v.Required("project_name", req.ProjectName)
+//	if err := v.Err(); err != nil {
+//		return customErr.NewBadRequest(err)
+//	}
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+" "+f.Message)
+	}
+	return strings.Join(msgs, ", ")
+}
+
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Required adds field error when value is empty.
+func (e *ValidationError) Required(field, value string) {
+	if value == "" {
+		e.Add(field, "is required")
+	}
+}
+
+// Err returns nil when no field error was added.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: e.Fields}
+}
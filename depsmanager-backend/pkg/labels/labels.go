@@ -0,0 +1,133 @@
+// Package labels validates project labels and parses label selectors, e.g. "team=payments,env!=dev,critical".
+package labels
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const maxLength = 63
+
+var (
+	keyRe   = regexp.MustCompile(`^[a-z0-9]([a-z0-9._/-]*[a-z0-9])?$`)
+	valueRe = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?)?$`)
+)
+
+// ValidKey reports whether k is lowercase letters, digits and ".", "_", "/", "-" inside, at most 63 characters.
+func ValidKey(k string) bool {
+	return len(k) <= maxLength && keyRe.MatchString(k)
+}
+
+// ValidValue reports whether v is empty or letters, digits and ".", "_", "-" inside, at most 63 characters.
+func ValidValue(v string) bool {
+	return len(v) <= maxLength && valueRe.MatchString(v)
+}
+
+// Format returns labels as comma separated key=value pairs sorted by key, the form accepted by Parse.
+func Format(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Selector matches labels satisfying all of its requirements. Zero Selector matches everything.
+type Selector struct {
+	requirements []requirement
+}
+
+type requirement struct {
+	key   string
+	op    string // one of =, !=, exists, !exists
+	value string
+}
+
+// Parse parses comma separated requirements: "key=value" (or "key==value"), "key!=value",
+// "key" when the label is set and "!key" when it is not. Label missing on a project does not equal any value,
+// so "env!=prod" matches projects without env label as well.
+func Parse(s string) (Selector, error) {
+	var sel Selector
+	if strings.TrimSpace(s) == "" {
+		return sel, nil
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		r, err := parseRequirement(part)
+		if err != nil {
+			return Selector{}, fmt.Errorf("selector %q: %w", s, err)
+		}
+		sel.requirements = append(sel.requirements, r)
+	}
+	return sel, nil
+}
+
+func parseRequirement(s string) (requirement, error) {
+	var r requirement
+	switch {
+	case strings.HasPrefix(s, "!") && !strings.Contains(s, "="):
+		r = requirement{key: strings.TrimSpace(s[1:]), op: "!exists"}
+	case strings.Contains(s, "!="):
+		k, v, _ := strings.Cut(s, "!=")
+		r = requirement{key: strings.TrimSpace(k), op: "!=", value: strings.TrimSpace(v)}
+	case strings.Contains(s, "="):
+		k, v, _ := strings.Cut(s, "=")
+		r = requirement{key: strings.TrimSpace(k), op: "=", value: strings.TrimSpace(strings.TrimPrefix(v, "="))}
+	default:
+		r = requirement{key: s, op: "exists"}
+	}
+
+	if !ValidKey(r.key) {
+		return requirement{}, fmt.Errorf("invalid label key %q", r.key)
+	}
+	if !ValidValue(r.value) {
+		return requirement{}, fmt.Errorf("invalid label value %q", r.value)
+	}
+	return r, nil
+}
+
+// Empty reports whether s has no requirements.
+func (s Selector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+// Matches reports whether labels satisfy every requirement of s.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s.requirements {
+		v, ok := labels[r.key]
+		var match bool
+		switch r.op {
+		case "exists":
+			match = ok
+		case "!exists":
+			match = !ok
+		case "!=":
+			match = !ok || v != r.value
+		default:
+			match = ok && v == r.value
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Selector) String() string {
+	parts := make([]string, 0, len(s.requirements))
+	for _, r := range s.requirements {
+		switch r.op {
+		case "exists":
+			parts = append(parts, r.key)
+		case "!exists":
+			parts = append(parts, "!"+r.key)
+		default:
+			parts = append(parts, r.key+r.op+r.value)
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,48 @@
+package labels
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelector_Matches(t *testing.T) {
+	payments := map[string]string{"team": "payments", "env": "prod", "critical": ""}
+	search := map[string]string{"team": "search", "env": "dev"}
+
+	tests := []struct {
+		selector string
+		want     []bool // payments, search, no labels
+	}{
+		{selector: "", want: []bool{true, true, true}},
+		{selector: "team=payments,env=prod", want: []bool{true, false, false}},
+		{selector: "team==search", want: []bool{false, true, false}},
+		{selector: "env!=prod", want: []bool{false, true, true}},
+		{selector: "critical", want: []bool{true, false, false}},
+		{selector: "!critical", want: []bool{false, true, true}},
+		{selector: " team = payments , !owner ", want: []bool{true, false, false}},
+	}
+	for _, tt := range tests {
+		sel, err := Parse(tt.selector)
+		require.NoError(t, err, tt.selector)
+		for i, l := range []map[string]string{payments, search, nil} {
+			assert.Equal(t, tt.want[i], sel.Matches(l), "%q on %v", tt.selector, l)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, s := range []string{"team=payments,", "Team=x", "team=a b", "=x", "!", "team=payments=x"} {
+		_, err := Parse(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParse_String(t *testing.T) {
+	sel, err := Parse("team==payments, env!=dev,critical,!legacy")
+	require.NoError(t, err)
+	assert.Equal(t, "team=payments,env!=dev,critical,!legacy", sel.String())
+	assert.True(t, Selector{}.Empty())
+	assert.Equal(t, "env=prod,team=payments", Format(map[string]string{"team": "payments", "env": "prod"}))
+}
@@ -0,0 +1,120 @@
+package lockfile
+
+import (
+	"depsmanager"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const nodeModules = "node_modules/"
+
+// Package is a single npm package resolved in package-lock.json.
+type Package struct {
+	Name     string
+	Version  string
+	Relation string
+}
+
+type packageLock struct {
+	LockfileVersion int                       `json:"lockfileVersion"`
+	Packages        map[string]lockPackage    `json:"packages"`
+	Dependencies    map[string]lockDependency `json:"dependencies"`
+}
+
+type lockPackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Link                 bool              `json:"link"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+type lockDependency struct {
+	Version      string                    `json:"version"`
+	Dependencies map[string]lockDependency `json:"dependencies"`
+}
+
+// Parse reads packages from package-lock.json in lockfile version 1, 2 or 3.
+// Each package name is returned once, sorted by name. When the same package is installed
+// in several versions, the hoisted (top-level) one wins.
+// Relation is DIRECT for packages declared by the root project and INDIRECT otherwise,
+// it is left empty for lockfile version 1 which does not record root declarations.
+// Returned errors wrap depsmanager.ErrInvalidLockfile.
+func Parse(data []byte) ([]Package, error) {
+	var lock packageLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("%w: %v", depsmanager.ErrInvalidLockfile, err)
+	}
+
+	var packages map[string]Package
+	switch {
+	case lock.Packages != nil:
+		packages = fromPackages(lock.Packages)
+	case lock.Dependencies != nil:
+		packages = map[string]Package{}
+		fromDependencies(lock.Dependencies, packages)
+	default:
+		return nil, fmt.Errorf("%w: neither packages nor dependencies found", depsmanager.ErrInvalidLockfile)
+	}
+
+	result := make([]Package, 0, len(packages))
+	for _, p := range packages {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
+
+func fromPackages(lockPackages map[string]lockPackage) map[string]Package {
+	root := lockPackages[""]
+	direct := map[string]bool{}
+	for _, deps := range []map[string]string{root.Dependencies, root.DevDependencies, root.OptionalDependencies, root.PeerDependencies} {
+		for name := range deps {
+			direct[name] = true
+		}
+	}
+
+	packages := map[string]Package{}
+	for path, lp := range lockPackages {
+		idx := strings.LastIndex(path, nodeModules)
+		if idx < 0 || lp.Link || lp.Version == "" {
+			continue
+		}
+		name := lp.Name
+		if name == "" {
+			name = path[idx+len(nodeModules):]
+		}
+		hoisted := idx == 0
+
+		if existing, ok := packages[name]; ok && (!hoisted || existing.Relation == depsmanager.RelationDirect) {
+			continue
+		}
+
+		relation := depsmanager.RelationIndirect
+		if hoisted && direct[name] {
+			relation = depsmanager.RelationDirect
+		}
+		packages[name] = Package{Name: name, Version: lp.Version, Relation: relation}
+	}
+
+	return packages
+}
+
+// fromDependencies walks lockfile version 1 tree, top-level entries are visited before nested ones.
+func fromDependencies(deps map[string]lockDependency, packages map[string]Package) {
+	for name, d := range deps {
+		if _, ok := packages[name]; !ok && d.Version != "" {
+			packages[name] = Package{Name: name, Version: d.Version}
+		}
+	}
+	for _, d := range deps {
+		if len(d.Dependencies) > 0 {
+			fromDependencies(d.Dependencies, packages)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package lockfile
+
+import (
+	"depsmanager"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		lock    string
+		want    []Package
+		wantErr bool
+	}{
+		{
+			name: "lockfile v3",
+			lock: `{
+  "name": "app",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "dependencies": {"react": "^18.0.0"}, "devDependencies": {"@types/node": "^20.0.0"}},
+    "node_modules/react": {"version": "18.3.1"},
+    "node_modules/loose-envify": {"version": "1.4.0"},
+    "node_modules/@types/node": {"version": "20.1.0"},
+    "node_modules/react/node_modules/loose-envify": {"version": "1.3.0"},
+    "node_modules/local": {"resolved": "packages/local", "link": true}
+  }
+}`,
+			want: []Package{
+				{Name: "@types/node", Version: "20.1.0", Relation: depsmanager.RelationDirect},
+				{Name: "loose-envify", Version: "1.4.0", Relation: depsmanager.RelationIndirect},
+				{Name: "react", Version: "18.3.1", Relation: depsmanager.RelationDirect},
+			},
+		},
+		{
+			name: "lockfile v2 nested only",
+			lock: `{
+  "lockfileVersion": 2,
+  "packages": {
+    "": {"dependencies": {"a": "1"}},
+    "node_modules/a": {"version": "1.0.0"},
+    "node_modules/a/node_modules/b": {"version": "2.0.0"}
+  },
+  "dependencies": {"a": {"version": "1.0.0"}}
+}`,
+			want: []Package{
+				{Name: "a", Version: "1.0.0", Relation: depsmanager.RelationDirect},
+				{Name: "b", Version: "2.0.0", Relation: depsmanager.RelationIndirect},
+			},
+		},
+		{
+			name: "lockfile v1",
+			lock: `{
+  "lockfileVersion": 1,
+  "dependencies": {
+    "a": {"version": "1.0.0", "dependencies": {"b": {"version": "1.0.0"}, "c": {"version": "3.0.0"}}},
+    "b": {"version": "2.0.0"}
+  }
+}`,
+			want: []Package{
+				{Name: "a", Version: "1.0.0"},
+				{Name: "b", Version: "2.0.0"},
+				{Name: "c", Version: "3.0.0"},
+			},
+		},
+		{name: "empty lockfile", lock: `{"lockfileVersion": 3, "packages": {"": {}}}`, want: []Package{}},
+		{name: "not json", lock: `lockfileVersion: 3`, wantErr: true},
+		{name: "no packages", lock: `{"name": "app"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.lock))
+			if tt.wantErr {
+				require.ErrorIs(t, err, depsmanager.ErrInvalidLockfile)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Package logging builds slog loggers writing JSON lines. Records logged with a context, e.g. slog.InfoContext,
+// carry request_id and trace_id of that context, so lines of one request can be correlated.
+package logging
+
+import (
+	"context"
+	"depsmanager/pkg/requestid"
+	"depsmanager/pkg/trace"
+	"io"
+	"log/slog"
+)
+
+// New returns a JSON logger writing records of level and above to w. Pass a *slog.LevelVar to change the level later.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(Handler(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})))
+}
+
+// Handler adds request and trace IDs of the record context to records passed to next.
+func Handler(next slog.Handler) slog.Handler {
+	return contextHandler{next}
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := requestid.FromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID.String()), slog.String("span_id", sc.SpanID.String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
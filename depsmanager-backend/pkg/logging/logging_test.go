@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"depsmanager/pkg/requestid"
+	"depsmanager/pkg/trace"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	var b bytes.Buffer
+	var level slog.LevelVar
+	logger := New(&b, &level).With("component", "api")
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	ctx, span := trace.NewTracer(nil).Start(ctx, "request", trace.KindServer)
+	logger.DebugContext(ctx, "hidden")
+	logger.InfoContext(ctx, "request", "status", 200)
+	logger.Info("no context")
+
+	level.Set(slog.LevelDebug)
+	logger.DebugContext(ctx, "shown")
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var first map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "INFO", first["level"])
+	assert.Equal(t, "request", first["msg"])
+	assert.Equal(t, "api", first["component"])
+	assert.Equal(t, float64(200), first["status"])
+	assert.Equal(t, "req-1", first["request_id"])
+	assert.Equal(t, span.SpanContext().TraceID.String(), first["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID.String(), first["span_id"])
+
+	var second map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.NotContains(t, second, "request_id")
+	assert.NotContains(t, second, "trace_id")
+
+	assert.Contains(t, lines[2], `"msg":"shown"`)
+}
@@ -0,0 +1,296 @@
+// Package metrics keeps counters, gauges and histograms and exposes them in the Prometheus text format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType of the exposition, Prometheus text format 0.0.4.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are upper bounds of latency histograms in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry holds metric families in registration order.
+type Registry struct {
+	mu       sync.Mutex
+	families []family
+	names    map[string]struct{}
+}
+
+type family interface {
+	write(w io.Writer) error
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]struct{})}
+}
+
+func (r *Registry) register(name string, f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.names[name]; ok {
+		panic(fmt.Sprintf("metrics: %s registered twice", name))
+	}
+	r.names[name] = struct{}{}
+	r.families = append(r.families, f)
+}
+
+// Write writes all metrics in the text format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	families := append([]family(nil), r.families...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		if err := f.write(bw); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Handler serves metrics of r, e.g. on /metrics.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		_ = r.Write(w)
+	})
+}
+
+// vec keeps one series per combination of label values.
+type vec[T any] struct {
+	name, help, kind string
+	labels           []string
+
+	mu     sync.Mutex
+	series map[string]*T
+	values map[string][]string
+}
+
+func newVec[T any](name, help, kind string, labels []string) *vec[T] {
+	return &vec[T]{name: name, help: help, kind: kind, labels: labels, series: make(map[string]*T), values: make(map[string][]string)}
+}
+
+// get returns series of labelValues, v.mu must be held. create makes a missing series.
+func (v *vec[T]) get(labelValues []string, create func() *T) *T {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = create()
+		v.series[key] = s
+		v.values[key] = append([]string(nil), labelValues...)
+	}
+	return s
+}
+
+// sorted returns series keys in a stable order, v.mu must be held.
+func (v *vec[T]) sorted() []string {
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (v *vec[T]) header(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, escapeHelp(v.help), v.name, v.kind)
+	return err
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct {
+	*vec[float64]
+}
+
+// NewCounterVec registers counter name, it panics when the name is taken.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newVec[float64](name, help, "counter", labels)}
+	r.register(name, c)
+	return c
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increases counter of labelValues by delta, negative delta panics.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: %s cannot decrease", c.name))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*c.get(labelValues, func() *float64 { return new(float64) }) += delta
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	return writeValues(w, c.vec)
+}
+
+// GaugeVec is a gauge partitioned by labels.
+type GaugeVec struct {
+	*vec[float64]
+}
+
+// Sample is a value of one gauge series.
+type Sample struct {
+	Value  float64
+	Labels []string
+}
+
+// NewGaugeVec registers gauge name, it panics when the name is taken.
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{newVec[float64](name, help, "gauge", labels)}
+	r.register(name, g)
+	return g
+}
+
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	*g.get(labelValues, func() *float64 { return new(float64) }) = value
+}
+
+// Replace swaps all series for samples at once, series missing in samples disappear.
+func (g *GaugeVec) Replace(samples []Sample) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.series = make(map[string]*float64, len(samples))
+	g.values = make(map[string][]string, len(samples))
+	for _, s := range samples {
+		*g.get(s.Labels, func() *float64 { return new(float64) }) = s.Value
+	}
+}
+
+func (g *GaugeVec) write(w io.Writer) error {
+	return writeValues(w, g.vec)
+}
+
+// writeValues writes series of counter or gauge v.
+func writeValues(w io.Writer, v *vec[float64]) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err := v.header(w); err != nil {
+		return err
+	}
+	for _, k := range v.sorted() {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", v.name, formatLabels(v.labels, v.values[k]), formatFloat(*v.series[k])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HistogramVec is a histogram partitioned by labels.
+type HistogramVec struct {
+	*vec[histogram]
+	buckets []float64
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec registers histogram name with sorted bucket upper bounds, +Inf is implicit.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: %s buckets are not sorted", name))
+	}
+	h := &HistogramVec{vec: newVec[histogram](name, help, "histogram", labels), buckets: buckets}
+	r.register(name, h)
+	return h
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.get(labelValues, func() *histogram { return &histogram{counts: make([]uint64, len(h.buckets))} })
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += value
+}
+
+func (h *HistogramVec) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.header(w); err != nil {
+		return err
+	}
+	labels := append(append([]string(nil), h.labels...), "le")
+	for _, k := range h.sorted() {
+		s, values := h.series[k], h.values[k]
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += s.counts[i]
+			le := formatLabels(labels, append(append([]string(nil), values...), formatFloat(upper)))
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, le, cumulative); err != nil {
+				return err
+			}
+		}
+		le := formatLabels(labels, append(append([]string(nil), values...), "+Inf"))
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, le, s.count); err != nil {
+			return err
+		}
+		plain := formatLabels(h.labels, values)
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", h.name, plain, formatFloat(s.sum), h.name, plain, s.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(n)
+		b.WriteString(`="`)
+		b.WriteString(escapeValue(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	valueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeValue(s string) string { return valueEscaper.Replace(s) }
+
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("http_requests_total", "Requests served.", "route", "status")
+	latency := r.NewHistogramVec("http_request_duration_seconds", "Request latency.", []float64{0.1, 1}, "route")
+
+	requests.Inc("/b", "200")
+	requests.Add(2, "/a", "500")
+	requests.Inc("/b", "200")
+	latency.Observe(0.05, `/x"y`)
+	latency.Observe(0.1, `/x"y`)
+	latency.Observe(3, `/x"y`)
+
+	var b strings.Builder
+	require.NoError(t, r.Write(&b))
+	assert.Equal(t, `# HELP http_requests_total Requests served.
+# TYPE http_requests_total counter
+http_requests_total{route="/a",status="500"} 2
+http_requests_total{route="/b",status="200"} 2
+# HELP http_request_duration_seconds Request latency.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{route="/x\"y",le="0.1"} 2
+http_request_duration_seconds_bucket{route="/x\"y",le="1"} 2
+http_request_duration_seconds_bucket{route="/x\"y",le="+Inf"} 3
+http_request_duration_seconds_sum{route="/x\"y"} 3.15
+http_request_duration_seconds_count{route="/x\"y"} 3
+`, b.String())
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("fetches_total", "Fetches.").Inc()
+
+	rr := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, ContentType, rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), "fetches_total 1\n")
+}
+
+func TestRegistry_Misuse(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("a_total", "A.", "kind")
+	assert.Panics(t, func() { r.NewCounterVec("a_total", "A again.") })
+	assert.Panics(t, func() { c.Inc() })
+	assert.Panics(t, func() { c.Add(-1, "x") })
+	assert.Panics(t, func() { r.NewHistogramVec("b", "B.", []float64{1, 0.5}) })
+}
+
+func TestGaugeVec_Replace(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGaugeVec("min_score", "Minimum score.", "project")
+	g.Set(3, "react")
+	g.Set(4, "vue")
+	g.Replace([]Sample{{Value: 5.5, Labels: []string{"vue"}}})
+
+	var b strings.Builder
+	require.NoError(t, r.Write(&b))
+	assert.Equal(t, "# HELP min_score Minimum score.\n# TYPE min_score gauge\nmin_score{project=\"vue\"} 5.5\n", b.String())
+}
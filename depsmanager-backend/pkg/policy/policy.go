@@ -0,0 +1,164 @@
+package policy
+
+import (
+	"bytes"
+	"depsmanager"
+	"depsmanager/pkg/suppression"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const day = 24 * time.Hour
+
+// Parse decodes a YAML policy document and validates it, e.g.:
+//
+//	name: baseline
+//	project_name: react # optional, global policy when empty
+//	rules:
+//	  - type: min_score
+//	    relation: direct
+//	    value: 4
+//	  - type: max_scorecard_age_days
+//	    value: 180
+//	  - type: max_unscored
+//	    value: 5
+//
+// Returned errors wrap depsmanager.ErrInvalidPolicy.
+func Parse(document []byte) (depsmanager.Policy, error) {
+	var p depsmanager.Policy
+
+	dec := yaml.NewDecoder(bytes.NewReader(document))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		if errors.Is(err, io.EOF) {
+			return depsmanager.Policy{}, fmt.Errorf("%w: empty document", depsmanager.ErrInvalidPolicy)
+		}
+		return depsmanager.Policy{}, fmt.Errorf("%w: %v", depsmanager.ErrInvalidPolicy, err)
+	}
+
+	if err := Validate(p); err != nil {
+		return depsmanager.Policy{}, err
+	}
+
+	p.Document = string(document)
+	return p, nil
+}
+
+// Validate checks that policy has a name and only well-formed rules.
+func Validate(p depsmanager.Policy) error {
+	if p.Name == "" {
+		return fmt.Errorf("%w: name is required", depsmanager.ErrInvalidPolicy)
+	}
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("%w: at least one rule is required", depsmanager.ErrInvalidPolicy)
+	}
+
+	for i, r := range p.Rules {
+		switch r.Type {
+		case depsmanager.PolicyRuleMinScore:
+			switch strings.ToUpper(r.Relation) {
+			case "", depsmanager.RelationDirect, depsmanager.RelationIndirect:
+			default:
+				return fmt.Errorf("%w: rules[%d]: unknown relation %q", depsmanager.ErrInvalidPolicy, i, r.Relation)
+			}
+		case depsmanager.PolicyRuleMaxScorecardAgeDays, depsmanager.PolicyRuleMaxUnscored:
+			if r.Relation != "" {
+				return fmt.Errorf("%w: rules[%d]: relation is supported only by %s", depsmanager.ErrInvalidPolicy, i, depsmanager.PolicyRuleMinScore)
+			}
+		default:
+			return fmt.Errorf("%w: rules[%d]: unknown type %q", depsmanager.ErrInvalidPolicy, i, r.Type)
+		}
+
+		if r.Value < 0 {
+			return fmt.Errorf("%w: rules[%d]: value must not be negative", depsmanager.ErrInvalidPolicy, i)
+		}
+	}
+
+	return nil
+}
+
+// Evaluate checks deps against every rule of policies and returns found violations.
+// Dependencies without scorecard (UpdatedAt == 0) are checked only by max_unscored rule, manual ones
+// are not checked by max_scorecard_age_days, suppressed dependencies are not checked at all.
+func Evaluate(policies []depsmanager.Policy, deps []depsmanager.Dependency, now time.Time) []depsmanager.PolicyViolation {
+	deps = suppression.Unsuppressed(deps)
+	violations := []depsmanager.PolicyViolation{}
+	for _, p := range policies {
+		for _, r := range p.Rules {
+			violations = append(violations, evaluateRule(p.Name, r, deps, now)...)
+		}
+	}
+	return violations
+}
+
+// Verdict returns VerdictFail if there is any violation, VerdictPass otherwise.
+func Verdict(violations []depsmanager.PolicyViolation) string {
+	if len(violations) > 0 {
+		return depsmanager.VerdictFail
+	}
+	return depsmanager.VerdictPass
+}
+
+func evaluateRule(policyName string, r depsmanager.PolicyRule, deps []depsmanager.Dependency, now time.Time) []depsmanager.PolicyViolation {
+	var violations []depsmanager.PolicyViolation
+	violation := func(depName, msg string) {
+		violations = append(violations, depsmanager.PolicyViolation{
+			Policy:         policyName,
+			Rule:           r.Type,
+			DependencyName: depName,
+			Message:        msg,
+		})
+	}
+
+	switch r.Type {
+	case depsmanager.PolicyRuleMinScore:
+		for _, d := range deps {
+			if d.UpdatedAt == 0 || !matchesRelation(r.Relation, d.Relation) {
+				continue
+			}
+			if d.Score < r.Value {
+				violation(d.Name, fmt.Sprintf("score %.2f is below %.2f", d.Score, r.Value))
+			}
+		}
+	case depsmanager.PolicyRuleMaxScorecardAgeDays:
+		maxAge := time.Duration(r.Value * float64(day))
+		for _, d := range deps {
+			if d.UpdatedAt == 0 || d.Source == depsmanager.SourceManual {
+				continue
+			}
+			if age := now.Sub(time.Unix(d.UpdatedAt, 0)); age > maxAge {
+				violation(d.Name, fmt.Sprintf("scorecard is %d days old, maximum is %v", int(age/day), r.Value))
+			}
+		}
+	case depsmanager.PolicyRuleMaxUnscored:
+		var unscored int
+		for _, d := range deps {
+			if d.UpdatedAt == 0 {
+				unscored++
+			}
+		}
+		if float64(unscored) > r.Value {
+			violation("", fmt.Sprintf("%d dependencies have no scorecard, maximum is %v", unscored, r.Value))
+		}
+	}
+
+	return violations
+}
+
+// matchesRelation reports whether relation got is checked by a rule of relation want. Unknown relations, e.g. of
+// manual dependencies or lockfile v1 packages, are checked by rules of either.
+func matchesRelation(want, got string) bool {
+	switch strings.ToUpper(want) {
+	case "":
+		return true
+	case depsmanager.RelationDirect:
+		return got == "" || got == depsmanager.RelationDirect
+	default:
+		return got != depsmanager.RelationDirect
+	}
+}
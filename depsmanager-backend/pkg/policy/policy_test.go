@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"depsmanager"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr bool
+	}{
+		{
+			name: "valid global policy",
+			doc: `
+name: baseline
+rules:
+  - type: min_score
+    relation: direct
+    value: 4
+  - type: max_scorecard_age_days
+    value: 180
+  - type: max_unscored
+    value: 5
+`,
+		},
+		{name: "empty document", doc: "", wantErr: true},
+		{name: "missing name", doc: "rules:\n  - type: max_unscored\n    value: 1\n", wantErr: true},
+		{name: "missing rules", doc: "name: x\n", wantErr: true},
+		{name: "unknown rule type", doc: "name: x\nrules:\n  - type: nope\n    value: 1\n", wantErr: true},
+		{name: "unknown relation", doc: "name: x\nrules:\n  - type: min_score\n    relation: peer\n    value: 1\n", wantErr: true},
+		{name: "relation on unsupported rule", doc: "name: x\nrules:\n  - type: max_unscored\n    relation: direct\n    value: 1\n", wantErr: true},
+		{name: "negative value", doc: "name: x\nrules:\n  - type: max_unscored\n    value: -1\n", wantErr: true},
+		{name: "unknown field", doc: "name: x\nowner: me\nrules:\n  - type: max_unscored\n    value: 1\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse([]byte(tt.doc))
+			if tt.wantErr {
+				require.ErrorIs(t, err, depsmanager.ErrInvalidPolicy)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "baseline", p.Name)
+			require.Len(t, p.Rules, 3)
+			require.Equal(t, tt.doc, p.Document)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	fresh := now.Add(-10 * day).Unix()
+	stale := now.Add(-200 * day).Unix()
+
+	deps := []depsmanager.Dependency{
+		{Name: "direct-low", Score: 3, UpdatedAt: fresh, Relation: depsmanager.RelationDirect},
+		{Name: "direct-ok", Score: 7, UpdatedAt: fresh, Relation: depsmanager.RelationDirect},
+		{Name: "indirect-low", Score: 2, UpdatedAt: stale, Relation: depsmanager.RelationIndirect},
+		{Name: "manual-old", Score: 8, UpdatedAt: stale, Relation: depsmanager.RelationDirect, Source: depsmanager.SourceManual},
+		{Name: "unscored-1", Relation: depsmanager.RelationDirect},
+		{Name: "unscored-2", Relation: depsmanager.RelationIndirect},
+		{Name: "suppressed-low", Score: 1, UpdatedAt: fresh, Relation: depsmanager.RelationDirect, SuppressedBy: new(int64)},
+		{Name: "suppressed-unscored", Relation: depsmanager.RelationDirect, SuppressedBy: new(int64)},
+		// manual dependencies may have no relation, rules of either relation check them
+		{Name: "unknown-low", Score: 1, UpdatedAt: fresh, Source: depsmanager.SourceManual},
+	}
+
+	tests := []struct {
+		name     string
+		rules    []depsmanager.PolicyRule
+		wantDeps []string
+	}{
+		{
+			name:     "min score on direct dependencies",
+			rules:    []depsmanager.PolicyRule{{Type: depsmanager.PolicyRuleMinScore, Relation: "direct", Value: 4}},
+			wantDeps: []string{"direct-low", "unknown-low"},
+		},
+		{
+			name:     "min score on indirect dependencies",
+			rules:    []depsmanager.PolicyRule{{Type: depsmanager.PolicyRuleMinScore, Relation: "indirect", Value: 4}},
+			wantDeps: []string{"indirect-low", "unknown-low"},
+		},
+		{
+			name:     "min score on all dependencies",
+			rules:    []depsmanager.PolicyRule{{Type: depsmanager.PolicyRuleMinScore, Value: 4}},
+			wantDeps: []string{"direct-low", "indirect-low", "unknown-low"},
+		},
+		{
+			name:     "scorecard age",
+			rules:    []depsmanager.PolicyRule{{Type: depsmanager.PolicyRuleMaxScorecardAgeDays, Value: 180}},
+			wantDeps: []string{"indirect-low"},
+		},
+		{
+			name:     "unscored above limit",
+			rules:    []depsmanager.PolicyRule{{Type: depsmanager.PolicyRuleMaxUnscored, Value: 1}},
+			wantDeps: []string{""},
+		},
+		{
+			name:     "unscored within limit",
+			rules:    []depsmanager.PolicyRule{{Type: depsmanager.PolicyRuleMaxUnscored, Value: 2}},
+			wantDeps: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Evaluate([]depsmanager.Policy{{Name: "p", Rules: tt.rules}}, deps, now)
+
+			var gotDeps []string
+			for _, v := range got {
+				require.Equal(t, "p", v.Policy)
+				require.Equal(t, tt.rules[0].Type, v.Rule)
+				require.NotEmpty(t, v.Message)
+				gotDeps = append(gotDeps, v.DependencyName)
+			}
+			require.Equal(t, tt.wantDeps, gotDeps)
+
+			if len(tt.wantDeps) == 0 {
+				require.Equal(t, depsmanager.VerdictPass, Verdict(got))
+			} else {
+				require.Equal(t, depsmanager.VerdictFail, Verdict(got))
+			}
+		})
+	}
+}
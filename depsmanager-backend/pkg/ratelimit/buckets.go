@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets refilled to their burst are dropped, a new bucket starts full anyway.
+const sweepInterval = time.Minute
+
+// Buckets keeps a token bucket per key, e.g. per api key and route class.
+type Buckets struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens, rate, burst float64
+	last                time.Time
+}
+
+// refill adds tokens for the time since the last call, capped at burst.
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// Result of Buckets.Allow, Limit, Remaining and Reset are the values of RateLimit-* headers.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token of a rejected call.
+	RetryAfter time.Duration
+}
+
+func NewBuckets(now func() time.Time) *Buckets {
+	return &Buckets{now: now, buckets: make(map[string]*bucket), lastSweep: now()}
+}
+
+// Limit is the quota of the bucket of Key, refilled at Rate per second up to Burst.
+type Limit struct {
+	Key   string
+	Rate  float64
+	Burst int
+}
+
+// Allow takes a token from the bucket of key refilled at rate per second up to burst. A changed quota applies
+// to the existing bucket, tokens above the new burst are dropped.
+func (b *Buckets) Allow(key string, rate float64, burst int) Result {
+	return b.AllowAll(Limit{Key: key, Rate: rate, Burst: burst})
+}
+
+// AllowAll takes a token from the bucket of every limit when all of them have one, and none otherwise.
+// The result is of the rejecting bucket with the longest RetryAfter, or of the one with the fewest remaining
+// tokens when allowed.
+func (b *Buckets) AllowAll(limits ...Limit) Result {
+	now := b.now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sweep(now)
+
+	bks := make([]*bucket, len(limits))
+	allowed := true
+	for i, l := range limits {
+		bk, ok := b.buckets[l.Key]
+		if !ok {
+			bk = &bucket{tokens: float64(l.Burst), last: now}
+			b.buckets[l.Key] = bk
+		}
+		bk.rate, bk.burst = l.Rate, float64(l.Burst)
+		bk.refill(now)
+		bks[i] = bk
+		allowed = allowed && bk.tokens >= 1
+	}
+
+	var res Result
+	for i, bk := range bks {
+		r := Result{Allowed: allowed, Limit: limits[i].Burst}
+		if allowed {
+			bk.tokens--
+		} else if bk.tokens < 1 {
+			r.RetryAfter = seconds((1 - bk.tokens) / bk.rate)
+		}
+		r.Remaining = int(bk.tokens)
+		r.Reset = seconds((bk.burst - bk.tokens) / bk.rate)
+		if i == 0 || r.RetryAfter > res.RetryAfter || (r.RetryAfter == res.RetryAfter && r.Remaining < res.Remaining) {
+			res = r
+		}
+	}
+	return res
+}
+
+func (b *Buckets) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < sweepInterval {
+		return
+	}
+	b.lastSweep = now
+	for key, bk := range b.buckets {
+		bk.refill(now)
+		if bk.tokens >= bk.burst {
+			delete(b.buckets, key)
+		}
+	}
+}
+
+// Len returns the number of kept buckets.
+func (b *Buckets) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.buckets)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuckets_Allow(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBuckets(func() time.Time { return now })
+
+	for i := range 3 {
+		res := b.Allow("ci", 0.5, 3)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 3, res.Limit)
+		assert.Equal(t, 2-i, res.Remaining)
+		assert.Equal(t, time.Duration(i+1)*2*time.Second, res.Reset)
+	}
+
+	res := b.Allow("ci", 0.5, 3)
+	assert.False(t, res.Allowed)
+	assert.Zero(t, res.Remaining)
+	assert.Equal(t, 2*time.Second, res.RetryAfter)
+	assert.Equal(t, 6*time.Second, res.Reset)
+
+	// other keys have their own bucket
+	assert.True(t, b.Allow("web", 0.5, 3).Allowed)
+
+	now = now.Add(time.Second)
+	res = b.Allow("ci", 0.5, 3)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+
+	now = now.Add(time.Second)
+	assert.True(t, b.Allow("ci", 0.5, 3).Allowed)
+}
+
+func TestBuckets_AllowAll(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBuckets(func() time.Time { return now })
+	tenant := Limit{Key: "tenant", Rate: 1, Burst: 2}
+
+	// the result is of the bucket with the fewest remaining tokens
+	res := b.AllowAll(Limit{Key: "ci", Rate: 1, Burst: 10}, tenant)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 2, res.Limit)
+	assert.Equal(t, 1, res.Remaining)
+	assert.True(t, b.AllowAll(Limit{Key: "web", Rate: 1, Burst: 10}, tenant).Allowed)
+
+	// the spent tenant rejects, the key keeps its token
+	res = b.AllowAll(Limit{Key: "ci", Rate: 1, Burst: 10}, tenant)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 2, res.Limit)
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.Equal(t, 8, b.Allow("ci", 1, 10).Remaining)
+}
+
+func TestBuckets_Sweep(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBuckets(func() time.Time { return now })
+	b.Allow("ci", 0.01, 10)
+	b.Allow("web", 1, 10)
+	assert.Equal(t, 2, b.Len())
+
+	// web refilled within the sweep interval, ci still misses its token
+	now = now.Add(sweepInterval)
+	b.Allow("cli", 1, 10)
+	assert.Equal(t, 2, b.Len())
+}
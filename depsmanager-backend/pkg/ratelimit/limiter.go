@@ -0,0 +1,187 @@
+// Package ratelimit limits calls by token buckets and a maximum of calls in flight.
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Priority of a call waiting in Limiter, interactive calls are let through before any background one.
+type Priority int
+
+const (
+	Interactive Priority = iota
+	Background
+)
+
+func (p Priority) String() string {
+	if p == Background {
+		return "background"
+	}
+	return "interactive"
+}
+
+// PriorityHeader marks API requests of bulk jobs, "X-Priority: background" queues their deps.dev calls
+// behind interactive ones.
+const PriorityHeader = "X-Priority"
+
+type ctxKey struct{}
+
+// WithPriority returns ctx whose calls wait with p, contexts without priority are Interactive.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+func PriorityFromContext(ctx context.Context) Priority {
+	p, _ := ctx.Value(ctxKey{}).(Priority)
+	return p
+}
+
+// PriorityFromRequest returns Background for requests with PriorityHeader "background", Interactive otherwise.
+func PriorityFromRequest(r *http.Request) Priority {
+	if strings.EqualFold(r.Header.Get(PriorityHeader), Background.String()) {
+		return Background
+	}
+	return Interactive
+}
+
+// Limiter lets calls through at most at rate per second with bursts of burst calls, and at most maxInFlight
+// at once. Waiting calls are served by priority, then in arrival order. Zero rate or maxInFlight is unlimited.
+type Limiter struct {
+	rate        float64
+	burst       float64
+	maxInFlight int
+
+	mu       sync.Mutex
+	tokens   float64
+	last     time.Time
+	inFlight int
+	waiting  [2]*list.List // by Priority
+	timer    *time.Timer
+}
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+func NewLimiter(rate float64, burst, maxInFlight int) *Limiter {
+	burst = max(burst, 1)
+	return &Limiter{
+		rate:        rate,
+		burst:       float64(burst),
+		maxInFlight: maxInFlight,
+		tokens:      float64(burst),
+		last:        time.Now(),
+		waiting:     [2]*list.List{list.New(), list.New()},
+	}
+}
+
+// Acquire waits until the call may start or ctx is done. release must be called when the call finishes.
+// waited is the time spent in the queue.
+func (l *Limiter) Acquire(ctx context.Context, p Priority) (release func(), waited time.Duration, err error) {
+	start := time.Now()
+	l.mu.Lock()
+	if l.queued(p) == 0 && l.take() {
+		l.mu.Unlock()
+		return l.release, 0, nil
+	}
+
+	w := &waiter{ready: make(chan struct{})}
+	elem := l.waiting[p].PushBack(w)
+	l.dispatch()
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return l.release, time.Since(start), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if w.granted {
+			// granted meanwhile, hand the slot over
+			l.inFlight--
+			l.dispatch()
+		} else {
+			l.waiting[p].Remove(elem)
+		}
+		return nil, time.Since(start), ctx.Err()
+	}
+}
+
+// Waiting returns the number of calls queued with p.
+func (l *Limiter) Waiting(p Priority) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.waiting[p].Len()
+}
+
+// queued returns the number of calls waiting ahead of a new call with p.
+func (l *Limiter) queued(p Priority) int {
+	n := l.waiting[Interactive].Len()
+	if p == Background {
+		n += l.waiting[Background].Len()
+	}
+	return n
+}
+
+func (l *Limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.dispatch()
+}
+
+// take takes a token and a slot when both are available.
+func (l *Limiter) take() bool {
+	if l.maxInFlight > 0 && l.inFlight >= l.maxInFlight {
+		return false
+	}
+	if l.rate > 0 {
+		now := time.Now()
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+		if l.tokens < 1 {
+			return false
+		}
+		l.tokens--
+	}
+	l.inFlight++
+	return true
+}
+
+// dispatch lets waiting calls through while tokens and slots last. Without a token it waits for the next one,
+// without a slot for a release.
+func (l *Limiter) dispatch() {
+	for {
+		var queue *list.List
+		for _, q := range l.waiting {
+			if q.Len() > 0 {
+				queue = q
+				break
+			}
+		}
+		if queue == nil || !l.take() {
+			break
+		}
+		w := queue.Remove(queue.Front()).(*waiter)
+		w.granted = true
+		close(w.ready)
+	}
+
+	starved := l.waiting[Interactive].Len()+l.waiting[Background].Len() > 0 &&
+		l.rate > 0 && l.tokens < 1 && (l.maxInFlight == 0 || l.inFlight < l.maxInFlight)
+	if starved && l.timer == nil {
+		next := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.timer = time.AfterFunc(next, func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.timer = nil
+			l.dispatch()
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Rate(t *testing.T) {
+	l := NewLimiter(50, 2, 0)
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 7 {
+		release, _, err := l.Acquire(ctx, Interactive)
+		require.NoError(t, err)
+		release()
+	}
+	// burst of 2, then 5 calls at 50 per second
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestLimiter_MaxInFlight(t *testing.T) {
+	l := NewLimiter(0, 0, 1)
+	ctx := context.Background()
+
+	release, _, err := l.Acquire(ctx, Interactive)
+	require.NoError(t, err)
+
+	acquired := make(chan time.Duration)
+	go func() {
+		release, waited, err := l.Acquire(ctx, Interactive)
+		assert.NoError(t, err)
+		release()
+		acquired <- waited
+	}()
+	require.Eventually(t, func() bool { return l.Waiting(Interactive) == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	release()
+	assert.GreaterOrEqual(t, <-acquired, 20*time.Millisecond)
+}
+
+func TestLimiter_Priority(t *testing.T) {
+	l := NewLimiter(0, 0, 1)
+	ctx := context.Background()
+	release, _, err := l.Acquire(ctx, Interactive)
+	require.NoError(t, err)
+
+	order := make(chan Priority, 3)
+	wait := func(p Priority) {
+		release, _, err := l.Acquire(ctx, p)
+		assert.NoError(t, err)
+		order <- p
+		release()
+	}
+	go wait(Background)
+	require.Eventually(t, func() bool { return l.Waiting(Background) == 1 }, time.Second, time.Millisecond)
+	go wait(Background)
+	require.Eventually(t, func() bool { return l.Waiting(Background) == 2 }, time.Second, time.Millisecond)
+	go wait(Interactive)
+	require.Eventually(t, func() bool { return l.Waiting(Interactive) == 1 }, time.Second, time.Millisecond)
+
+	release()
+	assert.Equal(t, []Priority{Interactive, Background, Background}, []Priority{<-order, <-order, <-order})
+}
+
+func TestLimiter_Cancel(t *testing.T) {
+	l := NewLimiter(0, 0, 1)
+	release, _, err := l.Acquire(context.Background(), Interactive)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = l.Acquire(ctx, Background)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, l.Waiting(Background))
+
+	// the slot is free again after release
+	release()
+	release, _, err = l.Acquire(context.Background(), Background)
+	require.NoError(t, err)
+	release()
+}
+
+func TestPriorityFromRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPut, "/", nil)
+	assert.Equal(t, Interactive, PriorityFromRequest(req))
+
+	req.Header.Set(PriorityHeader, "Background")
+	assert.Equal(t, Background, PriorityFromRequest(req))
+}
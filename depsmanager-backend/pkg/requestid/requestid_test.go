@@ -0,0 +1,43 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		reuse    bool
+	}{
+		{name: "reuses valid id", incoming: "abc-123", reuse: true},
+		{name: "generates when missing", incoming: ""},
+		{name: "generates when invalid", incoming: "has space"},
+		{name: "generates when too long", incoming: strings.Repeat("a", maxLength+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = FromContext(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(Header, tt.incoming)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			assert.NotEmpty(t, got)
+			assert.Equal(t, got, rr.Header().Get(Header))
+			if tt.reuse {
+				assert.Equal(t, tt.incoming, got)
+			} else {
+				assert.Len(t, got, 32)
+			}
+		})
+	}
+}
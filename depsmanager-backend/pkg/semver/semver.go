@@ -0,0 +1,349 @@
+// Package semver parses npm versions and version ranges, e.g. ">=1.2.0 <2", "^4.17.0 || ~3.10.1", "1.x".
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Version struct {
+	Major, Minor, Patch int
+	// Prerelease without the leading dash, build metadata is dropped.
+	Prerelease string
+}
+
+// Parse parses full version, a leading "v" or "=" is accepted.
+func Parse(s string) (Version, error) {
+	p, err := parsePartial(strings.TrimSpace(s))
+	if err != nil {
+		return Version{}, err
+	}
+	if p.parts < 3 {
+		return Version{}, fmt.Errorf("version %q: major, minor and patch are required", s)
+	}
+	return p.Version, nil
+}
+
+// Compare returns -1, 0 or 1 when v is lower, equal or greater than o.
+// Version with prerelease is lower than the same version without it.
+func (v Version) Compare(o Version) int {
+	for _, d := range [][2]int{{v.Major, o.Major}, {v.Minor, o.Minor}, {v.Patch, o.Patch}} {
+		if d[0] != d[1] {
+			return cmpInt(d[0], d[1])
+		}
+	}
+	switch {
+	case v.Prerelease == o.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case o.Prerelease == "":
+		return -1
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Range is a set of alternatives, a version matches when it satisfies every comparator of any alternative.
+type Range struct {
+	alternatives [][]comparator
+}
+
+type comparator struct {
+	op string // one of <, <=, >, >=, =
+	v  Version
+}
+
+// ParseRange parses npm range. Empty range and "*" match every version.
+// Prerelease versions match only comparators with a prerelease of the same major.minor.patch, like in npm.
+func ParseRange(s string) (Range, error) {
+	var r Range
+	for _, alt := range strings.Split(s, "||") {
+		comparators, err := parseAlternative(strings.TrimSpace(alt))
+		if err != nil {
+			return Range{}, fmt.Errorf("range %q: %w", s, err)
+		}
+		r.alternatives = append(r.alternatives, comparators)
+	}
+	return r, nil
+}
+
+// Contains reports whether v satisfies r.
+func (r Range) Contains(v Version) bool {
+	for _, alt := range r.alternatives {
+		if matchesAll(alt, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAll(comparators []comparator, v Version) bool {
+	prereleaseAllowed := v.Prerelease == ""
+	for _, c := range comparators {
+		if !c.matches(v) {
+			return false
+		}
+		if c.v.Prerelease != "" && c.v.Major == v.Major && c.v.Minor == v.Minor && c.v.Patch == v.Patch {
+			prereleaseAllowed = true
+		}
+	}
+	return prereleaseAllowed
+}
+
+func (c comparator) matches(v Version) bool {
+	cmp := v.Compare(c.v)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+func parseAlternative(s string) ([]comparator, error) {
+	if s == "" || s == "*" {
+		return nil, nil
+	}
+
+	// hyphen range "1.2.3 - 2.3.4"
+	if fields := strings.Fields(s); len(fields) == 3 && fields[1] == "-" {
+		from, err := parsePartial(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		to, err := parsePartial(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		return append(from.lowerBound(), to.upperBoundInclusive()...), nil
+	}
+
+	var comparators []comparator
+	for _, token := range strings.Fields(joinOperators(s)) {
+		op, rest := splitOperator(token)
+		p, err := parsePartial(rest)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "^":
+			comparators = append(comparators, p.caret()...)
+		case "~":
+			comparators = append(comparators, p.tilde()...)
+		case "", "=":
+			comparators = append(comparators, p.exact()...)
+		case ">=":
+			comparators = append(comparators, p.lowerBound()...)
+		case ">":
+			comparators = append(comparators, p.greater()...)
+		case "<":
+			comparators = append(comparators, p.less()...)
+		case "<=":
+			comparators = append(comparators, p.upperBoundInclusive()...)
+		}
+	}
+	return comparators, nil
+}
+
+// joinOperators removes spaces between operator and version, e.g. ">= 1.2.3".
+func joinOperators(s string) string {
+	fields := strings.Fields(s)
+	var out []string
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if op, rest := splitOperator(f); op != "" && rest == "" && i+1 < len(fields) {
+			f += fields[i+1]
+			i++
+		}
+		out = append(out, f)
+	}
+	return strings.Join(out, " ")
+}
+
+func splitOperator(token string) (string, string) {
+	for _, op := range []string{">=", "<=", "~>", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(token, op) {
+			if op == "~>" {
+				op = "~"
+			}
+			return op, strings.TrimPrefix(strings.TrimPrefix(token, "~>"), op)
+		}
+	}
+	return "", token
+}
+
+// partial is a version where minor and patch may be missing or wildcards, parts counts the given ones.
+type partial struct {
+	Version
+	parts int
+}
+
+func parsePartial(s string) (partial, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "="), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	var p partial
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		p.Prerelease = s[i+1:]
+		s = s[:i]
+		if p.Prerelease == "" {
+			return partial{}, fmt.Errorf("version %q: empty prerelease", s)
+		}
+	}
+	if s == "" {
+		return partial{}, fmt.Errorf("empty version")
+	}
+
+	fields := strings.Split(s, ".")
+	if len(fields) > 3 {
+		return partial{}, fmt.Errorf("version %q: too many parts", s)
+	}
+	nums := []*int{&p.Major, &p.Minor, &p.Patch}
+	for i, f := range fields {
+		if f == "x" || f == "X" || f == "*" {
+			break
+		}
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return partial{}, fmt.Errorf("version %q: invalid number %q", s, f)
+		}
+		*nums[i] = n
+		p.parts++
+	}
+	if p.parts < 3 && p.Prerelease != "" {
+		return partial{}, fmt.Errorf("version %q: prerelease requires full version", s)
+	}
+	return p, nil
+}
+
+// next returns the first version above every version matched by p, e.g. 2.0.0 for 1.x.
+func (p partial) next() Version {
+	switch p.parts {
+	case 1:
+		return Version{Major: p.Major + 1}
+	case 2:
+		return Version{Major: p.Major, Minor: p.Minor + 1}
+	default:
+		return Version{Major: p.Major, Minor: p.Minor, Patch: p.Patch + 1}
+	}
+}
+
+func (p partial) lowerBound() []comparator {
+	if p.parts == 0 {
+		return nil
+	}
+	return []comparator{{op: ">=", v: p.Version}}
+}
+
+func (p partial) upperBoundInclusive() []comparator {
+	switch p.parts {
+	case 0:
+		return nil
+	case 3:
+		return []comparator{{op: "<=", v: p.Version}}
+	}
+	return []comparator{{op: "<", v: p.next()}}
+}
+
+func (p partial) exact() []comparator {
+	if p.parts == 3 {
+		return []comparator{{op: "=", v: p.Version}}
+	}
+	return append(p.lowerBound(), p.upperBoundInclusive()...)
+}
+
+func (p partial) greater() []comparator {
+	switch p.parts {
+	case 0:
+		// >* matches nothing
+		return []comparator{{op: "<", v: Version{}}}
+	case 3:
+		return []comparator{{op: ">", v: p.Version}}
+	}
+	return []comparator{{op: ">=", v: p.next()}}
+}
+
+func (p partial) less() []comparator {
+	if p.parts == 0 {
+		return []comparator{{op: "<", v: Version{}}}
+	}
+	return []comparator{{op: "<", v: p.Version}}
+}
+
+// caret allows changes that do not modify the left-most non-zero part.
+func (p partial) caret() []comparator {
+	if p.parts == 0 {
+		return nil
+	}
+	var upper Version
+	switch {
+	case p.Major > 0 || p.parts == 1:
+		upper = Version{Major: p.Major + 1}
+	case p.Minor > 0 || p.parts == 2:
+		upper = Version{Minor: p.Minor + 1}
+	default:
+		upper = Version{Patch: p.Patch + 1}
+	}
+	return []comparator{{op: ">=", v: p.Version}, {op: "<", v: upper}}
+}
+
+// tilde allows patch changes, or minor changes when only major is given.
+func (p partial) tilde() []comparator {
+	if p.parts == 0 {
+		return nil
+	}
+	upper := Version{Major: p.Major, Minor: p.Minor + 1}
+	if p.parts == 1 {
+		upper = Version{Major: p.Major + 1}
+	}
+	return []comparator{{op: ">=", v: p.Version}, {op: "<", v: upper}}
+}
+
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return cmpInt(an, bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return cmpInt(len(as), len(bs))
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package semver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	v, err := Parse("v1.2.3-beta.1+build.5")
+	require.NoError(t, err)
+	assert.Equal(t, Version{Major: 1, Minor: 2, Patch: 3, Prerelease: "beta.1"}, v)
+	assert.Equal(t, "1.2.3-beta.1", v.String())
+
+	for _, s := range []string{"", "1.2", "1.2.x", "1.2.3.4", "a.b.c", "1.2.3-"} {
+		_, err := Parse(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0", "1.0.1", "1.1.0", "2.0.0"}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := Parse(ordered[i-1])
+		b, _ := Parse(ordered[i])
+		assert.Equal(t, -1, a.Compare(b), "%s < %s", a, b)
+		assert.Equal(t, 1, b.Compare(a), "%s > %s", b, a)
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	tests := []struct {
+		rng string
+		in  []string
+		out []string
+	}{
+		{rng: "", in: []string{"0.0.1", "9.9.9"}},
+		{rng: "*", in: []string{"1.0.0"}, out: []string{"1.0.0-rc.1"}},
+		{rng: "1.2.3", in: []string{"1.2.3"}, out: []string{"1.2.4"}},
+		{rng: "1.x", in: []string{"1.0.0", "1.9.9"}, out: []string{"2.0.0", "0.9.0"}},
+		{rng: "1.2", in: []string{"1.2.0", "1.2.9"}, out: []string{"1.3.0"}},
+		{rng: ">=1.2.0 <2", in: []string{"1.2.0", "1.99.0"}, out: []string{"1.1.9", "2.0.0"}},
+		{rng: ">= 1.2.0 < 1.3.0", in: []string{"1.2.5"}, out: []string{"1.3.0"}},
+		{rng: ">1.2", in: []string{"1.3.0"}, out: []string{"1.2.9"}},
+		{rng: "<=1.2", in: []string{"1.2.9"}, out: []string{"1.3.0"}},
+		{rng: "^4.17.0", in: []string{"4.17.0", "4.99.0"}, out: []string{"4.16.9", "5.0.0"}},
+		{rng: "^0.2.3", in: []string{"0.2.9"}, out: []string{"0.3.0"}},
+		{rng: "^0.0.3", in: []string{"0.0.3"}, out: []string{"0.0.4"}},
+		{rng: "~1.2.3", in: []string{"1.2.9"}, out: []string{"1.3.0"}},
+		{rng: "~1", in: []string{"1.9.0"}, out: []string{"2.0.0"}},
+		{rng: "1.2.3 - 2.3", in: []string{"1.2.3", "2.3.9"}, out: []string{"2.4.0"}},
+		{rng: "<1.0.0 || >=3", in: []string{"0.5.0", "3.1.0"}, out: []string{"2.0.0"}},
+		{rng: ">=1.0.0-rc.1 <1.0.1", in: []string{"1.0.0-rc.2", "1.0.0"}, out: []string{"1.0.0-beta", "1.0.1-rc.1"}},
+	}
+	for _, tt := range tests {
+		r, err := ParseRange(tt.rng)
+		require.NoError(t, err, tt.rng)
+		for _, s := range tt.in {
+			v, err := Parse(s)
+			require.NoError(t, err)
+			assert.True(t, r.Contains(v), "%s should contain %s", tt.rng, s)
+		}
+		for _, s := range tt.out {
+			v, err := Parse(s)
+			require.NoError(t, err)
+			assert.False(t, r.Contains(v), "%s should not contain %s", tt.rng, s)
+		}
+	}
+}
+
+func TestParseRange_Invalid(t *testing.T) {
+	for _, s := range []string{">=a", "1.2.3.4", "^1.x-beta", "1 - "} {
+		_, err := ParseRange(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,87 @@
+package summary
+
+import (
+	"depsmanager"
+	"sort"
+)
+
+const (
+	maxScore    = 10
+	bucketWidth = 1
+)
+
+// Summarize computes risk statistics for deps.
+// A dependency without scorecard date (UpdatedAt == 0) is treated as unscored,
+// it is counted in UnscoredCount but excluded from score statistics and histogram.
+// Suppressed dependencies are counted in SuppressedCount only. Manual dependencies have no scorecard,
+// their UpdatedAt is when they were edited, so they do not count for OldestScorecardAt.
+func Summarize(deps []depsmanager.Dependency) depsmanager.RiskSummary {
+	summary := depsmanager.RiskSummary{
+		DependencyCount: len(deps),
+		Histogram:       newHistogram(),
+	}
+
+	scores := make([]float64, 0, len(deps))
+	for _, d := range deps {
+		if d.SuppressedBy != nil {
+			summary.SuppressedCount++
+			continue
+		}
+		if d.UpdatedAt == 0 {
+			summary.UnscoredCount++
+			continue
+		}
+		scores = append(scores, d.Score)
+		summary.Histogram[bucketIndex(d.Score)].Count++
+
+		if d.Source == depsmanager.SourceManual {
+			continue
+		}
+		if summary.OldestScorecardAt == 0 || d.UpdatedAt < summary.OldestScorecardAt {
+			summary.OldestScorecardAt = d.UpdatedAt
+		}
+	}
+
+	if len(scores) == 0 {
+		return summary
+	}
+
+	sort.Float64s(scores)
+	var sum float64
+	for _, s := range scores {
+		sum += s
+	}
+	summary.MeanScore = sum / float64(len(scores))
+	summary.MinScore = scores[0]
+	summary.MedianScore = median(scores)
+
+	return summary
+}
+
+func newHistogram() []depsmanager.ScoreBucket {
+	buckets := make([]depsmanager.ScoreBucket, 0, maxScore/bucketWidth)
+	for from := 0; from < maxScore; from += bucketWidth {
+		buckets = append(buckets, depsmanager.ScoreBucket{From: float64(from), To: float64(from + bucketWidth)})
+	}
+	return buckets
+}
+
+func bucketIndex(score float64) int {
+	idx := int(score / bucketWidth)
+	if idx < 0 {
+		return 0
+	}
+	if last := maxScore/bucketWidth - 1; idx > last {
+		return last
+	}
+	return idx
+}
+
+// median expects sorted, non-empty scores.
+func median(scores []float64) float64 {
+	mid := len(scores) / 2
+	if len(scores)%2 == 1 {
+		return scores[mid]
+	}
+	return (scores[mid-1] + scores[mid]) / 2
+}
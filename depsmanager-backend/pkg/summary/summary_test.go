@@ -0,0 +1,118 @@
+package summary
+
+import (
+	"depsmanager"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name           string
+		deps           []depsmanager.Dependency
+		wantCount      int
+		wantMean       float64
+		wantMedian     float64
+		wantMin        float64
+		wantUnscored   int
+		wantOldest     int64
+		wantBuckets    map[int]int
+		wantSuppressed int
+	}{
+		{
+			name:        "empty",
+			deps:        nil,
+			wantBuckets: map[int]int{},
+		},
+		{
+			name: "odd number of scores",
+			deps: []depsmanager.Dependency{
+				{Name: "a", Score: 7.5, UpdatedAt: 30},
+				{Name: "b", Score: 3.2, UpdatedAt: 10},
+				{Name: "c", Score: 5.0, UpdatedAt: 20},
+			},
+			wantCount:   3,
+			wantMean:    (7.5 + 3.2 + 5.0) / 3,
+			wantMedian:  5.0,
+			wantMin:     3.2,
+			wantOldest:  10,
+			wantBuckets: map[int]int{3: 1, 5: 1, 7: 1},
+		},
+		{
+			name: "even number of scores with unscored and perfect score",
+			deps: []depsmanager.Dependency{
+				{Name: "a", Score: 10, UpdatedAt: 50},
+				{Name: "b", Score: 2, UpdatedAt: 40},
+				{Name: "c", Score: 0, UpdatedAt: 0},
+				{Name: "d", Score: 4, UpdatedAt: 60},
+				{Name: "e", Score: 6, UpdatedAt: 70},
+			},
+			wantCount:    5,
+			wantMean:     5.5,
+			wantMedian:   5,
+			wantMin:      2,
+			wantUnscored: 1,
+			wantOldest:   40,
+			wantBuckets:  map[int]int{2: 1, 4: 1, 6: 1, 9: 1},
+		},
+		{
+			name: "manual dependency has no scorecard date",
+			deps: []depsmanager.Dependency{
+				{Name: "a", Score: 6, UpdatedAt: 30},
+				{Name: "manual", Score: 8, UpdatedAt: 5, Source: depsmanager.SourceManual},
+			},
+			wantCount:   2,
+			wantMean:    7,
+			wantMedian:  7,
+			wantMin:     6,
+			wantOldest:  30,
+			wantBuckets: map[int]int{6: 1, 8: 1},
+		},
+		{
+			name: "only unscored",
+			deps: []depsmanager.Dependency{
+				{Name: "a", Score: 0, UpdatedAt: 0},
+				{Name: "b", Score: 0, UpdatedAt: 0},
+			},
+			wantCount:    2,
+			wantUnscored: 2,
+			wantBuckets:  map[int]int{},
+		},
+		{
+			name: "suppressed excluded from statistics",
+			deps: []depsmanager.Dependency{
+				{Name: "a", Score: 8, UpdatedAt: 30},
+				{Name: "b", Score: 1, UpdatedAt: 10, SuppressedBy: new(int64)},
+				{Name: "c", Score: 0, UpdatedAt: 0, SuppressedBy: new(int64)},
+			},
+			wantCount:      3,
+			wantMean:       8,
+			wantMedian:     8,
+			wantMin:        8,
+			wantOldest:     30,
+			wantBuckets:    map[int]int{8: 1},
+			wantSuppressed: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Summarize(tt.deps)
+
+			require.Equal(t, tt.wantCount, got.DependencyCount)
+			require.InDelta(t, tt.wantMean, got.MeanScore, 1e-9)
+			require.InDelta(t, tt.wantMedian, got.MedianScore, 1e-9)
+			require.InDelta(t, tt.wantMin, got.MinScore, 1e-9)
+			require.Equal(t, tt.wantUnscored, got.UnscoredCount)
+			require.Equal(t, tt.wantSuppressed, got.SuppressedCount)
+			require.Equal(t, tt.wantOldest, got.OldestScorecardAt)
+
+			require.Len(t, got.Histogram, 10)
+			for i, b := range got.Histogram {
+				require.Equal(t, float64(i), b.From)
+				require.Equal(t, float64(i+1), b.To)
+				require.Equal(t, tt.wantBuckets[i], b.Count, "bucket %d", i)
+			}
+		})
+	}
+}
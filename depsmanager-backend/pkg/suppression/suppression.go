@@ -0,0 +1,75 @@
+// Package suppression matches suppressions to dependencies of project versions.
+package suppression
+
+import (
+	"depsmanager"
+	"depsmanager/pkg/semver"
+	"time"
+)
+
+// Active returns suppressions which have not expired at now.
+func Active(all []depsmanager.Suppression, now time.Time) []depsmanager.Suppression {
+	var active []depsmanager.Suppression
+	for _, s := range all {
+		if s.ExpiresAt > now.Unix() {
+			active = append(active, s)
+		}
+	}
+	return active
+}
+
+// Matches reports whether s applies to dep, dep.ProjectName and dep.ProjectVersion identify its project.
+// Tenants are compared when both are known, dependencies listed across tenants carry ProjectTenant.
+// Dependency of unknown version does not match a suppression with VersionRange.
+func Matches(s depsmanager.Suppression, dep depsmanager.Dependency) bool {
+	if s.DependencyName != dep.Name {
+		return false
+	}
+	if s.Tenant != "" && dep.ProjectTenant != "" && s.Tenant != dep.ProjectTenant {
+		return false
+	}
+	if s.ProjectName != "" && s.ProjectName != dep.ProjectName {
+		return false
+	}
+	if s.ProjectVersion != "" && s.ProjectVersion != dep.ProjectVersion {
+		return false
+	}
+	if s.VersionRange == "" {
+		return true
+	}
+
+	r, err := semver.ParseRange(s.VersionRange)
+	if err != nil {
+		return false
+	}
+	v, err := semver.Parse(dep.Version)
+	if err != nil {
+		return false
+	}
+	return r.Contains(v)
+}
+
+// Apply sets SuppressedBy of deps matched by one of active suppressions, deps are changed in place.
+func Apply(active []depsmanager.Suppression, deps []depsmanager.Dependency) []depsmanager.Dependency {
+	for i := range deps {
+		for _, s := range active {
+			if Matches(s, deps[i]) {
+				id := s.ID
+				deps[i].SuppressedBy = &id
+				break
+			}
+		}
+	}
+	return deps
+}
+
+// Unsuppressed returns deps without suppressed ones.
+func Unsuppressed(deps []depsmanager.Dependency) []depsmanager.Dependency {
+	out := make([]depsmanager.Dependency, 0, len(deps))
+	for _, d := range deps {
+		if d.SuppressedBy == nil {
+			out = append(out, d)
+		}
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package suppression
+
+import (
+	"depsmanager"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatches(t *testing.T) {
+	dep := depsmanager.Dependency{ProjectTenant: "acme", ProjectName: "react", ProjectVersion: "18.3.1", Name: "lodash", Version: "4.17.20"}
+
+	tests := []struct {
+		name string
+		s    depsmanager.Suppression
+		want bool
+	}{
+		{name: "every project", s: depsmanager.Suppression{DependencyName: "lodash"}, want: true},
+		{name: "other dependency", s: depsmanager.Suppression{DependencyName: "scheduler"}, want: false},
+		{name: "tenant", s: depsmanager.Suppression{Tenant: "acme", DependencyName: "lodash"}, want: true},
+		{name: "other tenant", s: depsmanager.Suppression{Tenant: "globex", DependencyName: "lodash"}, want: false},
+		{name: "project", s: depsmanager.Suppression{DependencyName: "lodash", ProjectName: "react"}, want: true},
+		{name: "other project", s: depsmanager.Suppression{DependencyName: "lodash", ProjectName: "vue"}, want: false},
+		{name: "project version", s: depsmanager.Suppression{DependencyName: "lodash", ProjectName: "react", ProjectVersion: "18.3.1"}, want: true},
+		{name: "other project version", s: depsmanager.Suppression{DependencyName: "lodash", ProjectName: "react", ProjectVersion: "18.2.0"}, want: false},
+		{name: "in range", s: depsmanager.Suppression{DependencyName: "lodash", VersionRange: "<4.17.21"}, want: true},
+		{name: "out of range", s: depsmanager.Suppression{DependencyName: "lodash", VersionRange: "^4.17.21"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Matches(tt.s, dep))
+		})
+	}
+
+	unknown := dep
+	unknown.Version = ""
+	assert.False(t, Matches(depsmanager.Suppression{DependencyName: "lodash", VersionRange: "*"}, unknown))
+}
+
+func TestActiveAndApply(t *testing.T) {
+	now := time.Unix(1000, 0)
+	all := []depsmanager.Suppression{
+		{ID: 1, DependencyName: "lodash", ExpiresAt: 999},
+		{ID: 2, DependencyName: "lodash", ExpiresAt: 2000},
+	}
+	active := Active(all, now)
+	assert.Len(t, active, 1)
+
+	deps := Apply(active, []depsmanager.Dependency{{Name: "lodash"}, {Name: "scheduler"}})
+	if assert.NotNil(t, deps[0].SuppressedBy) {
+		assert.Equal(t, int64(2), *deps[0].SuppressedBy)
+	}
+	assert.Nil(t, deps[1].SuppressedBy)
+	assert.Equal(t, []depsmanager.Dependency{{Name: "scheduler"}}, Unsuppressed(deps))
+}
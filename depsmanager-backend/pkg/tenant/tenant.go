@@ -0,0 +1,38 @@
+// Package tenant carries the tenant of a request in context, storage scopes every query by it.
+package tenant
+
+import (
+	"context"
+	"regexp"
+)
+
+// Header lets admins act in another tenant, All queries every tenant.
+const Header = "X-Tenant"
+
+// Default owns data created before tenants were introduced and requests without tenant.
+const Default = "default"
+
+// All is a read-only scope across every tenant, writes require a single tenant.
+const All = "*"
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+type ctxKey struct{}
+
+func NewContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, name)
+}
+
+// FromContext returns tenant of ctx, Default when there is none.
+func FromContext(ctx context.Context) string {
+	name, _ := ctx.Value(ctxKey{}).(string)
+	if name == "" {
+		return Default
+	}
+	return name
+}
+
+// ValidName reports whether name is lowercase letters, digits and dashes, at most 63 characters.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
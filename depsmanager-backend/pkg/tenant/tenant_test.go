@@ -0,0 +1,30 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, Default, FromContext(context.Background()))
+	assert.Equal(t, "team-a", FromContext(NewContext(context.Background(), "team-a")))
+	assert.Equal(t, All, FromContext(NewContext(context.Background(), All)))
+}
+
+func TestValidName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"default":                true,
+		"team-a":                 true,
+		"42":                     true,
+		"":                       false,
+		"*":                      false,
+		"-team":                  false,
+		"Team":                   false,
+		"team_a":                 false,
+		string(make([]byte, 64)): false,
+	} {
+		assert.Equal(t, want, ValidName(name), name)
+	}
+}
@@ -0,0 +1,217 @@
+// Package trace records spans compatible with OpenTelemetry: W3C trace context is propagated
+// in traceparent headers and finished spans are handed to an Exporter, e.g. OTLPExporter.
+// A nil *Tracer and the spans it returns are no-ops, so instrumented code does not check whether tracing is on.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+type TraceID [16]byte
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+type SpanID [8]byte
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+// SpanContext identifies a span across processes.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// Kind values follow OTLP SpanKind.
+type Kind int
+
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// Attribute is a key with string, bool, int64 or float64 value.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+func String(key, value string) Attribute { return Attribute{Key: key, Value: value} }
+
+func Int(key string, value int) Attribute { return Attribute{Key: key, Value: int64(value)} }
+
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+func Float(key string, value float64) Attribute { return Attribute{Key: key, Value: value} }
+
+// SpanData is a finished span as passed to exporters.
+type SpanData struct {
+	Name          string
+	Kind          Kind
+	SpanContext   SpanContext
+	Parent        SpanID
+	Start, End    time.Time
+	Attributes    []Attribute
+	Error         bool
+	StatusMessage string
+}
+
+// Attribute returns value of attribute key, nil when it is not set.
+func (d SpanData) Attribute(key string) any {
+	for i := len(d.Attributes) - 1; i >= 0; i-- {
+		if d.Attributes[i].Key == key {
+			return d.Attributes[i].Value
+		}
+	}
+	return nil
+}
+
+// Exporter receives sampled spans when they end, ExportSpan must not block.
+type Exporter interface {
+	ExportSpan(span SpanData)
+}
+
+type Tracer struct {
+	exporter Exporter
+	now      func() time.Time
+}
+
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter, now: time.Now}
+}
+
+// Start starts span as a child of the span in ctx or of the remote parent extracted into ctx,
+// without any a new trace is started. The returned context carries the new span.
+func (t *Tracer) Start(ctx context.Context, name string, kind Kind, attrs ...Attribute) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	s := &Span{tracer: t, data: SpanData{Name: name, Kind: kind, Start: t.now(), Attributes: attrs}}
+	if parent := SpanContextFromContext(ctx); parent.IsValid() {
+		s.data.SpanContext = SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled}
+		s.data.Parent = parent.SpanID
+	} else {
+		s.data.SpanContext = SpanContext{TraceID: newTraceID(), Sampled: true}
+	}
+	s.data.SpanContext.SpanID = newSpanID()
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// Span is an operation in progress, its methods are safe for concurrent use and no-ops on nil.
+type Span struct {
+	tracer *Tracer
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.SpanContext
+}
+
+// SetName renames the span, e.g. once the HTTP route is known.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Name = name
+}
+
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+// RecordError marks the span failed with err, nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = true
+	s.data.StatusMessage = err.Error()
+}
+
+// End finishes the span and exports it when sampled, later calls are ignored.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = s.tracer.now()
+	data := s.data
+	data.Attributes = append([]Attribute(nil), s.data.Attributes...)
+	s.mu.Unlock()
+
+	if data.SpanContext.Sampled && s.tracer.exporter != nil {
+		s.tracer.exporter.ExportSpan(data)
+	}
+}
+
+type spanKey struct{}
+
+type remoteKey struct{}
+
+// SpanFromContext returns span started in ctx, nil when there is none.
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// ContextWithRemoteParent makes spans started in ctx children of span of another process.
+func ContextWithRemoteParent(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// SpanContextFromContext returns context of the current span or of the remote parent.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if s := SpanFromContext(ctx); s != nil {
+		return s.SpanContext()
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
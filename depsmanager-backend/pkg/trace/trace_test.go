@@ -0,0 +1,158 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracer_Start(t *testing.T) {
+	exp := NewInMemoryExporter()
+	tr := NewTracer(exp)
+
+	ctx, root := tr.Start(context.Background(), "root", KindServer, String("a", "b"))
+	_, child := tr.Start(ctx, "child", KindClient)
+	child.SetAttributes(Int("n", 3))
+	child.RecordError(errors.New("boom"))
+	child.End()
+	root.SetName("renamed")
+	root.End()
+	root.End()
+
+	spans := exp.Spans()
+	require.Len(t, spans, 2)
+	c, r := spans[0], spans[1]
+	assert.Equal(t, "child", c.Name)
+	assert.Equal(t, r.SpanContext.TraceID, c.SpanContext.TraceID)
+	assert.Equal(t, r.SpanContext.SpanID, c.Parent)
+	assert.Equal(t, int64(3), c.Attribute("n"))
+	assert.True(t, c.Error)
+	assert.Equal(t, "boom", c.StatusMessage)
+	assert.Equal(t, "renamed", r.Name)
+	assert.False(t, r.Parent.IsValid())
+	assert.Equal(t, "b", r.Attribute("a"))
+	assert.Nil(t, r.Attribute("missing"))
+}
+
+func TestTracer_Nil(t *testing.T) {
+	var tr *Tracer
+	ctx, span := tr.Start(context.Background(), "x", KindInternal)
+	span.SetAttributes(Bool("ok", true))
+	span.RecordError(errors.New("boom"))
+	span.End()
+	assert.Nil(t, SpanFromContext(ctx))
+	assert.False(t, SpanContextFromContext(ctx).IsValid())
+}
+
+func TestPropagation(t *testing.T) {
+	h := http.Header{}
+	h.Set(Header, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	sc, ok := Extract(h)
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled)
+
+	exp := NewInMemoryExporter()
+	ctx, span := NewTracer(exp).Start(ContextWithRemoteParent(context.Background(), sc), "server", KindServer)
+	assert.Equal(t, sc.TraceID, span.SpanContext().TraceID)
+
+	out := http.Header{}
+	Inject(ctx, out)
+	back, ok := Extract(out)
+	require.True(t, ok)
+	assert.Equal(t, span.SpanContext(), back)
+
+	span.End()
+	assert.Equal(t, sc.SpanID, exp.Spans()[0].Parent)
+
+	for _, v := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		h.Set(Header, v)
+		_, ok := Extract(h)
+		assert.False(t, ok, v)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(Header)
+	}))
+	defer srv.Close()
+
+	ctx, span := NewTracer(nil).Start(context.Background(), "client", KindClient)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "00-"+span.SpanContext().TraceID.String()+"-"+span.SpanContext().SpanID.String()+"-01", got)
+	assert.Empty(t, req.Header.Get(Header))
+}
+
+func TestOTLPExporter(t *testing.T) {
+	bodies := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		b, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(b, &body))
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	exp := NewOTLPExporter(srv.URL+"/v1/traces", "depsmanager")
+	tr := NewTracer(exp)
+	tr.now = func() time.Time { return time.Unix(1_700_000_000, 0) }
+	ctx, parent := tr.Start(context.Background(), "parent", KindServer)
+	_, span := tr.Start(ctx, "storage.ListProjects", KindInternal, String("project.name", "p"), Int("dependency.count", 2))
+	span.RecordError(errors.New("boom"))
+	span.End()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, exp.Shutdown(ctx))
+
+	body := <-bodies
+	rs := body["resourceSpans"].([]any)[0].(map[string]any)
+	assert.Equal(t, []any{map[string]any{"key": "service.name", "value": map[string]any{"stringValue": "depsmanager"}}},
+		rs["resource"].(map[string]any)["attributes"])
+	got := rs["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"traceId":           parent.SpanContext().TraceID.String(),
+		"spanId":            span.SpanContext().SpanID.String(),
+		"parentSpanId":      parent.SpanContext().SpanID.String(),
+		"name":              "storage.ListProjects",
+		"kind":              float64(KindInternal),
+		"startTimeUnixNano": "1700000000000000000",
+		"endTimeUnixNano":   "1700000000000000000",
+		"attributes": []any{
+			map[string]any{"key": "project.name", "value": map[string]any{"stringValue": "p"}},
+			map[string]any{"key": "dependency.count", "value": map[string]any{"intValue": "2"}},
+		},
+		"status": map[string]any{"code": float64(2), "message": "boom"},
+	}, got)
+
+	// spans after shutdown are dropped
+	exp.ExportSpan(SpanData{Name: "late"})
+	require.NoError(t, exp.Flush(context.Background()))
+	assert.Empty(t, bodies)
+}
@@ -5,34 +5,89 @@ import (
 	"context"
 	"depsmanager"
 	customErr "depsmanager/pkg/errors"
+	"depsmanager/pkg/labels"
+	"depsmanager/pkg/requestid"
+	"depsmanager/pkg/trace"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
 	"net/http"
+	"time"
 )
 
 type Service interface {
 	FetchAndStoreProjectDependencies(ctx context.Context, projectName, version string) error
 	ListDependencies(ctx context.Context, projectName, version string) (depsmanager.ListDependenciesResponse, error)
+	GetProjectSummary(ctx context.Context, projectName, version string) (depsmanager.RiskSummary, error)
+	GetPortfolioSummary(ctx context.Context, selector labels.Selector) (depsmanager.RiskSummary, error)
 	DeleteProject(ctx context.Context, projectName, version string) error
-	ListProjects(ctx context.Context) ([]depsmanager.Project, error)
+	ListProjects(ctx context.Context, selector labels.Selector) ([]depsmanager.Project, error)
 	ListProjectVersions(ctx context.Context, projectName string) ([]string, error)
-	GetProjectsByDependency(ctx context.Context, depName string) ([]depsmanager.Project, error)
-	GetDependenciesByExactScore(ctx context.Context, score float64) ([]string, error)
+	GetProjectsByDependency(ctx context.Context, depName string, selector labels.Selector) ([]depsmanager.Project, error)
+	GetDependenciesByExactScore(ctx context.Context, score float64, selector labels.Selector) ([]string, error)
 
 	AddDependency(ctx context.Context, projectName, version string, dep depsmanager.Dependency) error
 	UpdateDependency(ctx context.Context, projectName, version string, dep depsmanager.Dependency) error
+	UpsertDependency(ctx context.Context, projectName, version string, dep depsmanager.Dependency) (bool, error)
 	DeleteDependency(ctx context.Context, projectName, version, depName string) error
+	ClearScoreOverride(ctx context.Context, projectName, version, depName string) error
+
+	SavePolicy(ctx context.Context, document []byte) (depsmanager.Policy, error)
+	ListPolicies(ctx context.Context, projectName string) ([]depsmanager.Policy, error)
+	DeletePolicy(ctx context.Context, projectName, name string) error
+	EvaluatePolicies(ctx context.Context, projectName, version string) (depsmanager.PolicyEvaluation, error)
+	GetPolicyEvaluation(ctx context.Context, projectName, version string) (depsmanager.PolicyEvaluation, error)
+
+	EvaluateLockfile(ctx context.Context, lock []byte, req depsmanager.GateRequest) (depsmanager.GateResult, error)
+
+	Authenticate(ctx context.Context, key string) (depsmanager.Identity, error)
+	CreateAPIKey(ctx context.Context, name string, role depsmanager.Role, tenantName string) (depsmanager.CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]depsmanager.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+
+	CreateTenant(ctx context.Context, name string) (depsmanager.Tenant, error)
+	ListTenants(ctx context.Context) ([]depsmanager.Tenant, error)
+	GetTenant(ctx context.Context, name string) (depsmanager.Tenant, error)
+
+	ListAudit(ctx context.Context, filter depsmanager.AuditFilter) (depsmanager.AuditPage, error)
+
+	CreateSuppression(ctx context.Context, req depsmanager.CreateSuppressionRequest) (depsmanager.Suppression, error)
+	ListSuppressions(ctx context.Context) ([]depsmanager.Suppression, error)
+	DeleteSuppression(ctx context.Context, id int64) error
+
+	GetProjectMetadata(ctx context.Context, projectName string) (depsmanager.ProjectMetadata, error)
+	SetProjectMetadata(ctx context.Context, projectName string, req depsmanager.ProjectMetadataRequest) (depsmanager.ProjectMetadata, error)
+	DeleteProjectMetadata(ctx context.Context, projectName string) error
 }
 type API struct {
-	service Service
+	service     Service
+	authEnabled bool
+	metrics     *Metrics
+	tracer      *trace.Tracer
+	readiness   []HealthCheck
+	version     string
+	// clientIdentities maps subjects of verified client certificates, see WithClientIdentities.
+	clientIdentities map[string]depsmanager.Identity
+	rateLimit        *rateLimiter
 }
 
-func NewAPI(service Service) API {
-	return API{
+func NewAPI(service Service, opts ...func(a *API)) API {
+	a := API{
 		service: service,
 	}
+	for _, opt := range opts {
+		opt(&a)
+	}
+
+	return a
+}
+
+// WithAuth requires api key on every route, see authenticate and requireRole.
+func WithAuth(enabled bool) func(a *API) {
+	return func(a *API) {
+		a.authEnabled = enabled
+	}
 }
 
 // GetHandler attaches chi Router as a subrouter along a routing path .
@@ -41,24 +96,64 @@ func NewAPI(service Service) API {
 // @BasePath /api
 func (a *API) GetHandler() chi.Router {
 	r := chi.NewRouter()
+	if a.tracer != nil {
+		r.Use(a.traceRequest)
+	}
+	if a.metrics != nil {
+		r.Use(a.metrics.instrument)
+	}
+	r.Use(requestid.Middleware)
+	r.Use(AccessLogMiddleware)
 	r.Use(JSONMiddleware)
 
+	if a.metrics != nil {
+		// outside /api, scraped without api key
+		r.Method(http.MethodGet, "/metrics", a.metrics.Handler())
+	}
+	// probes, outside /api without api key
+	r.Get("/healthz", a.Healthz)
+	r.Get("/readyz", a.Readyz)
+	r.Get("/version", a.Version)
+
+	root := r
 	r.Route("/api", func(r chi.Router) {
-		r.Route("/v1/projects", func(r chi.Router) {
-			r.Post("/", customErr.HandleError(a.FetchProject))
-			r.Delete("/", customErr.HandleError(a.DeleteProject))
-			r.Get("/", customErr.HandleError(a.ListProjects))
-			r.Get("/versions", customErr.HandleError(a.ProjectVersions))
-		})
-		r.Route("/v1/dependencies", func(r chi.Router) {
-			r.Post("/", customErr.HandleError(a.ListDependencies))
-			r.Post("/new", customErr.HandleError(a.AddDependency))
-			r.Patch("/modify", customErr.HandleError(a.ModifyDependency))
-			r.Delete("/delete", customErr.HandleError(a.DeleteDependency))
-
-			r.Post("/byprojectname", customErr.HandleError(a.ProjectByDependency))
-			r.Post("/byscore", customErr.HandleError(a.DependenciesByScore))
+		r.Use(a.limitAddresses)
+		r.Use(a.authenticate)
+		r.Use(a.scopeTenant)
+		r.Use(a.requestPriority)
+		r.Use(a.limitRequests(root))
+		editor := a.requireRole(depsmanager.RoleEditor)
+
+		r.Group(func(r chi.Router) {
+			r.Use(DeprecationMiddleware)
+
+			r.Route("/v1/projects", func(r chi.Router) {
+				r.With(editor).Post("/", customErr.HandleError(a.FetchProject))
+				r.With(editor).Delete("/", customErr.HandleError(a.DeleteProject))
+				r.Get("/", customErr.HandleError(a.ListProjects))
+				r.Get("/versions", customErr.HandleError(a.ProjectVersions))
+			})
+			r.Route("/v1/dependencies", func(r chi.Router) {
+				r.Post("/", customErr.HandleError(a.ListDependencies))
+				r.Post("/summary", customErr.HandleError(a.ProjectSummary))
+				r.Get("/summary", customErr.HandleError(a.PortfolioSummary))
+				r.With(editor).Post("/new", customErr.HandleError(a.AddDependency))
+				r.With(editor).Patch("/modify", customErr.HandleError(a.ModifyDependency))
+				r.With(editor).Delete("/delete", customErr.HandleError(a.DeleteDependency))
+
+				r.Post("/byprojectname", customErr.HandleError(a.ProjectByDependency))
+				r.Post("/byscore", customErr.HandleError(a.DependenciesByScore))
+			})
+			r.Route("/v1/policies", func(r chi.Router) {
+				r.With(editor).Post("/", customErr.HandleError(a.SavePolicy))
+				r.Get("/", customErr.HandleError(a.ListPolicies))
+				r.With(editor).Delete("/", customErr.HandleError(a.DeletePolicy))
+				r.With(editor).Post("/evaluate", customErr.HandleError(a.EvaluatePolicies))
+				r.Post("/evaluation", customErr.HandleError(a.PolicyEvaluation))
+			})
+			r.Post("/v1/gate", customErr.HandleError(a.Gate))
 		})
+		r.Route("/v2", a.v2Routes)
 	})
 	return r
 }
@@ -72,7 +167,7 @@ func (a *API) GetHandler() chi.Router {
 // @param request r.body body depsmanager.ProjectRequest true "request body"
 // @failure 500 "internal error"
 // @failure 404 "not found project"
-// @failure 400 "cannot decode request / body.ProjectName is required / body.Version is required"
+// @failure 400 "cannot decode request / body.project_name is required / body.version is required"
 // @Success 201 "fetched successfully"
 // @Router /v1/projects [post]
 func (a *API) FetchProject(w http.ResponseWriter, r *http.Request) error {
@@ -81,12 +176,8 @@ func (a *API) FetchProject(w http.ResponseWriter, r *http.Request) error {
 		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
 	}
 
-	if req.ProjectName == "" {
-		return customErr.NewBadRequest(fmt.Errorf("req.ProjectName is required"))
-	}
-
-	if req.Version == "" {
-		return customErr.NewBadRequest(fmt.Errorf("req.Version is required"))
+	if err := validateProjectRequest(req); err != nil {
+		return customErr.NewBadRequest(err)
 	}
 
 	if err := a.service.FetchAndStoreProjectDependencies(r.Context(), req.ProjectName, req.Version); err != nil {
@@ -108,7 +199,7 @@ func (a *API) FetchProject(w http.ResponseWriter, r *http.Request) error {
 // @param request r.body body depsmanager.ProjectRequest true "request body"
 // @failure 500 "internal error"
 // @failure 404 "not found project"
-// @failure 400 "cannot decode request / body.ProjectName is required / body.Version is required"
+// @failure 400 "cannot decode request / body.project_name is required / body.version is required"
 // @Success 204 "deleted successfully"
 // @Router /v1/projects [delete]
 func (a *API) DeleteProject(w http.ResponseWriter, r *http.Request) error {
@@ -117,12 +208,8 @@ func (a *API) DeleteProject(w http.ResponseWriter, r *http.Request) error {
 		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
 	}
 
-	if req.ProjectName == "" {
-		return customErr.NewBadRequest(fmt.Errorf("req.ProjectName is required"))
-	}
-
-	if req.Version == "" {
-		return customErr.NewBadRequest(fmt.Errorf("req.Version is required"))
+	if err := validateProjectRequest(req); err != nil {
+		return customErr.NewBadRequest(err)
 	}
 
 	if err := a.service.DeleteProject(r.Context(), req.ProjectName, req.Version); err != nil {
@@ -144,7 +231,7 @@ func (a *API) DeleteProject(w http.ResponseWriter, r *http.Request) error {
 // @param request r.body body depsmanager.ProjectRequest true "request body"
 // @failure 500 "internal error"
 // @failure 404 "not found project"
-// @failure 400 "cannot decode request / body.ProjectName is required / body.Version is required"
+// @failure 400 "cannot decode request / body.project_name is required / body.version is required"
 // @Success 200 {object} depsmanager.ListDependenciesResponse "project dependencies"
 // @Router /v1/dependencies [post]
 func (a *API) ListDependencies(w http.ResponseWriter, r *http.Request) error {
@@ -153,12 +240,8 @@ func (a *API) ListDependencies(w http.ResponseWriter, r *http.Request) error {
 		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
 	}
 
-	if req.ProjectName == "" {
-		return customErr.NewBadRequest(fmt.Errorf("req.ProjectName is required"))
-	}
-
-	if req.Version == "" {
-		return customErr.NewBadRequest(fmt.Errorf("req.Version is required"))
+	if err := validateProjectRequest(req); err != nil {
+		return customErr.NewBadRequest(err)
 	}
 
 	resp, err := a.service.ListDependencies(r.Context(), req.ProjectName, req.Version)
@@ -176,15 +259,88 @@ func (a *API) ListDependencies(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// ProjectSummary
+// @summary ProjectSummary
+// @description Risk summary of dependencies for project name and version.
+// @description Score statistics and histogram are computed over dependencies with a scorecard only.
+// @tags dependencies
+// @accept json
+// @param request r.body body depsmanager.ProjectRequest true "request body"
+// @failure 500 "internal error"
+// @failure 404 "not found project"
+// @failure 400 "cannot decode request / body.project_name is required / body.version is required"
+// @Success 200 {object} depsmanager.RiskSummary "project risk summary"
+// @Router /v1/dependencies/summary [post]
+func (a *API) ProjectSummary(w http.ResponseWriter, r *http.Request) error {
+	var req depsmanager.ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
+	}
+
+	if err := validateProjectRequest(req); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	resp, err := a.service.GetProjectSummary(r.Context(), req.ProjectName, req.Version)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.GetProjectSummary: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// PortfolioSummary
+// @summary PortfolioSummary
+// @description Risk summary of dependencies across all stored projects, or projects matching label selector.
+// @tags dependencies
+// @param selector query string false "label selector, e.g. team=payments,env!=dev"
+// @failure 500 "internal error"
+// @failure 400 "invalid selector"
+// @Success 200 {object} depsmanager.RiskSummary "portfolio risk summary"
+// @Router /v1/dependencies/summary [get]
+func (a *API) PortfolioSummary(w http.ResponseWriter, r *http.Request) error {
+	var v customErr.ValidationError
+	selector := selectorParam(r, &v)
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	resp, err := a.service.GetPortfolioSummary(r.Context(), selector)
+	if err != nil {
+		return customErr.NewInternal(fmt.Errorf("service.GetPortfolioSummary: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
 // ListProjects
 // @summary ListProjects
-// @description List all projects stored in the database. To add a project with dependencies, use FetchProject.
+// @description List all projects stored in the database, or projects matching label selector. To add a project with dependencies, use FetchProject.
 // @tags projects
+// @param selector query string false "label selector, e.g. team=payments,env!=dev"
 // @failure 500 "internal error"
+// @failure 400 "invalid selector"
 // @Success 200 {object} []depsmanager.Project "projects"
 // @Router /v1/projects [get]
 func (a *API) ListProjects(w http.ResponseWriter, r *http.Request) error {
-	projects, err := a.service.ListProjects(r.Context())
+	var v customErr.ValidationError
+	selector := selectorParam(r, &v)
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	projects, err := a.service.ListProjects(r.Context(), selector)
 	if err != nil {
 		return customErr.NewInternal(fmt.Errorf("service.ListProjects: %w", err))
 	}
@@ -207,8 +363,10 @@ func (a *API) ListProjects(w http.ResponseWriter, r *http.Request) error {
 // @Router /v1/projects/versions [get]
 func (a *API) ProjectVersions(w http.ResponseWriter, r *http.Request) error {
 	projectName := r.URL.Query().Get("project_name")
-	if projectName == "" {
-		return customErr.NewBadRequest(fmt.Errorf("project_name is required"))
+	var v customErr.ValidationError
+	v.Required("project_name", projectName)
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
 	}
 
 	versions, err := a.service.ListProjectVersions(r.Context(), projectName)
@@ -242,11 +400,13 @@ func (a *API) ProjectByDependency(w http.ResponseWriter, r *http.Request) error
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
 	}
-	if req.DependencyName == "" {
-		return customErr.NewBadRequest(fmt.Errorf("req.DependencyName is required"))
+	var v customErr.ValidationError
+	v.Required("dependency_name", req.DependencyName)
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
 	}
 
-	versions, err := a.service.GetProjectsByDependency(r.Context(), req.DependencyName)
+	versions, err := a.service.GetProjectsByDependency(r.Context(), req.DependencyName, labels.Selector{})
 	if err != nil {
 		if errors.Is(err, depsmanager.ErrProjectNotFound) {
 			return customErr.NewNotFound(err)
@@ -278,7 +438,7 @@ func (a *API) DependenciesByScore(w http.ResponseWriter, r *http.Request) error
 		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
 	}
 
-	versions, err := a.service.GetDependenciesByExactScore(r.Context(), req.Score)
+	versions, err := a.service.GetDependenciesByExactScore(r.Context(), req.Score, labels.Selector{})
 	if err != nil {
 		if errors.Is(err, depsmanager.ErrProjectNotFound) {
 			return customErr.NewNotFound(err)
@@ -311,8 +471,8 @@ func (a *API) AddDependency(w http.ResponseWriter, r *http.Request) error {
 		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
 	}
 
-	if req.ProjectName == "" || req.Version == "" || req.DependencyName == "" {
-		return customErr.NewBadRequest(fmt.Errorf("req.ProjectName or req.Version or req.DependencyName is required"))
+	if err := validateDependencyRequest(req.ProjectName, req.Version, req.DependencyName); err != nil {
+		return customErr.NewBadRequest(err)
 	}
 
 	if err := a.service.AddDependency(r.Context(), req.ProjectName, req.Version, depsmanager.Dependency{
@@ -334,13 +494,14 @@ func (a *API) AddDependency(w http.ResponseWriter, r *http.Request) error {
 
 // ModifyDependency
 // @summary ModifyDependency
-// @description Modify manually dependency for project.
+// @description Modify manually dependency for project. Score of fetched dependency is pinned by an override
+// @description kept across refreshes until expires_at or until it is cleared, manual dependency is changed in place.
 // @tags dependencies
 // @accept json
 // @param request r.body body depsmanager.DependencyRequest true "request body"
 // @failure 500 "internal error"
-// @failure 404 "not found project"
-// @failure 400 "cannot decode body"
+// @failure 404 "not found project / not found dependency"
+// @failure 400 "cannot decode body / expires_at in the past"
 // @Success 200 "modified"
 // @Router /v1/dependencies/modify [patch]
 func (a *API) ModifyDependency(w http.ResponseWriter, r *http.Request) error {
@@ -349,15 +510,21 @@ func (a *API) ModifyDependency(w http.ResponseWriter, r *http.Request) error {
 		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
 	}
 
-	if req.ProjectName == "" || req.Version == "" || req.DependencyName == "" {
-		return customErr.NewBadRequest(fmt.Errorf("req.ProjectName or req.Version or req.DependencyName is required"))
+	if err := validateDependencyRequest(req.ProjectName, req.Version, req.DependencyName); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+	var v customErr.ValidationError
+	override := scoreOverride(&v, req.ExpiresAt)
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
 	}
 
 	if err := a.service.UpdateDependency(r.Context(), req.ProjectName, req.Version, depsmanager.Dependency{
-		Score: req.Score,
-		Name:  req.DependencyName,
+		Score:    req.Score,
+		Name:     req.DependencyName,
+		Override: override,
 	}); err != nil {
-		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) || errors.Is(err, depsmanager.ErrDependencyNotFound) {
 			return customErr.NewNotFound(err)
 		}
 		return customErr.NewInternal(fmt.Errorf("service.UpdateDependency: %w", err))
@@ -374,7 +541,7 @@ func (a *API) ModifyDependency(w http.ResponseWriter, r *http.Request) error {
 // @accept json
 // @param request r.body body depsmanager.RemoveDependencyRequest true "request body"
 // @failure 500 "internal error"
-// @failure 404 "not found project"
+// @failure 404 "not found project / not found dependency"
 // @failure 400 "cannot decode body"
 // @Success 204 "deleted"
 // @Router /v1/dependencies/delete [delete]
@@ -384,12 +551,12 @@ func (a *API) DeleteDependency(w http.ResponseWriter, r *http.Request) error {
 		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
 	}
 
-	if req.ProjectName == "" || req.Version == "" || req.DependencyName == "" {
-		return customErr.NewBadRequest(fmt.Errorf("req.ProjectName or req.Version or req.DependencyName is required"))
+	if err := validateDependencyRequest(req.ProjectName, req.Version, req.DependencyName); err != nil {
+		return customErr.NewBadRequest(err)
 	}
 
 	if err := a.service.DeleteDependency(r.Context(), req.ProjectName, req.Version, req.DependencyName); err != nil {
-		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) || errors.Is(err, depsmanager.ErrDependencyNotFound) {
 			return customErr.NewNotFound(err)
 		}
 		return customErr.NewInternal(fmt.Errorf("service.DeleteDependency: %w", err))
@@ -399,6 +566,34 @@ func (a *API) DeleteDependency(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+func validateProjectRequest(req depsmanager.ProjectRequest) error {
+	var v customErr.ValidationError
+	v.Required("project_name", req.ProjectName)
+	v.Required("version", req.Version)
+	return v.Err()
+}
+
+// validateDependencyRequest uses JSON field names of depsmanager.DependencyRequest.
+func validateDependencyRequest(projectName, version, dependencyName string) error {
+	var v customErr.ValidationError
+	v.Required("project_name", projectName)
+	v.Required("Version", version)
+	v.Required("dependency_name", dependencyName)
+	return v.Err()
+}
+
+// v1DeprecatedAt is when v2 API became available, v1 responses announce it in Deprecation header.
+var v1DeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+// DeprecationMiddleware marks v1 responses as deprecated (RFC 9745) and links v2 as the successor.
+func DeprecationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", fmt.Sprintf("@%d", v1DeprecatedAt.Unix()))
+		w.Header().Set("Link", `</api/v2>; rel="successor-version"`)
+		next.ServeHTTP(w, r)
+	})
+}
+
 func JSONMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"depsmanager"
+	customErr "depsmanager/pkg/errors"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// ListAudit
+// @summary ListAudit
+// @description List audit entries of tenant, newest first. Pass next_before_id of the response as before_id to get the next page. Requires admin role.
+// @tags audit
+// @param actor query string false "api key name, anonymous when auth is disabled"
+// @param action query string false "e.g. project.fetch, dependency.update, policy.delete"
+// @param project_name query string false "project name"
+// @param version query string false "project version"
+// @param dependency_name query string false "dependency name"
+// @param from query string false "RFC 3339 time, inclusive"
+// @param to query string false "RFC 3339 time, exclusive"
+// @param limit query int false "page size, default 100, at most 1000"
+// @param before_id query int false "return entries older than this id"
+// @failure 500 "internal error"
+// @failure 400 "invalid query"
+// @Success 200 {object} depsmanager.AuditPage "audit entries"
+// @Router /v2/audit [get]
+func (a *API) ListAudit(w http.ResponseWriter, r *http.Request) error {
+	filter, err := parseAuditFilter(r.URL.Query(), true)
+	if err != nil {
+		return err
+	}
+
+	page, err := a.service.ListAudit(r.Context(), filter)
+	if err != nil {
+		return customErr.NewInternal(fmt.Errorf("service.ListAudit: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// ExportAudit
+// @summary ExportAudit
+// @description Download every audit entry matching the filters of ListAudit, limit and before_id are ignored. Requires admin role.
+// @tags audit
+// @produce text/csv,application/x-ndjson
+// @param format query string false "csv (default) or jsonl"
+// @failure 500 "internal error"
+// @failure 400 "invalid query"
+// @Success 200 "audit entries as attachment"
+// @Router /v2/audit/export [get]
+func (a *API) ExportAudit(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "jsonl" {
+		var v customErr.ValidationError
+		v.Add("format", "must be csv or jsonl")
+		return customErr.NewBadRequest(v.Err())
+	}
+
+	filter, err := parseAuditFilter(query, false)
+	if err != nil {
+		return err
+	}
+
+	page, err := a.service.ListAudit(r.Context(), filter)
+	if err != nil {
+		return customErr.NewInternal(fmt.Errorf("service.ListAudit: %w", err))
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="audit.%s"`, format))
+	if format == "jsonl" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for _, e := range page.Entries {
+			if err := enc.Encode(e); err != nil {
+				return customErr.NewInternal(fmt.Errorf("enc.Encode(entry): %w", err))
+			}
+		}
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "created_at", "tenant", "actor", "action", "project_name", "version", "dependency_name", "target", "before", "after", "request_id"})
+	for _, e := range page.Entries {
+		_ = cw.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			time.Unix(e.CreatedAt, 0).UTC().Format(time.RFC3339),
+			e.Tenant, e.Actor, e.Action, e.ProjectName, e.Version, e.DependencyName, e.Target,
+			string(e.Before), string(e.After), e.RequestID,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return customErr.NewInternal(fmt.Errorf("cw.Flush(): %w", err))
+	}
+
+	return nil
+}
+
+// parseAuditFilter reads filters of ListAudit from query, paging parameters only when paged.
+func parseAuditFilter(query url.Values, paged bool) (depsmanager.AuditFilter, error) {
+	filter := depsmanager.AuditFilter{
+		Actor:          query.Get("actor"),
+		Action:         query.Get("action"),
+		ProjectName:    query.Get("project_name"),
+		Version:        query.Get("version"),
+		DependencyName: query.Get("dependency_name"),
+	}
+
+	var v customErr.ValidationError
+	parseTime := func(key string) int64 {
+		raw := query.Get(key)
+		if raw == "" {
+			return 0
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			v.Add(key, "must be RFC 3339 time")
+			return 0
+		}
+		return t.Unix()
+	}
+	filter.From = parseTime("from")
+	filter.To = parseTime("to")
+
+	if paged {
+		filter.Limit = defaultAuditLimit
+		if raw := query.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit < 1 || limit > maxAuditLimit {
+				v.Add("limit", fmt.Sprintf("must be between 1 and %d", maxAuditLimit))
+			}
+			filter.Limit = limit
+		}
+		if raw := query.Get("before_id"); raw != "" {
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || id < 1 {
+				v.Add("before_id", "must be positive integer")
+			}
+			filter.BeforeID = id
+		}
+	}
+
+	if err := v.Err(); err != nil {
+		return depsmanager.AuditFilter{}, customErr.NewBadRequest(err)
+	}
+	return filter, nil
+}
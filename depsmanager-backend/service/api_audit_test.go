@@ -0,0 +1,89 @@
+package service
+
+import (
+	"depsmanager"
+	"depsmanager/pkg/tenant"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func auditEntries() []depsmanager.AuditEntry {
+	return []depsmanager.AuditEntry{{
+		ID:             7,
+		Tenant:         tenant.Default,
+		Actor:          "ops",
+		Action:         depsmanager.AuditDependencyUpdate,
+		ProjectName:    "react",
+		Version:        "18.3.1",
+		DependencyName: "scheduler",
+		Before:         json.RawMessage(`{"score":8}`),
+		After:          json.RawMessage(`{"score":2}`),
+		RequestID:      "req-1",
+		CreatedAt:      time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC).Unix(),
+	}}
+}
+
+func TestListAudit(t *testing.T) {
+	h, svc := setupTenants(t)
+	next := int64(7)
+	svc.On("ListAudit", inTenant(tenant.Default), depsmanager.AuditFilter{
+		Action:      depsmanager.AuditDependencyUpdate,
+		ProjectName: "react",
+		From:        time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC).Unix(),
+		Limit:       1,
+		BeforeID:    10,
+	}).Return(depsmanager.AuditPage{Entries: auditEntries(), NextBeforeID: &next}, nil).Once()
+
+	rr := doTenant(h, http.MethodGet,
+		"/api/v2/audit?action=dependency.update&project_name=react&from=2026-10-01T00:00:00Z&limit=1&before_id=10", "dm_admin", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var page depsmanager.AuditPage
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	require.Len(t, page.Entries, 1)
+	assert.JSONEq(t, `{"score":2}`, string(page.Entries[0].After))
+	assert.Equal(t, &next, page.NextBeforeID)
+
+	rr = doTenant(h, http.MethodGet, "/api/v2/audit?limit=5000&from=yesterday", "dm_admin", "")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"limit"`)
+	assert.Contains(t, rr.Body.String(), `"field":"from"`)
+
+	rr = doTenant(h, http.MethodGet, "/api/v2/audit", "dm_reader", "")
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestExportAudit(t *testing.T) {
+	h, svc := setupTenants(t)
+	svc.On("ListAudit", mock.Anything, depsmanager.AuditFilter{Actor: "ops"}).
+		Return(depsmanager.AuditPage{Entries: auditEntries()}, nil).Twice()
+
+	rr := doTenant(h, http.MethodGet, "/api/v2/audit/export?actor=ops&limit=1", "dm_admin", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="audit.csv"`, rr.Header().Get("Content-Disposition"))
+	records, err := csv.NewReader(rr.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"7", "2026-10-01T12:00:00Z", "default", "ops", "dependency.update", "react", "18.3.1", "scheduler", "",
+		`{"score":8}`, `{"score":2}`, "req-1"}, records[1])
+
+	rr = doTenant(h, http.MethodGet, "/api/v2/audit/export?actor=ops&format=jsonl", "dm_admin", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"action":"dependency.update"`)
+
+	rr = doTenant(h, http.MethodGet, "/api/v2/audit/export?format=xml", "dm_admin", "")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	svc.AssertExpectations(t)
+}
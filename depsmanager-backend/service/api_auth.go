@@ -0,0 +1,193 @@
+package service
+
+import (
+	"depsmanager"
+	"depsmanager/pkg/auth"
+	customErr "depsmanager/pkg/errors"
+	"depsmanager/pkg/tenant"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// WithClientIdentities authenticates requests without api key by the verified client certificate
+// of the mTLS connection, its subject is looked up in ids.
+func WithClientIdentities(ids []depsmanager.ClientIdentity) func(a *API) {
+	return func(a *API) {
+		a.clientIdentities = make(map[string]depsmanager.Identity, len(ids))
+		for _, id := range ids {
+			name := id.Tenant
+			if name == "" {
+				name = tenant.Default
+			}
+			a.clientIdentities[id.Subject] = depsmanager.Identity{Name: id.Subject, Role: id.Role, Tenant: name}
+		}
+	}
+}
+
+// authenticate resolves api key of the request into identity, requests without valid key get 401.
+// Requests without key are authenticated by a mapped client certificate, see WithClientIdentities.
+// Every authenticated identity has at least reader role, stricter routes use requireRole.
+func (a *API) authenticate(next http.Handler) http.Handler {
+	if !a.authEnabled {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		customErr.HandleError(func(w http.ResponseWriter, r *http.Request) error {
+			key := auth.KeyFromRequest(r)
+			if key == "" {
+				if id, ok := a.certIdentity(r); ok {
+					next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), id)))
+					return nil
+				}
+			}
+
+			id, err := a.service.Authenticate(r.Context(), key)
+			if err != nil {
+				if errors.Is(err, depsmanager.ErrUnauthorized) {
+					return customErr.NewUnauthorized(err)
+				}
+				return customErr.NewInternal(fmt.Errorf("service.Authenticate: %w", err))
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), id)))
+			return nil
+		})(w, r)
+	})
+}
+
+// certIdentity returns identity mapped to subject of the client certificate, only certificates verified
+// during the handshake count.
+func (a *API) certIdentity(r *http.Request) (depsmanager.Identity, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return depsmanager.Identity{}, false
+	}
+	id, ok := a.clientIdentities[r.TLS.VerifiedChains[0][0].Subject.String()]
+	return id, ok
+}
+
+// requireRole rejects identities without role with 403, it is a no-op with auth disabled.
+func (a *API) requireRole(role depsmanager.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !a.authEnabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			customErr.HandleError(func(w http.ResponseWriter, r *http.Request) error {
+				id, ok := auth.FromContext(r.Context())
+				if !ok {
+					return customErr.NewUnauthorized(depsmanager.ErrUnauthorized)
+				}
+				if !id.Role.Allows(role) {
+					return customErr.NewForbidden(fmt.Errorf("%w: %s requires %s role", depsmanager.ErrForbidden, r.URL.Path, role))
+				}
+
+				next.ServeHTTP(w, r)
+				return nil
+			})(w, r)
+		})
+	}
+}
+
+// CreateAPIKey
+// @summary CreateAPIKey
+// @description Create api key, the key is returned only in this response. Requires admin role.
+// @description Without body.tenant the key belongs to the tenant of the caller.
+// @tags keys
+// @accept json
+// @param request r.body body depsmanager.CreateAPIKeyRequest true "request body"
+// @failure 500 "internal error"
+// @failure 404 "not found tenant"
+// @failure 400 "cannot decode body / body.name is required / invalid body.role / invalid body.tenant"
+// @Success 201 {object} depsmanager.CreatedAPIKey "created key"
+// @Router /v2/keys [post]
+func (a *API) CreateAPIKey(w http.ResponseWriter, r *http.Request) error {
+	var req depsmanager.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
+	}
+
+	var v customErr.ValidationError
+	v.Required("name", req.Name)
+	if !req.Role.Valid() {
+		v.Add("role", "must be one of reader, editor, admin")
+	}
+	if req.Tenant != "" && !tenant.ValidName(req.Tenant) {
+		v.Add("tenant", "must be lowercase letters, digits and dashes")
+	}
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	key, err := a.service.CreateAPIKey(r.Context(), req.Name, req.Role, req.Tenant)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrTenantNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.CreateAPIKey: %w", err))
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(key); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// ListAPIKeys
+// @summary ListAPIKeys
+// @description List api keys of tenant including revoked ones, keys themselves are never returned. Requires admin role.
+// @tags keys
+// @failure 500 "internal error"
+// @Success 200 {object} []depsmanager.APIKey "api keys"
+// @Router /v2/keys [get]
+func (a *API) ListAPIKeys(w http.ResponseWriter, r *http.Request) error {
+	keys, err := a.service.ListAPIKeys(r.Context())
+	if err != nil {
+		return customErr.NewInternal(fmt.Errorf("service.ListAPIKeys: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(keys); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// RevokeAPIKey
+// @summary RevokeAPIKey
+// @description Revoke api key, it stops working immediately. Requires admin role.
+// @tags keys
+// @param id path int true "api key id"
+// @failure 500 "internal error"
+// @failure 404 "not found key / already revoked"
+// @failure 400 "invalid id"
+// @Success 204 "revoked"
+// @Router /v2/keys/{id} [delete]
+func (a *API) RevokeAPIKey(w http.ResponseWriter, r *http.Request) error {
+	var v customErr.ValidationError
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		v.Add("id", "must be an integer")
+	}
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	if err := a.service.RevokeAPIKey(r.Context(), id); err != nil {
+		if errors.Is(err, depsmanager.ErrAPIKeyNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.RevokeAPIKey: %w", err))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"depsmanager"
+	customErr "depsmanager/pkg/errors"
+	"depsmanager/pkg/tenant"
+	"depsmanager/service/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuth(t *testing.T) (http.Handler, *mocks.Service) {
+	t.Helper()
+	svc := new(mocks.Service)
+	a := NewAPI(svc, WithAuth(true))
+
+	for key, role := range map[string]depsmanager.Role{
+		"dm_reader": depsmanager.RoleReader,
+		"dm_editor": depsmanager.RoleEditor,
+		"dm_admin":  depsmanager.RoleAdmin,
+	} {
+		svc.On("Authenticate", mock.Anything, key).Return(depsmanager.Identity{Name: key, Role: role}, nil).Maybe()
+	}
+	svc.On("Authenticate", mock.Anything, mock.Anything).Return(depsmanager.Identity{}, depsmanager.ErrUnauthorized).Maybe()
+	return a.GetHandler(), svc
+}
+
+func doAuth(h http.Handler, method, path, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAuth_MissingOrInvalidKey(t *testing.T) {
+	h, _ := setupAuth(t)
+
+	for _, key := range []string{"", "dm_unknown"} {
+		rr := doAuth(h, http.MethodGet, "/api/v2/projects", key)
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, "Bearer", rr.Header().Get("WWW-Authenticate"))
+		assert.Contains(t, rr.Body.String(), customErr.CodeUnauthorized)
+	}
+}
+
+func TestAuth_RolesPerRoute(t *testing.T) {
+	h, svc := setupAuth(t)
+	svc.On("ListProjects", mock.Anything, mock.Anything).Return([]depsmanager.Project{}, nil)
+	svc.On("DeleteProject", mock.Anything, "react", "18.3.1").Return(nil)
+	svc.On("ListAPIKeys", mock.Anything).Return([]depsmanager.APIKey{}, nil)
+
+	tests := []struct {
+		method, path, key string
+		want              int
+	}{
+		{http.MethodGet, "/api/v2/projects", "dm_reader", http.StatusOK},
+		{http.MethodGet, "/api/v1/projects/", "dm_reader", http.StatusOK},
+		{http.MethodDelete, "/api/v2/projects/react/versions/18.3.1", "dm_reader", http.StatusForbidden},
+		{http.MethodDelete, "/api/v2/projects/react/versions/18.3.1", "dm_editor", http.StatusNoContent},
+		{http.MethodGet, "/api/v2/keys", "dm_editor", http.StatusForbidden},
+		{http.MethodGet, "/api/v2/keys", "dm_admin", http.StatusOK},
+		{http.MethodDelete, "/api/v2/projects/react/versions/18.3.1", "dm_admin", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path+" "+tt.key, func(t *testing.T) {
+			rr := doAuth(h, tt.method, tt.path, tt.key)
+			require.Equal(t, tt.want, rr.Code, rr.Body.String())
+		})
+	}
+}
+
+func TestAuth_ClientCertificate(t *testing.T) {
+	svc := new(mocks.Service)
+	a := NewAPI(svc, WithAuth(true), WithClientIdentities([]depsmanager.ClientIdentity{
+		{Subject: "CN=ci-runner,O=Acme", Role: depsmanager.RoleReader, Tenant: "payments"},
+	}))
+	h := a.GetHandler()
+	svc.On("Authenticate", mock.Anything, "").Return(depsmanager.Identity{}, depsmanager.ErrUnauthorized)
+	svc.On("ListProjects", mock.MatchedBy(func(ctx context.Context) bool {
+		return tenant.FromContext(ctx) == "payments"
+	}), mock.Anything).Return([]depsmanager.Project{}, nil).Once()
+
+	cert := func(cn string) *x509.Certificate {
+		return &x509.Certificate{Subject: pkix.Name{CommonName: cn, Organization: []string{"Acme"}}}
+	}
+	do := func(method, path string, state *tls.ConnectionState) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.TLS = state
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	verified := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert("ci-runner")}}}
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v2/projects", verified))
+	assert.Equal(t, http.StatusForbidden, do(http.MethodDelete, "/api/v2/projects/react/versions/18.3.1", verified),
+		"mapped role applies")
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/v2/projects",
+		&tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert("other")}}}), "unmapped subject")
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/v2/projects",
+		&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert("ci-runner")}}), "not verified")
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/v2/projects", nil))
+	svc.AssertExpectations(t)
+}
+
+func TestAuth_DisabledByDefault(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("ListAPIKeys", mock.Anything).Return([]depsmanager.APIKey{}, nil).Once()
+
+	rr := doAuth(h, http.MethodGet, "/api/v2/keys", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	svc.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything)
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("CreateAPIKey", mock.Anything, "ci", depsmanager.RoleReader, "").
+		Return(depsmanager.CreatedAPIKey{APIKey: depsmanager.APIKey{ID: 1, Name: "ci", Role: depsmanager.RoleReader}, Key: "dm_x"}, nil).Once()
+
+	rr := doJSON(t, h, http.MethodPost, "/api/v2/keys", depsmanager.CreateAPIKeyRequest{Name: "ci", Role: depsmanager.RoleReader})
+	require.Equal(t, http.StatusCreated, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"key":"dm_x"`)
+
+	rr = doJSON(t, h, http.MethodPost, "/api/v2/keys", depsmanager.CreateAPIKeyRequest{Name: "ci", Role: "root"})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"role"`)
+	svc.AssertExpectations(t)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("RevokeAPIKey", mock.Anything, int64(3)).Return(depsmanager.ErrAPIKeyNotFound).Once()
+
+	rr := doJSON(t, h, http.MethodDelete, "/api/v2/keys/3", nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), customErr.CodeAPIKeyNotFound)
+
+	rr = doJSON(t, h, http.MethodDelete, "/api/v2/keys/abc", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	svc.AssertExpectations(t)
+}
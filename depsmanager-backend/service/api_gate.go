@@ -0,0 +1,135 @@
+package service
+
+import (
+	"depsmanager"
+	customErr "depsmanager/pkg/errors"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const maxLockfileSize = 32 << 20
+
+// Gate
+// @summary Gate
+// @description Score packages of uploaded package-lock.json and check them against thresholds, nothing is stored.
+// @description The lockfile is sent as request body or as multipart form file "lockfile".
+// @description With baseline_project and baseline_version only packages missing in the stored baseline are checked.
+// @description Thresholds not given fall back to server defaults, unset thresholds are not checked.
+// @tags gate
+// @accept json
+// @accept multipart/form-data
+// @param request body string true "package-lock.json"
+// @param baseline_project query string false "baseline project name"
+// @param baseline_version query string false "baseline project version, required with baseline_project"
+// @param min_score query number false "minimal score of scored packages"
+// @param max_unscored query int false "maximal number of packages without scorecard"
+// @param max_scorecard_age_days query int false "maximal scorecard age in days"
+// @failure 500 "internal error"
+// @failure 404 "not found baseline project"
+// @failure 400 "cannot read lockfile / invalid lockfile / invalid query parameters, see errors"
+// @Success 200 {object} depsmanager.GateResult "verdict, exit code hint, changed packages and violations"
+// @Router /v1/gate [post]
+func (a *API) Gate(w http.ResponseWriter, r *http.Request) error {
+	req, err := parseGateRequest(r.URL.Query())
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	lock, err := readLockfile(w, r)
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	resp, err := a.service.EvaluateLockfile(r.Context(), lock, req)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrInvalidLockfile) {
+			return customErr.NewBadRequest(err)
+		}
+		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.EvaluateLockfile: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+func parseGateRequest(q url.Values) (depsmanager.GateRequest, error) {
+	req := depsmanager.GateRequest{
+		BaselineProject: q.Get("baseline_project"),
+		BaselineVersion: q.Get("baseline_version"),
+	}
+
+	var v customErr.ValidationError
+	if req.BaselineProject != "" {
+		v.Required("baseline_version", req.BaselineVersion)
+	}
+
+	if s := q.Get("min_score"); s != "" {
+		minScore, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			v.Add("min_score", "must be a number")
+		} else {
+			req.Thresholds.MinScore = &minScore
+		}
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **int
+	}{
+		{"max_unscored", &req.Thresholds.MaxUnscored},
+		{"max_scorecard_age_days", &req.Thresholds.MaxScorecardAgeDays},
+	} {
+		s := q.Get(p.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			v.Add(p.name, "must be a non-negative integer")
+			continue
+		}
+		*p.dst = &n
+	}
+
+	if err := v.Err(); err != nil {
+		return depsmanager.GateRequest{}, err
+	}
+	return req, nil
+}
+
+func readLockfile(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxLockfileSize)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		lock, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("io.ReadAll(r.Body): %w", err)
+		}
+		return lock, nil
+	}
+
+	file, _, err := r.FormFile("lockfile")
+	if err != nil {
+		return nil, fmt.Errorf("r.FormFile(lockfile): %w", err)
+	}
+	defer file.Close()
+
+	lock, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll(lockfile): %w", err)
+	}
+	return lock, nil
+}
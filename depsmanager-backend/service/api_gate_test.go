@@ -0,0 +1,100 @@
+package service
+
+import (
+	"bytes"
+	"depsmanager"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGate_Success(t *testing.T) {
+	h, svc := setup(t)
+	lock := `{"lockfileVersion":3,"packages":{}}`
+	minScore := 4.5
+	maxUnscored := 2
+	svc.On("EvaluateLockfile", mock.Anything, []byte(lock), depsmanager.GateRequest{
+		BaselineProject: "app",
+		BaselineVersion: "1.0.0",
+		Thresholds:      depsmanager.GateThresholds{MinScore: &minScore, MaxUnscored: &maxUnscored},
+	}).Return(depsmanager.GateResult{Verdict: depsmanager.VerdictFail, ExitCode: 1}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/gate?baseline_project=app&baseline_version=1.0.0&min_score=4.5&max_unscored=2", bytes.NewBufferString(lock))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var got depsmanager.GateResult
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, depsmanager.VerdictFail, got.Verdict)
+	assert.Equal(t, 1, got.ExitCode)
+	svc.AssertExpectations(t)
+}
+
+func TestGate_Multipart(t *testing.T) {
+	h, svc := setup(t)
+	lock := `{"lockfileVersion":3,"packages":{}}`
+	svc.On("EvaluateLockfile", mock.Anything, []byte(lock), depsmanager.GateRequest{}).
+		Return(depsmanager.GateResult{Verdict: depsmanager.VerdictPass}, nil).Once()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("lockfile", "package-lock.json")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte(lock))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/gate", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestGate_BadRequest(t *testing.T) {
+	for _, query := range []string{
+		"?baseline_project=app",
+		"?min_score=abc",
+		"?max_unscored=-1",
+		"?max_scorecard_age_days=x",
+	} {
+		t.Run(query, func(t *testing.T) {
+			h, svc := setup(t)
+			rr := doJSON(t, h, http.MethodPost, "/api/v1/gate"+query, nil)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			svc.AssertNotCalled(t, "EvaluateLockfile", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGate_ServiceErrors(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{err: fmt.Errorf("lockfile.Parse(): %w", depsmanager.ErrInvalidLockfile), code: http.StatusBadRequest},
+		{err: fmt.Errorf("baseline: %w", depsmanager.ErrProjectNotFound), code: http.StatusNotFound},
+		{err: fmt.Errorf("boom"), code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			h, svc := setup(t)
+			svc.On("EvaluateLockfile", mock.Anything, mock.Anything, mock.Anything).Return(depsmanager.GateResult{}, tt.err).Once()
+
+			rr := doJSON(t, h, http.MethodPost, "/api/v1/gate?baseline_project=app&baseline_version=1", nil)
+			require.Equal(t, tt.code, rr.Code)
+			svc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"depsmanager"
+	customErr "depsmanager/pkg/errors"
+	"depsmanager/pkg/labels"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GetProjectMetadata
+// @summary GetProjectMetadata
+// @description Get owner, description, repository URL and labels of project, they are shared by all stored versions.
+// @tags projects
+// @param name path string true "project name, escaped"
+// @failure 500 "internal error"
+// @failure 404 "not found project"
+// @failure 400 "invalid path"
+// @Success 200 {object} depsmanager.ProjectMetadata "project metadata"
+// @Router /v2/projects/{name}/metadata [get]
+func (a *API) GetProjectMetadata(w http.ResponseWriter, r *http.Request) error {
+	name, err := pathParam(r, "name")
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	m, err := a.service.GetProjectMetadata(r.Context(), name)
+	if err != nil {
+		return metadataError("service.GetProjectMetadata", err)
+	}
+
+	if err := json.NewEncoder(w).Encode(m); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// SetProjectMetadata
+// @summary SetProjectMetadata
+// @description Replace owner, description, repository URL and labels of a stored project. Requires editor role.
+// @description Label keys are lowercase letters, digits and ".", "_", "/", "-", values letters, digits and ".", "_", "-", both at most 63 characters.
+// @tags projects
+// @accept json
+// @param name path string true "project name, escaped"
+// @param request r.body body depsmanager.ProjectMetadataRequest true "request body"
+// @failure 500 "internal error"
+// @failure 404 "not found project"
+// @failure 400 "cannot decode body / invalid label / invalid repo_url"
+// @Success 200 {object} depsmanager.ProjectMetadata "saved metadata"
+// @Router /v2/projects/{name}/metadata [put]
+func (a *API) SetProjectMetadata(w http.ResponseWriter, r *http.Request) error {
+	name, err := pathParam(r, "name")
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+	var req depsmanager.ProjectMetadataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
+	}
+
+	var v customErr.ValidationError
+	for k, value := range req.Labels {
+		if !labels.ValidKey(k) {
+			v.Add("labels", fmt.Sprintf("invalid key %q", k))
+		} else if !labels.ValidValue(value) {
+			v.Add("labels."+k, fmt.Sprintf("invalid value %q", value))
+		}
+	}
+	if req.RepoURL != "" {
+		if u, err := url.Parse(req.RepoURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.Add("repo_url", "must be an absolute http or https URL")
+		}
+	}
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	m, err := a.service.SetProjectMetadata(r.Context(), name, req)
+	if err != nil {
+		return metadataError("service.SetProjectMetadata", err)
+	}
+
+	if err := json.NewEncoder(w).Encode(m); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// DeleteProjectMetadata
+// @summary DeleteProjectMetadata
+// @description Remove owner, description, repository URL and labels of project. Requires editor role.
+// @tags projects
+// @param name path string true "project name, escaped"
+// @failure 500 "internal error"
+// @failure 404 "not found project"
+// @failure 400 "invalid path"
+// @Success 204 "deleted successfully"
+// @Router /v2/projects/{name}/metadata [delete]
+func (a *API) DeleteProjectMetadata(w http.ResponseWriter, r *http.Request) error {
+	name, err := pathParam(r, "name")
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	if err := a.service.DeleteProjectMetadata(r.Context(), name); err != nil {
+		return metadataError("service.DeleteProjectMetadata", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func metadataError(op string, err error) error {
+	if errors.Is(err, depsmanager.ErrProjectNotFound) {
+		return customErr.NewNotFound(err)
+	}
+	return customErr.NewInternal(fmt.Errorf("%s: %w", op, err))
+}
+
+// selectorParam parses selector query parameter, an invalid one is added to v.
+func selectorParam(r *http.Request, v *customErr.ValidationError) labels.Selector {
+	selector, err := labels.Parse(r.URL.Query().Get("selector"))
+	if err != nil {
+		v.Add("selector", err.Error())
+	}
+	return selector
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"depsmanager"
+	"depsmanager/pkg/labels"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectMetadata_SetGetDelete(t *testing.T) {
+	h, svc := setup(t)
+	req := depsmanager.ProjectMetadataRequest{
+		Owner:   "build-tools",
+		RepoURL: "https://github.com/babel/babel",
+		Labels:  map[string]string{"team": "build", "tier": "1"},
+	}
+	saved := depsmanager.ProjectMetadata{ProjectName: "@babel/core", Owner: req.Owner, RepoURL: req.RepoURL, Labels: req.Labels, UpdatedAt: 1}
+	svc.On("SetProjectMetadata", mock.Anything, "@babel/core", req).Return(saved, nil).Once()
+	svc.On("GetProjectMetadata", mock.Anything, "@babel/core").Return(saved, nil).Once()
+	svc.On("DeleteProjectMetadata", mock.Anything, "@babel/core").Return(nil).Once()
+	svc.On("GetProjectMetadata", mock.Anything, "missing").Return(depsmanager.ProjectMetadata{}, depsmanager.ErrProjectNotFound).Once()
+
+	rr := doJSON(t, h, http.MethodPut, "/api/v2/projects/%40babel%2Fcore/metadata", req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	rr = doJSON(t, h, http.MethodGet, "/api/v2/projects/%40babel%2Fcore/metadata", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var got depsmanager.ProjectMetadata
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, saved, got)
+
+	rr = doJSON(t, h, http.MethodDelete, "/api/v2/projects/%40babel%2Fcore/metadata", nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = doJSON(t, h, http.MethodGet, "/api/v2/projects/missing/metadata", nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestSetProjectMetadata_Validation(t *testing.T) {
+	h, svc := setup(t)
+
+	rr := doJSON(t, h, http.MethodPut, "/api/v2/projects/react/metadata", depsmanager.ProjectMetadataRequest{
+		RepoURL: "github.com/facebook/react",
+		Labels:  map[string]string{"Team": "web"},
+	})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	for _, field := range []string{"labels", "repo_url"} {
+		assert.Contains(t, rr.Body.String(), `"field":"`+field+`"`)
+	}
+
+	rr = doJSON(t, h, http.MethodPut, "/api/v2/projects/react/metadata", depsmanager.ProjectMetadataRequest{
+		Labels: map[string]string{"team": "web platform"},
+	})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"labels.team"`)
+	svc.AssertNotCalled(t, "SetProjectMetadata", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLabelSelector_Query(t *testing.T) {
+	h, svc := setup(t)
+	bySelector := mock.MatchedBy(func(s labels.Selector) bool { return s.String() == "team=payments,env!=dev" })
+	svc.On("ListProjects", mock.Anything, bySelector).Return([]depsmanager.Project{}, nil).Once()
+	svc.On("GetPortfolioSummary", mock.Anything, bySelector).Return(depsmanager.RiskSummary{}, nil).Once()
+	svc.On("GetDependenciesByExactScore", mock.Anything, 4.0, bySelector).Return([]string{}, nil).Once()
+	svc.On("GetProjectsByDependency", mock.Anything, "lodash", bySelector).Return([]depsmanager.Project{}, nil).Once()
+
+	for _, path := range []string{
+		"/api/v2/projects?selector=team%3Dpayments%2Cenv%21%3Ddev",
+		"/api/v2/summary?selector=team%3Dpayments%2Cenv%21%3Ddev",
+		"/api/v2/dependencies?score=4&selector=team%3Dpayments%2Cenv%21%3Ddev",
+		"/api/v2/dependencies/lodash/projects?selector=team%3Dpayments%2Cenv%21%3Ddev",
+	} {
+		rr := doJSON(t, h, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code, path)
+	}
+
+	rr := doJSON(t, h, http.MethodGet, "/api/v2/projects?selector=Team%3Dx", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"selector"`)
+	svc.AssertExpectations(t)
+}
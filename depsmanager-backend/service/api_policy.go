@@ -0,0 +1,174 @@
+package service
+
+import (
+	"depsmanager"
+	customErr "depsmanager/pkg/errors"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const maxPolicyDocumentSize = 1 << 20
+
+// SavePolicy
+// @summary SavePolicy
+// @description Create or replace policy defined in YAML. Policy without project_name is global.
+// @description Supported rule types: min_score (optional relation: direct/indirect), max_scorecard_age_days, max_unscored.
+// @tags policies
+// @accept application/yaml
+// @param request body string true "YAML policy document"
+// @failure 500 "internal error"
+// @failure 400 "cannot read body / invalid policy"
+// @Success 201 {object} depsmanager.Policy "saved policy"
+// @Router /v1/policies [post]
+func (a *API) SavePolicy(w http.ResponseWriter, r *http.Request) error {
+	document, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPolicyDocumentSize))
+	if err != nil {
+		return customErr.NewBadRequest(fmt.Errorf("io.ReadAll(r.Body): %w", err))
+	}
+
+	p, err := a.service.SavePolicy(r.Context(), document)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrInvalidPolicy) {
+			return customErr.NewBadRequest(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.SavePolicy: %w", err))
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// ListPolicies
+// @summary ListPolicies
+// @description List global policies and, if project_name is given, policies of this project.
+// @tags policies
+// @param project_name query string false "project name"
+// @failure 500 "internal error"
+// @Success 200 {object} []depsmanager.Policy "policies"
+// @Router /v1/policies [get]
+func (a *API) ListPolicies(w http.ResponseWriter, r *http.Request) error {
+	policies, err := a.service.ListPolicies(r.Context(), r.URL.Query().Get("project_name"))
+	if err != nil {
+		return customErr.NewInternal(fmt.Errorf("service.ListPolicies: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(policies); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// DeletePolicy
+// @summary DeletePolicy
+// @description Delete policy by name. Empty project_name deletes a global policy.
+// @tags policies
+// @accept json
+// @param request r.body body depsmanager.DeletePolicyRequest true "request body"
+// @failure 500 "internal error"
+// @failure 404 "not found policy"
+// @failure 400 "cannot decode body / body.name is required"
+// @Success 204 "deleted"
+// @Router /v1/policies [delete]
+func (a *API) DeletePolicy(w http.ResponseWriter, r *http.Request) error {
+	var req depsmanager.DeletePolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
+	}
+
+	var v customErr.ValidationError
+	v.Required("name", req.Name)
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	if err := a.service.DeletePolicy(r.Context(), req.ProjectName, req.Name); err != nil {
+		if errors.Is(err, depsmanager.ErrPolicyNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.DeletePolicy: %w", err))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// EvaluatePolicies
+// @summary EvaluatePolicies
+// @description Evaluate global and project policies against stored dependencies of project version.
+// @description The result is stored as the latest evaluation.
+// @tags policies
+// @accept json
+// @param request r.body body depsmanager.ProjectRequest true "request body"
+// @failure 500 "internal error"
+// @failure 404 "not found project"
+// @failure 400 "cannot decode request / body.project_name is required / body.version is required"
+// @Success 200 {object} depsmanager.PolicyEvaluation "verdict with violations"
+// @Router /v1/policies/evaluate [post]
+func (a *API) EvaluatePolicies(w http.ResponseWriter, r *http.Request) error {
+	var req depsmanager.ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
+	}
+
+	if err := validateProjectRequest(req); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	resp, err := a.service.EvaluatePolicies(r.Context(), req.ProjectName, req.Version)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.EvaluatePolicies: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// PolicyEvaluation
+// @summary PolicyEvaluation
+// @description Get the latest policy evaluation of project version, policies are evaluated automatically after every fetch.
+// @tags policies
+// @accept json
+// @param request r.body body depsmanager.ProjectRequest true "request body"
+// @failure 500 "internal error"
+// @failure 404 "not found project / not evaluated yet"
+// @failure 400 "cannot decode request / body.project_name is required / body.version is required"
+// @Success 200 {object} depsmanager.PolicyEvaluation "verdict with violations"
+// @Router /v1/policies/evaluation [post]
+func (a *API) PolicyEvaluation(w http.ResponseWriter, r *http.Request) error {
+	var req depsmanager.ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
+	}
+
+	if err := validateProjectRequest(req); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	resp, err := a.service.GetPolicyEvaluation(r.Context(), req.ProjectName, req.Version)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) || errors.Is(err, depsmanager.ErrPolicyEvaluationNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.GetPolicyEvaluation: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
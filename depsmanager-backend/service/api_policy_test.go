@@ -0,0 +1,101 @@
+package service
+
+import (
+	"bytes"
+	"depsmanager"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavePolicy_Success(t *testing.T) {
+	h, svc := setup(t)
+	doc := "name: strict\nrules:\n  - type: max_unscored\n    value: 5\n"
+	svc.On("SavePolicy", mock.Anything, []byte(doc)).
+		Return(depsmanager.Policy{Name: "strict", Rules: []depsmanager.PolicyRule{{Type: depsmanager.PolicyRuleMaxUnscored, Value: 5}}}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/policies/", bytes.NewBufferString(doc))
+	req.Header.Set("Content-Type", "application/yaml")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var got depsmanager.Policy
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, "strict", got.Name)
+	svc.AssertExpectations(t)
+}
+
+func TestSavePolicy_Invalid(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("SavePolicy", mock.Anything, mock.Anything).
+		Return(depsmanager.Policy{}, fmt.Errorf("policy.Parse(): %w", depsmanager.ErrInvalidPolicy)).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/policies/", bytes.NewBufferString("name: x"))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestListPolicies_Success(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("ListPolicies", mock.Anything, "react").Return([]depsmanager.Policy{{Name: "global"}, {Name: "strict", ProjectName: "react"}}, nil).Once()
+
+	rr := doJSON(t, h, http.MethodGet, "/api/v1/policies/?project_name=react", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var got []depsmanager.Policy
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	require.Len(t, got, 2)
+	svc.AssertExpectations(t)
+}
+
+func TestDeletePolicy_NotFound(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("DeletePolicy", mock.Anything, "react", "strict").Return(depsmanager.ErrPolicyNotFound).Once()
+
+	rr := doJSON(t, h, http.MethodDelete, "/api/v1/policies/", depsmanager.DeletePolicyRequest{Name: "strict", ProjectName: "react"})
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestDeletePolicy_Validation(t *testing.T) {
+	h, _ := setup(t)
+	rr := doJSON(t, h, http.MethodDelete, "/api/v1/policies/", depsmanager.DeletePolicyRequest{ProjectName: "react"})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestEvaluatePolicies_Success(t *testing.T) {
+	h, svc := setup(t)
+	resp := depsmanager.PolicyEvaluation{
+		ProjectName: "react",
+		Version:     "18.3.1",
+		Verdict:     depsmanager.VerdictFail,
+		Violations:  []depsmanager.PolicyViolation{{Policy: "strict", Rule: depsmanager.PolicyRuleMinScore, DependencyName: "x", Message: "low"}},
+	}
+	svc.On("EvaluatePolicies", mock.Anything, "react", "18.3.1").Return(resp, nil).Once()
+
+	rr := doJSON(t, h, http.MethodPost, "/api/v1/policies/evaluate", depsmanager.ProjectRequest{ProjectName: "react", Version: "18.3.1"})
+	require.Equal(t, http.StatusOK, rr.Code)
+	var got depsmanager.PolicyEvaluation
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, resp, got)
+	svc.AssertExpectations(t)
+}
+
+func TestPolicyEvaluation_NotEvaluatedYet(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("GetPolicyEvaluation", mock.Anything, "react", "18.3.1").
+		Return(depsmanager.PolicyEvaluation{}, depsmanager.ErrPolicyEvaluationNotFound).Once()
+
+	rr := doJSON(t, h, http.MethodPost, "/api/v1/policies/evaluation", depsmanager.ProjectRequest{ProjectName: "react", Version: "18.3.1"})
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	svc.AssertExpectations(t)
+}
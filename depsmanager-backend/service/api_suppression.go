@@ -0,0 +1,114 @@
+package service
+
+import (
+	"depsmanager"
+	customErr "depsmanager/pkg/errors"
+	"depsmanager/pkg/semver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateSuppression
+// @summary CreateSuppression
+// @description Accept risk of a dependency until expires_at. Without project_name the suppression applies to every project,
+// @description without project_version to every version of the project, without version_range to every version of the dependency.
+// @description Suppressed dependencies are flagged in listings and left out of score queries, summaries, policies and gate. Requires editor role.
+// @tags suppressions
+// @accept json
+// @param request r.body body depsmanager.CreateSuppressionRequest true "request body"
+// @failure 500 "internal error"
+// @failure 400 "cannot decode body / required field is missing / expires_at is not in the future / invalid version_range"
+// @Success 201 {object} depsmanager.Suppression "created suppression"
+// @Router /v2/suppressions [post]
+func (a *API) CreateSuppression(w http.ResponseWriter, r *http.Request) error {
+	var req depsmanager.CreateSuppressionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
+	}
+
+	var v customErr.ValidationError
+	v.Required("dependency_name", req.DependencyName)
+	v.Required("justification", req.Justification)
+	v.Required("approver", req.Approver)
+	if req.ExpiresAt <= time.Now().Unix() {
+		v.Add("expires_at", "must be in the future")
+	}
+	if req.ProjectVersion != "" && req.ProjectName == "" {
+		v.Add("project_version", "requires project_name")
+	}
+	if _, err := semver.ParseRange(req.VersionRange); err != nil {
+		v.Add("version_range", "must be an npm version range, e.g. <4.17.21")
+	}
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	created, err := a.service.CreateSuppression(r.Context(), req)
+	if err != nil {
+		return customErr.NewInternal(fmt.Errorf("service.CreateSuppression: %w", err))
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(created); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// ListSuppressions
+// @summary ListSuppressions
+// @description List suppressions of tenant, expired ones are kept and marked with expired.
+// @tags suppressions
+// @failure 500 "internal error"
+// @Success 200 {object} []depsmanager.Suppression "suppressions"
+// @Router /v2/suppressions [get]
+func (a *API) ListSuppressions(w http.ResponseWriter, r *http.Request) error {
+	suppressions, err := a.service.ListSuppressions(r.Context())
+	if err != nil {
+		return customErr.NewInternal(fmt.Errorf("service.ListSuppressions: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(suppressions); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// DeleteSuppression
+// @summary DeleteSuppression
+// @description Delete suppression, findings it accepted are active again. Requires editor role.
+// @tags suppressions
+// @param id path int true "suppression id"
+// @failure 500 "internal error"
+// @failure 404 "not found suppression"
+// @failure 400 "invalid id"
+// @Success 204 "deleted"
+// @Router /v2/suppressions/{id} [delete]
+func (a *API) DeleteSuppression(w http.ResponseWriter, r *http.Request) error {
+	var v customErr.ValidationError
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		v.Add("id", "must be an integer")
+	}
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	if err := a.service.DeleteSuppression(r.Context(), id); err != nil {
+		if errors.Is(err, depsmanager.ErrSuppressionNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.DeleteSuppression: %w", err))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"depsmanager"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSuppression(t *testing.T) {
+	h, svc := setup(t)
+	req := depsmanager.CreateSuppressionRequest{
+		DependencyName: "lodash",
+		ProjectName:    "react",
+		VersionRange:   "<4.17.21",
+		Justification:  "prototype pollution is not reachable",
+		Approver:       "security",
+		ExpiresAt:      time.Now().Add(24 * time.Hour).Unix(),
+	}
+	svc.On("CreateSuppression", mock.Anything, req).
+		Return(depsmanager.Suppression{ID: 3, DependencyName: "lodash", ExpiresAt: req.ExpiresAt}, nil).Once()
+
+	rr := doJSON(t, h, http.MethodPost, "/api/v2/suppressions", req)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var got depsmanager.Suppression
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, int64(3), got.ID)
+	svc.AssertExpectations(t)
+}
+
+func TestCreateSuppression_Validation(t *testing.T) {
+	h, svc := setup(t)
+
+	rr := doJSON(t, h, http.MethodPost, "/api/v2/suppressions", depsmanager.CreateSuppressionRequest{
+		ProjectVersion: "18.3.1",
+		VersionRange:   ">=a",
+		ExpiresAt:      time.Now().Add(-time.Hour).Unix(),
+	})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	for _, field := range []string{"dependency_name", "justification", "approver", "expires_at", "project_version", "version_range"} {
+		assert.Contains(t, rr.Body.String(), `"field":"`+field+`"`)
+	}
+	svc.AssertNotCalled(t, "CreateSuppression", mock.Anything, mock.Anything)
+}
+
+func TestListAndDeleteSuppression(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("ListSuppressions", mock.Anything).
+		Return([]depsmanager.Suppression{{ID: 1, DependencyName: "lodash", Expired: true}}, nil).Once()
+	svc.On("DeleteSuppression", mock.Anything, int64(1)).Return(nil).Once()
+	svc.On("DeleteSuppression", mock.Anything, int64(2)).Return(depsmanager.ErrSuppressionNotFound).Once()
+
+	rr := doJSON(t, h, http.MethodGet, "/api/v2/suppressions", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"expired":true`)
+
+	rr = doJSON(t, h, http.MethodDelete, "/api/v2/suppressions/1", nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = doJSON(t, h, http.MethodDelete, "/api/v2/suppressions/2", nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"suppression_not_found"`)
+
+	rr = doJSON(t, h, http.MethodDelete, "/api/v2/suppressions/x", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	svc.AssertExpectations(t)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"depsmanager"
+	"depsmanager/pkg/auth"
+	customErr "depsmanager/pkg/errors"
+	"depsmanager/pkg/tenant"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// scopeTenant puts tenant of the request into context, storage scopes every query by it.
+// The tenant is the one of the api key, admins may pick another one or tenant.All in X-Tenant header.
+// tenant.All is read-only, it is accepted for GET requests only.
+func (a *API) scopeTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		customErr.HandleError(func(w http.ResponseWriter, r *http.Request) error {
+			id, authenticated := auth.FromContext(r.Context())
+			name := tenant.Default
+			if authenticated {
+				name = id.Tenant
+			}
+
+			requested := r.Header.Get(tenant.Header)
+			if requested != "" && requested != name {
+				// without authentication every caller is trusted like an admin
+				if a.authEnabled && !id.Role.Allows(depsmanager.RoleAdmin) {
+					return customErr.NewForbidden(fmt.Errorf("%w: only admins can change tenant", depsmanager.ErrForbidden))
+				}
+				if err := a.checkTenant(r, requested); err != nil {
+					return err
+				}
+				name = requested
+			}
+
+			next.ServeHTTP(w, r.WithContext(tenant.NewContext(r.Context(), name)))
+			return nil
+		})(w, r)
+	})
+}
+
+func (a *API) checkTenant(r *http.Request, name string) error {
+	if name == tenant.All {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			return customErr.NewBadRequest(depsmanager.ErrTenantRequired)
+		}
+		return nil
+	}
+
+	var v customErr.ValidationError
+	if !tenant.ValidName(name) {
+		v.Add(tenant.Header, "must be lowercase letters, digits and dashes or "+tenant.All)
+	}
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	if _, err := a.service.GetTenant(r.Context(), name); err != nil {
+		if errors.Is(err, depsmanager.ErrTenantNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.GetTenant: %w", err))
+	}
+	return nil
+}
+
+// CreateTenant
+// @summary CreateTenant
+// @description Create tenant, create its api keys with tenant field of CreateAPIKey. Requires admin role.
+// @tags tenants
+// @accept json
+// @param request r.body body depsmanager.CreateTenantRequest true "request body"
+// @failure 500 "internal error"
+// @failure 409 "tenant already exists"
+// @failure 400 "cannot decode body / invalid body.name"
+// @Success 201 {object} depsmanager.Tenant "created tenant"
+// @Router /v2/tenants [post]
+func (a *API) CreateTenant(w http.ResponseWriter, r *http.Request) error {
+	var req depsmanager.CreateTenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&req): %w", err))
+	}
+
+	var v customErr.ValidationError
+	if !tenant.ValidName(req.Name) {
+		v.Add("name", "must be lowercase letters, digits and dashes, at most 63 characters")
+	}
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	t, err := a.service.CreateTenant(r.Context(), req.Name)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrTenantAlreadyExists) {
+			return customErr.NewConflict(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.CreateTenant: %w", err))
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(t); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// ListTenants
+// @summary ListTenants
+// @description List all tenants. Requires admin role.
+// @tags tenants
+// @failure 500 "internal error"
+// @Success 200 {object} []depsmanager.Tenant "tenants"
+// @Router /v2/tenants [get]
+func (a *API) ListTenants(w http.ResponseWriter, r *http.Request) error {
+	tenants, err := a.service.ListTenants(r.Context())
+	if err != nil {
+		return customErr.NewInternal(fmt.Errorf("service.ListTenants: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(tenants); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	customErr "depsmanager/pkg/errors"
+	"depsmanager/pkg/tenant"
+	"depsmanager/service/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTenants(t *testing.T) (http.Handler, *mocks.Service) {
+	t.Helper()
+	svc := new(mocks.Service)
+	a := NewAPI(svc, WithAuth(true))
+
+	svc.On("Authenticate", mock.Anything, "dm_reader").
+		Return(depsmanager.Identity{Name: "ci", Role: depsmanager.RoleReader, Tenant: "team-a"}, nil).Maybe()
+	svc.On("Authenticate", mock.Anything, "dm_admin").
+		Return(depsmanager.Identity{Name: "ops", Role: depsmanager.RoleAdmin, Tenant: tenant.Default}, nil).Maybe()
+	svc.On("GetTenant", mock.Anything, "team-a").Return(depsmanager.Tenant{Name: "team-a"}, nil).Maybe()
+	svc.On("GetTenant", mock.Anything, "missing").Return(depsmanager.Tenant{}, depsmanager.ErrTenantNotFound).Maybe()
+	return a.GetHandler(), svc
+}
+
+func inTenant(name string) any {
+	return mock.MatchedBy(func(ctx context.Context) bool { return tenant.FromContext(ctx) == name })
+}
+
+func doTenant(h http.Handler, method, path, key, tenantHeader string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+	if tenantHeader != "" {
+		req.Header.Set(tenant.Header, tenantHeader)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestScopeTenant_FromAPIKey(t *testing.T) {
+	h, svc := setupTenants(t)
+	svc.On("ListProjects", inTenant("team-a"), mock.Anything).Return([]depsmanager.Project{}, nil).Once()
+
+	rr := doTenant(h, http.MethodGet, "/api/v2/projects", "dm_reader", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// repeating own tenant is allowed, other tenants are not
+	svc.On("ListProjects", inTenant("team-a"), mock.Anything).Return([]depsmanager.Project{}, nil).Once()
+	rr = doTenant(h, http.MethodGet, "/api/v2/projects", "dm_reader", "team-a")
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = doTenant(h, http.MethodGet, "/api/v2/projects", "dm_reader", tenant.All)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestScopeTenant_AdminAcrossTenants(t *testing.T) {
+	h, svc := setupTenants(t)
+	svc.On("ListProjects", inTenant(tenant.All), mock.Anything).Return([]depsmanager.Project{
+		{Tenant: "default", Name: "react", Version: "18.3.1"},
+		{Tenant: "team-a", Name: "react", Version: "18.3.1"},
+	}, nil).Once()
+	svc.On("DeleteProject", inTenant("team-a"), "react", "18.3.1").Return(nil).Once()
+	svc.On("ListDependencies", inTenant(tenant.All), "react", "18.3.1").
+		Return(depsmanager.ListDependenciesResponse{}, depsmanager.ErrTenantRequired).Once()
+
+	rr := doTenant(h, http.MethodGet, "/api/v2/projects", "dm_admin", tenant.All)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"tenant":"team-a"`)
+
+	rr = doTenant(h, http.MethodDelete, "/api/v2/projects/react/versions/18.3.1", "dm_admin", "team-a")
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = doTenant(h, http.MethodDelete, "/api/v2/projects/react/versions/18.3.1", "dm_admin", tenant.All)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), customErr.CodeTenantRequired)
+
+	rr = doTenant(h, http.MethodGet, "/api/v2/projects/react/versions/18.3.1/dependencies", "dm_admin", tenant.All)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// mapped in one place for every handler
+	svc.On("GetProjectSummary", inTenant(tenant.All), "react", "18.3.1").
+		Return(depsmanager.RiskSummary{}, depsmanager.ErrTenantRequired).Once()
+	rr = doTenant(h, http.MethodGet, "/api/v2/projects/react/versions/18.3.1/summary", "dm_admin", tenant.All)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), customErr.CodeTenantRequired)
+
+	rr = doTenant(h, http.MethodGet, "/api/v2/projects", "dm_admin", "missing")
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), customErr.CodeTenantNotFound)
+
+	rr = doTenant(h, http.MethodGet, "/api/v2/projects", "dm_admin", "Team A")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestScopeTenant_DefaultWithoutAuth(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("ListProjects", inTenant(tenant.Default), mock.Anything).Return([]depsmanager.Project{}, nil).Once()
+
+	rr := doJSON(t, h, http.MethodGet, "/api/v2/projects", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestCreateTenant(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("CreateTenant", mock.Anything, "team-a").Return(depsmanager.Tenant{Name: "team-a", CreatedAt: 1}, nil).Once()
+	svc.On("CreateTenant", mock.Anything, "team-b").Return(depsmanager.Tenant{}, depsmanager.ErrTenantAlreadyExists).Once()
+
+	rr := doJSON(t, h, http.MethodPost, "/api/v2/tenants", depsmanager.CreateTenantRequest{Name: "team-a"})
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	rr = doJSON(t, h, http.MethodPost, "/api/v2/tenants", depsmanager.CreateTenantRequest{Name: "team-b"})
+	require.Equal(t, http.StatusConflict, rr.Code)
+
+	rr = doJSON(t, h, http.MethodPost, "/api/v2/tenants", depsmanager.CreateTenantRequest{Name: "*"})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	svc.AssertExpectations(t)
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"bytes"
 	"depsmanager"
+	customErr "depsmanager/pkg/errors"
+	"depsmanager/pkg/requestid"
 	"depsmanager/service/mocks"
 	"encoding/json"
 	"errors"
@@ -200,7 +202,7 @@ func TestListDependencies_InternalError(t *testing.T) {
 func TestListProjects_Success(t *testing.T) {
 	h, svc := setup(t)
 	out := []depsmanager.Project{{Name: "a", Version: "1.0.0", UpdatedAt: 1}}
-	svc.On("ListProjects", mock.Anything).Return(out, nil).Once()
+	svc.On("ListProjects", mock.Anything, mock.Anything).Return(out, nil).Once()
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/projects/", nil)
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
@@ -237,7 +239,7 @@ func TestAPI_ProjectVersions_Validation(t *testing.T) {
 }
 func TestListProjects_InternalError(t *testing.T) {
 	h, svc := setup(t)
-	svc.On("ListProjects", mock.Anything).Return(nil, errors.New("db failure")).Once()
+	svc.On("ListProjects", mock.Anything, mock.Anything).Return(nil, errors.New("db failure")).Once()
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/projects/", nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -282,7 +284,7 @@ func TestAPI_ProjectByDependency_Success(t *testing.T) {
 		{Name: "vue", Version: "3.5.0", UpdatedAt: time.Now().Unix()},
 	}
 	svc.
-		On("GetProjectsByDependency", mock.Anything, "shared").
+		On("GetProjectsByDependency", mock.Anything, "shared", mock.Anything).
 		Return(out, nil).Once()
 
 	rr := doJSON(t, h, http.MethodPost, "/api/v1/dependencies/byprojectname", body)
@@ -318,7 +320,7 @@ func TestAPI_ProjectByDependency_NotFound(t *testing.T) {
 	body := depsmanager.GetProjectNameByDepNameReq{DependencyName: "missing"}
 
 	svc.
-		On("GetProjectsByDependency", mock.Anything, "missing").
+		On("GetProjectsByDependency", mock.Anything, "missing", mock.Anything).
 		Return([]depsmanager.Project(nil), depsmanager.ErrProjectNotFound).Once()
 
 	rr := doJSON(t, h, http.MethodPost, "/api/v1/dependencies/byprojectname", body)
@@ -332,7 +334,7 @@ func TestAPI_ProjectByDependency_InternalError(t *testing.T) {
 	body := depsmanager.GetProjectNameByDepNameReq{DependencyName: "shared"}
 
 	svc.
-		On("GetProjectsByDependency", mock.Anything, "shared").
+		On("GetProjectsByDependency", mock.Anything, "shared", mock.Anything).
 		Return([]depsmanager.Project(nil), errors.New("boom")).Once()
 
 	rr := doJSON(t, h, http.MethodPost, "/api/v1/dependencies/byprojectname", body)
@@ -346,7 +348,7 @@ func TestDependenciesByScore_Success(t *testing.T) {
 	body := depsmanager.GetDependenciesByScore{Score: 81.5}
 
 	svc.
-		On("GetDependenciesByExactScore", mock.Anything, 81.5).
+		On("GetDependenciesByExactScore", mock.Anything, 81.5, mock.Anything).
 		Return([]string{"left-pad", "shared"}, nil).Once()
 
 	rr := doJSON(t, h, http.MethodPost, "/api/v1/dependencies/byscore", body)
@@ -373,7 +375,7 @@ func TestDependenciesByScore_NotFound(t *testing.T) {
 	body := depsmanager.GetDependenciesByScore{Score: 99.99}
 
 	svc.
-		On("GetDependenciesByExactScore", mock.Anything, 99.99).
+		On("GetDependenciesByExactScore", mock.Anything, 99.99, mock.Anything).
 		Return([]string(nil), depsmanager.ErrProjectNotFound).Once()
 
 	rr := doJSON(t, h, http.MethodPost, "/api/v1/dependencies/byscore", body)
@@ -387,7 +389,7 @@ func TestDependenciesByScore_InternalError(t *testing.T) {
 	body := depsmanager.GetDependenciesByScore{Score: 77.7}
 
 	svc.
-		On("GetDependenciesByExactScore", mock.Anything, 77.7).
+		On("GetDependenciesByExactScore", mock.Anything, 77.7, mock.Anything).
 		Return([]string(nil), errors.New("deps failure")).Once()
 
 	rr := doJSON(t, h, http.MethodPost, "/api/v1/dependencies/byscore", body)
@@ -623,3 +625,123 @@ func TestDeleteDependency_InternalError(t *testing.T) {
 	require.Equal(t, http.StatusInternalServerError, rr.Code)
 	svc.AssertExpectations(t)
 }
+
+func TestProjectSummary_Success(t *testing.T) {
+	h, svc := setup(t)
+	body := depsmanager.ProjectRequest{ProjectName: "react", Version: "18.3.1"}
+	resp := depsmanager.RiskSummary{ProjectName: "react", Version: "18.3.1", DependencyCount: 2, MeanScore: 5.5}
+	svc.On("GetProjectSummary", mock.Anything, "react", "18.3.1").Return(resp, nil).Once()
+
+	rr := doJSON(t, h, http.MethodPost, "/api/v1/dependencies/summary", body)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var got depsmanager.RiskSummary
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, resp, got)
+	svc.AssertExpectations(t)
+}
+
+func TestProjectSummary_Validation(t *testing.T) {
+	h, _ := setup(t)
+	body := depsmanager.ProjectRequest{ProjectName: "react"}
+	rr := doJSON(t, h, http.MethodPost, "/api/v1/dependencies/summary", body)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestProjectSummary_NotFound(t *testing.T) {
+	h, svc := setup(t)
+	body := depsmanager.ProjectRequest{ProjectName: "missing", Version: "1.0.0"}
+	svc.On("GetProjectSummary", mock.Anything, "missing", "1.0.0").
+		Return(depsmanager.RiskSummary{}, depsmanager.ErrProjectNotFound).Once()
+
+	rr := doJSON(t, h, http.MethodPost, "/api/v1/dependencies/summary", body)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestPortfolioSummary_Success(t *testing.T) {
+	h, svc := setup(t)
+	resp := depsmanager.RiskSummary{DependencyCount: 10, UnscoredCount: 2}
+	svc.On("GetPortfolioSummary", mock.Anything, mock.Anything).Return(resp, nil).Once()
+
+	rr := doJSON(t, h, http.MethodGet, "/api/v1/dependencies/summary", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var got depsmanager.RiskSummary
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, resp, got)
+	svc.AssertExpectations(t)
+}
+
+func TestPortfolioSummary_InternalError(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("GetPortfolioSummary", mock.Anything, mock.Anything).Return(depsmanager.RiskSummary{}, errors.New("db failure")).Once()
+
+	rr := doJSON(t, h, http.MethodGet, "/api/v1/dependencies/summary", nil)
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+// --- Problem details ---
+
+func TestModifyDependency_DependencyNotFound(t *testing.T) {
+	h, svc := setup(t)
+	body := depsmanager.DependencyRequest{ProjectName: "proj", Version: "1.0.0", DependencyName: "missing", Score: 1}
+	svc.On("UpdateDependency", mock.Anything, "proj", "1.0.0", mock.AnythingOfType("depsmanager.Dependency")).
+		Return(depsmanager.ErrDependencyNotFound).Once()
+
+	rr := doJSON(t, h, http.MethodPatch, "/api/v1/dependencies/modify", body)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	var p customErr.Problem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p))
+	assert.Equal(t, customErr.CodeDependencyNotFound, p.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestDeleteDependency_DependencyNotFound(t *testing.T) {
+	h, svc := setup(t)
+	body := depsmanager.RemoveDependencyRequest{ProjectName: "proj", Version: "1.0.0", DependencyName: "missing"}
+	svc.On("DeleteDependency", mock.Anything, "proj", "1.0.0", "missing").
+		Return(depsmanager.ErrDependencyNotFound).Once()
+
+	rr := doJSON(t, h, http.MethodDelete, "/api/v1/dependencies/delete", body)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestAPI_ProblemDetails_Validation(t *testing.T) {
+	h, _ := setup(t)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/dependencies/new", bytes.NewBufferString(`{"project_name":"proj"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(requestid.Header, "req-42")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, customErr.ContentTypeProblem, rr.Header().Get("Content-Type"))
+	assert.Equal(t, "req-42", rr.Header().Get(requestid.Header))
+
+	var p customErr.Problem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p))
+	assert.Equal(t, customErr.CodeValidationFailed, p.Code)
+	assert.Equal(t, "req-42", p.RequestID)
+	assert.Equal(t, []customErr.FieldError{
+		{Field: "Version", Message: "is required"},
+		{Field: "dependency_name", Message: "is required"},
+	}, p.Errors)
+}
+
+func TestAPI_ProblemDetails_InternalHidesDetail(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("ListProjects", mock.Anything, mock.Anything).Return(nil, errors.New("database is locked")).Once()
+
+	rr := doJSON(t, h, http.MethodGet, "/api/v1/projects", nil)
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	var p customErr.Problem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p))
+	assert.Equal(t, customErr.CodeInternal, p.Code)
+	assert.Empty(t, p.Detail)
+	assert.NotEmpty(t, p.RequestID)
+	svc.AssertExpectations(t)
+}
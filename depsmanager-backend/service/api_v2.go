@@ -0,0 +1,558 @@
+package service
+
+import (
+	"depsmanager"
+	customErr "depsmanager/pkg/errors"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// v2Routes registers resource-oriented routes. Project and dependency names are path segments,
+// scoped names must be escaped, e.g. /projects/%40babel%2Fcore/versions/7.24.0/dependencies.
+// With auth enabled reading requires reader role, changes editor role, api keys, tenants and audit admin role.
+func (a *API) v2Routes(r chi.Router) {
+	editor := a.requireRole(depsmanager.RoleEditor)
+	admin := a.requireRole(depsmanager.RoleAdmin)
+
+	r.Get("/projects", customErr.HandleError(a.ListProjects))
+	r.Get("/projects/{name}/versions", customErr.HandleError(a.V2ProjectVersions))
+	r.Get("/projects/{name}/metadata", customErr.HandleError(a.GetProjectMetadata))
+	r.With(editor).Put("/projects/{name}/metadata", customErr.HandleError(a.SetProjectMetadata))
+	r.With(editor).Delete("/projects/{name}/metadata", customErr.HandleError(a.DeleteProjectMetadata))
+	r.Route("/projects/{name}/versions/{version}", func(r chi.Router) {
+		r.With(editor).Put("/", customErr.HandleError(a.V2FetchProject))
+		r.With(editor).Delete("/", customErr.HandleError(a.V2DeleteProject))
+		r.Get("/dependencies", customErr.HandleError(a.V2ListDependencies))
+		r.With(editor).Put("/dependencies/{dependency}", customErr.HandleError(a.V2PutDependency))
+		r.With(editor).Delete("/dependencies/{dependency}", customErr.HandleError(a.V2DeleteDependency))
+		r.With(editor).Delete("/dependencies/{dependency}/override", customErr.HandleError(a.V2ClearScoreOverride))
+		r.Get("/summary", customErr.HandleError(a.V2ProjectSummary))
+		r.With(editor).Post("/policy-evaluation", customErr.HandleError(a.V2EvaluatePolicies))
+		r.Get("/policy-evaluation", customErr.HandleError(a.V2PolicyEvaluation))
+	})
+
+	r.Get("/dependencies", customErr.HandleError(a.V2DependenciesByScore))
+	r.Get("/dependencies/{dependency}/projects", customErr.HandleError(a.V2ProjectsByDependency))
+	r.Get("/summary", customErr.HandleError(a.PortfolioSummary))
+
+	r.With(editor).Post("/policies", customErr.HandleError(a.SavePolicy))
+	r.Get("/policies", customErr.HandleError(a.ListPolicies))
+	r.With(editor).Delete("/policies/{policy}", customErr.HandleError(a.V2DeletePolicy))
+
+	r.Post("/gate", customErr.HandleError(a.Gate))
+
+	r.With(editor).Post("/suppressions", customErr.HandleError(a.CreateSuppression))
+	r.Get("/suppressions", customErr.HandleError(a.ListSuppressions))
+	r.With(editor).Delete("/suppressions/{id}", customErr.HandleError(a.DeleteSuppression))
+
+	r.Route("/keys", func(r chi.Router) {
+		r.Use(admin)
+		r.Post("/", customErr.HandleError(a.CreateAPIKey))
+		r.Get("/", customErr.HandleError(a.ListAPIKeys))
+		r.Delete("/{id}", customErr.HandleError(a.RevokeAPIKey))
+	})
+	r.Route("/tenants", func(r chi.Router) {
+		r.Use(admin)
+		r.Post("/", customErr.HandleError(a.CreateTenant))
+		r.Get("/", customErr.HandleError(a.ListTenants))
+	})
+	r.Route("/audit", func(r chi.Router) {
+		r.Use(admin)
+		r.Get("/", customErr.HandleError(a.ListAudit))
+		r.Get("/export", customErr.HandleError(a.ExportAudit))
+	})
+}
+
+// V2ProjectVersions
+// @summary V2ProjectVersions
+// @description List all versions of project, by using deps.dev API.
+// @tags v2
+// @param name path string true "project name, escaped"
+// @failure 500 "internal error"
+// @failure 404 "not found project"
+// @failure 400 "invalid path"
+// @Success 200 {object} []string "versions"
+// @Router /v2/projects/{name}/versions [get]
+func (a *API) V2ProjectVersions(w http.ResponseWriter, r *http.Request) error {
+	name, err := pathParam(r, "name")
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	versions, err := a.service.ListProjectVersions(r.Context(), name)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.ListProjectVersions: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(versions); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// V2FetchProject
+// @summary V2FetchProject
+// @description Fetch dependencies of project version from deps.dev and store them, existing dependencies are replaced.
+// @tags v2
+// @param name path string true "project name, escaped"
+// @param version path string true "project version"
+// @failure 500 "internal error"
+// @failure 404 "not found project"
+// @failure 400 "invalid path"
+// @Success 204 "fetched successfully"
+// @Router /v2/projects/{name}/versions/{version} [put]
+func (a *API) V2FetchProject(w http.ResponseWriter, r *http.Request) error {
+	req, err := projectFromPath(r)
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	if err := a.service.FetchAndStoreProjectDependencies(r.Context(), req.ProjectName, req.Version); err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.FetchAndStoreProjectDependencies: %w", err))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// V2DeleteProject
+// @summary V2DeleteProject
+// @description Delete project version with all its dependencies.
+// @tags v2
+// @param name path string true "project name, escaped"
+// @param version path string true "project version"
+// @failure 500 "internal error"
+// @failure 404 "not found project"
+// @failure 400 "invalid path"
+// @Success 204 "deleted successfully"
+// @Router /v2/projects/{name}/versions/{version} [delete]
+func (a *API) V2DeleteProject(w http.ResponseWriter, r *http.Request) error {
+	req, err := projectFromPath(r)
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	if err := a.service.DeleteProject(r.Context(), req.ProjectName, req.Version); err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.DeleteProject: %w", err))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// V2ListDependencies
+// @summary V2ListDependencies
+// @description List stored dependencies of project version.
+// @tags v2
+// @param name path string true "project name, escaped"
+// @param version path string true "project version"
+// @failure 500 "internal error"
+// @failure 404 "not found project"
+// @failure 400 "invalid path"
+// @Success 200 {object} depsmanager.ListDependenciesResponse "project dependencies"
+// @Router /v2/projects/{name}/versions/{version}/dependencies [get]
+func (a *API) V2ListDependencies(w http.ResponseWriter, r *http.Request) error {
+	req, err := projectFromPath(r)
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	resp, err := a.service.ListDependencies(r.Context(), req.ProjectName, req.Version)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.ListDependencies: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// V2PutDependency
+// @summary V2PutDependency
+// @description Add manual dependency to project version or update its score when it already exists.
+// @description Score of fetched dependency is pinned by an override until expires_at or until it is cleared.
+// @tags v2
+// @accept json
+// @param name path string true "project name, escaped"
+// @param version path string true "project version"
+// @param dependency path string true "dependency name, escaped"
+// @param request r.body body depsmanager.PutDependencyRequest true "request body"
+// @failure 500 "internal error"
+// @failure 404 "not found project"
+// @failure 400 "invalid path / cannot decode body / score out of range / expires_at in the past"
+// @Success 201 "created"
+// @Success 200 "updated"
+// @Router /v2/projects/{name}/versions/{version}/dependencies/{dependency} [put]
+func (a *API) V2PutDependency(w http.ResponseWriter, r *http.Request) error {
+	req, err := projectFromPath(r)
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+	depName, err := pathParam(r, "dependency")
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	var body depsmanager.PutDependencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return customErr.NewBadRequest(fmt.Errorf("json.NewDecoder(r.Body).Decode(&body): %w", err))
+	}
+
+	var v customErr.ValidationError
+	if body.Score < 0 || body.Score > 10 {
+		v.Add("score", "must be between 0 and 10")
+	}
+	override := scoreOverride(&v, body.ExpiresAt)
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	created, err := a.service.UpsertDependency(r.Context(), req.ProjectName, req.Version, depsmanager.Dependency{
+		Score:    body.Score,
+		Name:     depName,
+		Override: override,
+	})
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.UpsertDependency: %w", err))
+	}
+
+	if created {
+		w.WriteHeader(http.StatusCreated)
+		return nil
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// V2DeleteDependency
+// @summary V2DeleteDependency
+// @description Delete dependency of project version.
+// @tags v2
+// @param name path string true "project name, escaped"
+// @param version path string true "project version"
+// @param dependency path string true "dependency name, escaped"
+// @failure 500 "internal error"
+// @failure 404 "not found project / not found dependency"
+// @failure 400 "invalid path"
+// @Success 204 "deleted"
+// @Router /v2/projects/{name}/versions/{version}/dependencies/{dependency} [delete]
+func (a *API) V2DeleteDependency(w http.ResponseWriter, r *http.Request) error {
+	req, err := projectFromPath(r)
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+	depName, err := pathParam(r, "dependency")
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	if err := a.service.DeleteDependency(r.Context(), req.ProjectName, req.Version, depName); err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) || errors.Is(err, depsmanager.ErrDependencyNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.DeleteDependency: %w", err))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// V2ClearScoreOverride
+// @summary V2ClearScoreOverride
+// @description Clear score override of fetched dependency, its score is the upstream one again.
+// @tags v2
+// @param name path string true "project name, escaped"
+// @param version path string true "project version"
+// @param dependency path string true "dependency name, escaped"
+// @failure 500 "internal error"
+// @failure 404 "not found project / not found dependency / no active override"
+// @failure 400 "invalid path"
+// @Success 204 "cleared"
+// @Router /v2/projects/{name}/versions/{version}/dependencies/{dependency}/override [delete]
+func (a *API) V2ClearScoreOverride(w http.ResponseWriter, r *http.Request) error {
+	req, err := projectFromPath(r)
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+	depName, err := pathParam(r, "dependency")
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	if err := a.service.ClearScoreOverride(r.Context(), req.ProjectName, req.Version, depName); err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) || errors.Is(err, depsmanager.ErrDependencyNotFound) ||
+			errors.Is(err, depsmanager.ErrOverrideNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.ClearScoreOverride: %w", err))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// scoreOverride returns override ending at expiresAt, nil when it does not expire.
+func scoreOverride(v *customErr.ValidationError, expiresAt *int64) *depsmanager.ScoreOverride {
+	if expiresAt == nil {
+		return nil
+	}
+	if *expiresAt <= time.Now().Unix() {
+		v.Add("expires_at", "must be in the future")
+	}
+	return &depsmanager.ScoreOverride{ExpiresAt: expiresAt}
+}
+
+// V2ProjectSummary
+// @summary V2ProjectSummary
+// @description Risk summary of dependencies of project version.
+// @tags v2
+// @param name path string true "project name, escaped"
+// @param version path string true "project version"
+// @failure 500 "internal error"
+// @failure 404 "not found project"
+// @failure 400 "invalid path"
+// @Success 200 {object} depsmanager.RiskSummary "project risk summary"
+// @Router /v2/projects/{name}/versions/{version}/summary [get]
+func (a *API) V2ProjectSummary(w http.ResponseWriter, r *http.Request) error {
+	req, err := projectFromPath(r)
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	resp, err := a.service.GetProjectSummary(r.Context(), req.ProjectName, req.Version)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.GetProjectSummary: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// V2EvaluatePolicies
+// @summary V2EvaluatePolicies
+// @description Evaluate global and project policies against project version, the result is stored as the latest evaluation.
+// @tags v2
+// @param name path string true "project name, escaped"
+// @param version path string true "project version"
+// @failure 500 "internal error"
+// @failure 404 "not found project"
+// @failure 400 "invalid path"
+// @Success 200 {object} depsmanager.PolicyEvaluation "verdict with violations"
+// @Router /v2/projects/{name}/versions/{version}/policy-evaluation [post]
+func (a *API) V2EvaluatePolicies(w http.ResponseWriter, r *http.Request) error {
+	req, err := projectFromPath(r)
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	resp, err := a.service.EvaluatePolicies(r.Context(), req.ProjectName, req.Version)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.EvaluatePolicies: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// V2PolicyEvaluation
+// @summary V2PolicyEvaluation
+// @description Get the latest policy evaluation of project version.
+// @tags v2
+// @param name path string true "project name, escaped"
+// @param version path string true "project version"
+// @failure 500 "internal error"
+// @failure 404 "not found project / not evaluated yet"
+// @failure 400 "invalid path"
+// @Success 200 {object} depsmanager.PolicyEvaluation "verdict with violations"
+// @Router /v2/projects/{name}/versions/{version}/policy-evaluation [get]
+func (a *API) V2PolicyEvaluation(w http.ResponseWriter, r *http.Request) error {
+	req, err := projectFromPath(r)
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	resp, err := a.service.GetPolicyEvaluation(r.Context(), req.ProjectName, req.Version)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) || errors.Is(err, depsmanager.ErrPolicyEvaluationNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.GetPolicyEvaluation: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// V2DependenciesByScore
+// @summary V2DependenciesByScore
+// @description List names of dependencies with exactly the given score, in projects matching label selector when given.
+// @tags v2
+// @param score query number true "score"
+// @param selector query string false "label selector, e.g. team=payments,env!=dev"
+// @failure 500 "internal error"
+// @failure 404 "not found dependencies"
+// @failure 400 "score is required / invalid selector"
+// @Success 200 {object} []string "dependency names"
+// @Router /v2/dependencies [get]
+func (a *API) V2DependenciesByScore(w http.ResponseWriter, r *http.Request) error {
+	var v customErr.ValidationError
+	score, err := strconv.ParseFloat(r.URL.Query().Get("score"), 64)
+	if err != nil {
+		v.Add("score", "must be a number")
+	}
+	selector := selectorParam(r, &v)
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	names, err := a.service.GetDependenciesByExactScore(r.Context(), score, selector)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.GetDependenciesByExactScore: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(names); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// V2ProjectsByDependency
+// @summary V2ProjectsByDependency
+// @description List stored projects using dependency, only projects matching label selector when given.
+// @tags v2
+// @param dependency path string true "dependency name, escaped"
+// @param selector query string false "label selector, e.g. team=payments,env!=dev"
+// @failure 500 "internal error"
+// @failure 404 "not found projects"
+// @failure 400 "invalid path / invalid selector"
+// @Success 200 {object} []depsmanager.Project "related projects"
+// @Router /v2/dependencies/{dependency}/projects [get]
+func (a *API) V2ProjectsByDependency(w http.ResponseWriter, r *http.Request) error {
+	depName, err := pathParam(r, "dependency")
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+	var v customErr.ValidationError
+	selector := selectorParam(r, &v)
+	if err := v.Err(); err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	projects, err := a.service.GetProjectsByDependency(r.Context(), depName, selector)
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrProjectNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.GetProjectsByDependency: %w", err))
+	}
+
+	if err := json.NewEncoder(w).Encode(projects); err != nil {
+		return customErr.NewInternal(fmt.Errorf("json.NewEncoder(w).Encode(resp)"))
+	}
+
+	return nil
+}
+
+// V2DeletePolicy
+// @summary V2DeletePolicy
+// @description Delete policy by name, without project_name a global policy is deleted.
+// @tags v2
+// @param policy path string true "policy name"
+// @param project_name query string false "project name"
+// @failure 500 "internal error"
+// @failure 404 "not found policy"
+// @failure 400 "invalid path"
+// @Success 204 "deleted"
+// @Router /v2/policies/{policy} [delete]
+func (a *API) V2DeletePolicy(w http.ResponseWriter, r *http.Request) error {
+	name, err := pathParam(r, "policy")
+	if err != nil {
+		return customErr.NewBadRequest(err)
+	}
+
+	if err := a.service.DeletePolicy(r.Context(), r.URL.Query().Get("project_name"), name); err != nil {
+		if errors.Is(err, depsmanager.ErrPolicyNotFound) {
+			return customErr.NewNotFound(err)
+		}
+		return customErr.NewInternal(fmt.Errorf("service.DeletePolicy: %w", err))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// pathParam returns unescaped URL parameter. chi matches routes against the escaped path
+// when it differs from the decoded one (e.g. %2F in @babel%2Fcore), parameters are escaped then.
+func pathParam(r *http.Request, key string) (string, error) {
+	value := chi.URLParam(r, key)
+	if r.URL.RawPath != "" {
+		unescaped, err := url.PathUnescape(value)
+		if err != nil {
+			return "", fmt.Errorf("url.PathUnescape(%s): %w", key, err)
+		}
+		value = unescaped
+	}
+
+	var v customErr.ValidationError
+	v.Required(key, value)
+	if err := v.Err(); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+func projectFromPath(r *http.Request) (depsmanager.ProjectRequest, error) {
+	name, err := pathParam(r, "name")
+	if err != nil {
+		return depsmanager.ProjectRequest{}, err
+	}
+	version, err := pathParam(r, "version")
+	if err != nil {
+		return depsmanager.ProjectRequest{}, err
+	}
+	return depsmanager.ProjectRequest{ProjectName: name, Version: version}, nil
+}
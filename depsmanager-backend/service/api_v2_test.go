@@ -0,0 +1,146 @@
+package service
+
+import (
+	"depsmanager"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestV1_DeprecationHeaders(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("ListProjects", mock.Anything, mock.Anything).Return([]depsmanager.Project{}, nil).Once()
+
+	rr := doJSON(t, h, http.MethodGet, "/api/v1/projects/", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, fmt.Sprintf("@%d", v1DeprecatedAt.Unix()), rr.Header().Get("Deprecation"))
+	assert.Equal(t, `</api/v2>; rel="successor-version"`, rr.Header().Get("Link"))
+}
+
+func TestV2_NoDeprecationHeaders(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("ListProjects", mock.Anything, mock.Anything).Return([]depsmanager.Project{}, nil).Once()
+
+	rr := doJSON(t, h, http.MethodGet, "/api/v2/projects", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Deprecation"))
+}
+
+func TestV2ListDependencies_ScopedName(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("ListDependencies", mock.Anything, "@babel/core", "7.24.0").
+		Return(depsmanager.ListDependenciesResponse{}, nil).Once()
+
+	path := "/api/v2/projects/" + url.PathEscape("@babel/core") + "/versions/7.24.0/dependencies"
+	rr := doJSON(t, h, http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestV2ListDependencies_NotFound(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("ListDependencies", mock.Anything, "react", "1.0.0").
+		Return(depsmanager.ListDependenciesResponse{}, depsmanager.ErrProjectNotFound).Once()
+
+	rr := doJSON(t, h, http.MethodGet, "/api/v2/projects/react/versions/1.0.0/dependencies", nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestV2PutDependency_CreatedAndUpdated(t *testing.T) {
+	h, svc := setup(t)
+	dep := depsmanager.Dependency{Name: "@types/node", Score: 6.5}
+	svc.On("UpsertDependency", mock.Anything, "@babel/core", "7.24.0", dep).Return(true, nil).Once()
+	svc.On("UpsertDependency", mock.Anything, "@babel/core", "7.24.0", dep).Return(false, nil).Once()
+
+	path := "/api/v2/projects/%40babel%2Fcore/versions/7.24.0/dependencies/%40types%2Fnode"
+	body := depsmanager.PutDependencyRequest{Score: 6.5}
+
+	rr := doJSON(t, h, http.MethodPut, path, body)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	rr = doJSON(t, h, http.MethodPut, path, body)
+	require.Equal(t, http.StatusOK, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestV2PutDependency_InvalidScore(t *testing.T) {
+	h, _ := setup(t)
+	rr := doJSON(t, h, http.MethodPut, "/api/v2/projects/react/versions/1.0.0/dependencies/lodash",
+		depsmanager.PutDependencyRequest{Score: 11})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"score"`)
+}
+
+func TestV2DeleteDependency(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("DeleteDependency", mock.Anything, "react", "1.0.0", "@babel/core").Return(nil).Once()
+	svc.On("DeleteDependency", mock.Anything, "react", "1.0.0", "missing").
+		Return(depsmanager.ErrDependencyNotFound).Once()
+
+	rr := doJSON(t, h, http.MethodDelete, "/api/v2/projects/react/versions/1.0.0/dependencies/%40babel%2Fcore", nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	rr = doJSON(t, h, http.MethodDelete, "/api/v2/projects/react/versions/1.0.0/dependencies/missing", nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestV2PutDependency_OverrideExpiry(t *testing.T) {
+	h, svc := setup(t)
+	expiresAt := time.Now().Add(time.Hour).Unix()
+	svc.On("UpsertDependency", mock.Anything, "react", "1.0.0", depsmanager.Dependency{
+		Name: "lodash", Score: 3, Override: &depsmanager.ScoreOverride{ExpiresAt: &expiresAt},
+	}).Return(false, nil).Once()
+
+	path := "/api/v2/projects/react/versions/1.0.0/dependencies/lodash"
+	rr := doJSON(t, h, http.MethodPut, path, depsmanager.PutDependencyRequest{Score: 3, ExpiresAt: &expiresAt})
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	past := time.Now().Add(-time.Hour).Unix()
+	rr = doJSON(t, h, http.MethodPut, path, depsmanager.PutDependencyRequest{Score: 3, ExpiresAt: &past})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"field":"expires_at"`)
+	svc.AssertExpectations(t)
+}
+
+func TestV2ClearScoreOverride(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("ClearScoreOverride", mock.Anything, "react", "1.0.0", "@babel/core").Return(nil).Once()
+	svc.On("ClearScoreOverride", mock.Anything, "react", "1.0.0", "lodash").
+		Return(depsmanager.ErrOverrideNotFound).Once()
+
+	rr := doJSON(t, h, http.MethodDelete, "/api/v2/projects/react/versions/1.0.0/dependencies/%40babel%2Fcore/override", nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	rr = doJSON(t, h, http.MethodDelete, "/api/v2/projects/react/versions/1.0.0/dependencies/lodash/override", nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"code":"override_not_found"`)
+	svc.AssertExpectations(t)
+}
+
+func TestV2FetchProject(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("FetchAndStoreProjectDependencies", mock.Anything, "react", "18.3.1").Return(nil).Once()
+
+	rr := doJSON(t, h, http.MethodPut, "/api/v2/projects/react/versions/18.3.1", nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestV2ProjectsByDependency_ScopedName(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("GetProjectsByDependency", mock.Anything, "@babel/core", mock.Anything).
+		Return([]depsmanager.Project{{Name: "app", Version: "1.0.0"}}, nil).Once()
+
+	rr := doJSON(t, h, http.MethodGet, "/api/v2/dependencies/%40babel%2Fcore/projects", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"app"`)
+}
+
+func TestV2DependenciesByScore_InvalidScore(t *testing.T) {
+	h, _ := setup(t)
+	rr := doJSON(t, h, http.MethodGet, "/api/v2/dependencies?score=abc", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"fmt"
+)
+
+// ListAudit returns entries matching filter, newest first. Zero filter.Limit returns every entry,
+// otherwise NextBeforeID is set when the page is full.
+func (s *service) ListAudit(ctx context.Context, filter depsmanager.AuditFilter) (depsmanager.AuditPage, error) {
+	entries, err := s.storage.ListAudit(ctx, filter)
+	if err != nil {
+		return depsmanager.AuditPage{}, fmt.Errorf("s.storage.ListAudit: %w", err)
+	}
+
+	page := depsmanager.AuditPage{Entries: entries}
+	if filter.Limit > 0 && len(entries) == filter.Limit {
+		next := entries[len(entries)-1].ID
+		page.NextBeforeID = &next
+	}
+	return page, nil
+}
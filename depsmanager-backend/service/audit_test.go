@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ListAudit_NextBeforeID(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+	entries := []depsmanager.AuditEntry{{ID: 9}, {ID: 4}}
+
+	st.On("ListAudit", ctx, depsmanager.AuditFilter{Limit: 2}).Return(entries, nil).Once()
+	st.On("ListAudit", ctx, depsmanager.AuditFilter{Limit: 3}).Return(entries, nil).Once()
+	st.On("ListAudit", ctx, depsmanager.AuditFilter{}).Return(entries, nil).Once()
+
+	page, err := s.ListAudit(ctx, depsmanager.AuditFilter{Limit: 2})
+	require.NoError(t, err)
+	require.NotNil(t, page.NextBeforeID)
+	assert.Equal(t, int64(4), *page.NextBeforeID)
+
+	// last page and unlimited export have no next page
+	page, err = s.ListAudit(ctx, depsmanager.AuditFilter{Limit: 3})
+	require.NoError(t, err)
+	assert.Nil(t, page.NextBeforeID)
+	page, err = s.ListAudit(ctx, depsmanager.AuditFilter{})
+	require.NoError(t, err)
+	assert.Nil(t, page.NextBeforeID)
+	st.AssertExpectations(t)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"depsmanager"
+	"depsmanager/pkg/auth"
+	"depsmanager/pkg/tenant"
+	"errors"
+	"fmt"
+)
+
+// bootstrapIdentity is the admin authenticated by the key from config.
+const bootstrapIdentity = "bootstrap"
+
+// WithBootstrapKey accepts key as admin key without storing it, it is meant to create the first keys.
+func WithBootstrapKey(key string) func(s *service) {
+	return func(s *service) {
+		s.bootstrapKey = key
+	}
+}
+
+// Authenticate returns identity of key, depsmanager.ErrUnauthorized when the key is unknown or revoked.
+func (s *service) Authenticate(ctx context.Context, key string) (depsmanager.Identity, error) {
+	if key == "" {
+		return depsmanager.Identity{}, depsmanager.ErrUnauthorized
+	}
+	if s.bootstrapKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(s.bootstrapKey)) == 1 {
+		return depsmanager.Identity{Name: bootstrapIdentity, Role: depsmanager.RoleAdmin, Tenant: tenant.Default}, nil
+	}
+
+	k, err := s.storage.GetActiveAPIKey(ctx, auth.Hash(key))
+	if err != nil {
+		if errors.Is(err, depsmanager.ErrAPIKeyNotFound) {
+			return depsmanager.Identity{}, depsmanager.ErrUnauthorized
+		}
+		return depsmanager.Identity{}, fmt.Errorf("s.storage.GetActiveAPIKey: %w", err)
+	}
+
+	return depsmanager.Identity{Name: k.Name, Role: k.Role, Tenant: k.Tenant, KeyID: k.ID}, nil
+}
+
+// CreateAPIKey generates a new key in tenantName, only its hash is stored.
+// Empty tenantName creates the key in tenant of ctx.
+func (s *service) CreateAPIKey(ctx context.Context, name string, role depsmanager.Role, tenantName string) (depsmanager.CreatedAPIKey, error) {
+	if tenantName == "" {
+		tenantName = tenant.FromContext(ctx)
+	}
+	if tenantName == tenant.All {
+		return depsmanager.CreatedAPIKey{}, depsmanager.ErrTenantRequired
+	}
+	if _, err := s.GetTenant(ctx, tenantName); err != nil {
+		return depsmanager.CreatedAPIKey{}, err
+	}
+
+	key, err := auth.GenerateKey()
+	if err != nil {
+		return depsmanager.CreatedAPIKey{}, fmt.Errorf("auth.GenerateKey: %w", err)
+	}
+
+	stored, err := s.storage.CreateAPIKey(ctx, depsmanager.APIKey{
+		Tenant:    tenantName,
+		Name:      name,
+		Role:      role,
+		Prefix:    auth.Prefix(key),
+		CreatedAt: s.tNow().Unix(),
+		KeyHash:   auth.Hash(key),
+	})
+	if err != nil {
+		return depsmanager.CreatedAPIKey{}, fmt.Errorf("s.storage.CreateAPIKey: %w", err)
+	}
+
+	return depsmanager.CreatedAPIKey{APIKey: stored, Key: key}, nil
+}
+
+func (s *service) ListAPIKeys(ctx context.Context) ([]depsmanager.APIKey, error) {
+	keys, err := s.storage.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.storage.ListAPIKeys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *service) RevokeAPIKey(ctx context.Context, id int64) error {
+	if err := s.storage.RevokeAPIKey(ctx, id, s.tNow().Unix()); err != nil {
+		return fmt.Errorf("s.storage.RevokeAPIKey: %w", err)
+	}
+	return nil
+}
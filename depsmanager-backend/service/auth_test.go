@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/auth"
+	"depsmanager/pkg/tenant"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Authenticate_BootstrapKey(t *testing.T) {
+	s, st, _ := newSvc(t)
+	WithBootstrapKey("dm_bootstrap")(s)
+
+	id, err := s.Authenticate(context.Background(), "dm_bootstrap")
+	require.NoError(t, err)
+	assert.Equal(t, depsmanager.Identity{Name: "bootstrap", Role: depsmanager.RoleAdmin, Tenant: tenant.Default}, id)
+	st.AssertNotCalled(t, "GetActiveAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestService_Authenticate_StoredKey(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	st.On("GetActiveAPIKey", ctx, auth.Hash("dm_ci")).
+		Return(depsmanager.APIKey{ID: 1, Name: "ci", Role: depsmanager.RoleReader}, nil).Once()
+	st.On("GetActiveAPIKey", ctx, auth.Hash("dm_revoked")).
+		Return(depsmanager.APIKey{}, depsmanager.ErrAPIKeyNotFound).Once()
+	st.On("GetActiveAPIKey", ctx, auth.Hash("dm_db")).
+		Return(depsmanager.APIKey{}, errors.New("db error")).Once()
+
+	id, err := s.Authenticate(ctx, "dm_ci")
+	require.NoError(t, err)
+	assert.Equal(t, depsmanager.Identity{Name: "ci", Role: depsmanager.RoleReader, KeyID: 1}, id)
+
+	_, err = s.Authenticate(ctx, "dm_revoked")
+	require.ErrorIs(t, err, depsmanager.ErrUnauthorized)
+
+	_, err = s.Authenticate(ctx, "")
+	require.ErrorIs(t, err, depsmanager.ErrUnauthorized)
+
+	_, err = s.Authenticate(ctx, "dm_db")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, depsmanager.ErrUnauthorized)
+	st.AssertExpectations(t)
+}
+
+func TestService_CreateAPIKey_StoresHashOnly(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	var stored depsmanager.APIKey
+	st.On("GetTenant", ctx, "default").Return(depsmanager.Tenant{Name: "default"}, nil).Once()
+	st.On("CreateAPIKey", ctx, mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(depsmanager.APIKey) }).
+		Return(func(_ context.Context, k depsmanager.APIKey) depsmanager.APIKey { k.ID = 7; return k }, nil).Once()
+
+	created, err := s.CreateAPIKey(ctx, "ci", depsmanager.RoleEditor, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), created.ID)
+	assert.Equal(t, "default", stored.Tenant)
+	assert.Equal(t, auth.Hash(created.Key), stored.KeyHash)
+	assert.Equal(t, auth.Prefix(created.Key), stored.Prefix)
+	assert.Equal(t, fixedNow().Unix(), stored.CreatedAt)
+	assert.NotContains(t, stored.KeyHash, created.Key)
+	st.AssertExpectations(t)
+}
+
+func TestService_RevokeAPIKey(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	st.On("RevokeAPIKey", ctx, int64(1), fixedNow().Unix()).Return(nil).Once()
+	st.On("RevokeAPIKey", ctx, int64(2), fixedNow().Unix()).Return(depsmanager.ErrAPIKeyNotFound).Once()
+
+	require.NoError(t, s.RevokeAPIKey(ctx, 1))
+	require.ErrorIs(t, s.RevokeAPIKey(ctx, 2), depsmanager.ErrAPIKeyNotFound)
+	st.AssertExpectations(t)
+}
+
+func TestService_CreateAPIKey_Tenant(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := tenant.NewContext(context.Background(), tenant.All)
+
+	_, err := s.CreateAPIKey(ctx, "ci", depsmanager.RoleReader, "")
+	require.ErrorIs(t, err, depsmanager.ErrTenantRequired)
+
+	st.On("GetTenant", ctx, "missing").Return(depsmanager.Tenant{}, depsmanager.ErrTenantNotFound).Once()
+	_, err = s.CreateAPIKey(ctx, "ci", depsmanager.RoleReader, "missing")
+	require.ErrorIs(t, err, depsmanager.ErrTenantNotFound)
+	st.AssertExpectations(t)
+}
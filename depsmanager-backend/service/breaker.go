@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// BreakerState of CircuitBreaker, the value is exported as depsmanager_depsclient_circuit_state.
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerHalfOpen:
+		return "half_open"
+	case BreakerOpen:
+		return "open"
+	}
+	return "closed"
+}
+
+// CircuitBreaker is a DepsClient failing fast with depsmanager.UpstreamUnavailableError while deps.dev keeps failing,
+// so callers do not wait for timeouts of a degraded upstream. See depsmanager.DepsBreaker for the transitions.
+type CircuitBreaker struct {
+	next DepsClient
+	conf depsmanager.DepsBreaker
+	m    *Metrics
+	now  func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	// probes in flight and succeeded while half-open
+	probes    int
+	succeeded int
+}
+
+// NewCircuitBreaker wraps next, a disabled conf (Failures 0) never opens. m may be nil.
+func NewCircuitBreaker(next DepsClient, conf depsmanager.DepsBreaker, m *Metrics, now func() time.Time) *CircuitBreaker {
+	if m != nil {
+		m.breakerState.Set(float64(BreakerClosed))
+	}
+	return &CircuitBreaker{next: next, conf: conf, m: m, now: now}
+}
+
+// State returns the current state, an open breaker past its timeout reports half-open.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && b.retryAfter() <= 0 {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Check is a readiness check failing while the breaker is open.
+func (b *CircuitBreaker) Check(context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen {
+		if retryAfter := b.retryAfter(); retryAfter > 0 {
+			return &depsmanager.UpstreamUnavailableError{RetryAfter: retryAfter}
+		}
+	}
+	return nil
+}
+
+// FailFast rejects a call while the breaker is open without reserving it, for callers deciding whether to wait
+// for the breaker at all, see LimitDepsClient. Calls passing it reserve their probe when they reach the breaker.
+func (b *CircuitBreaker) FailFast(context.Context) error {
+	if b.conf.Failures <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen {
+		if retryAfter := b.retryAfter(); retryAfter > 0 {
+			b.m.breakerRejected()
+			return &depsmanager.UpstreamUnavailableError{RetryAfter: retryAfter}
+		}
+	}
+	return nil
+}
+
+func (b *CircuitBreaker) retryAfter() time.Duration {
+	return b.openedAt.Add(b.conf.OpenTimeout).Sub(b.now())
+}
+
+// allow reserves a call or returns the error of a rejected one.
+func (b *CircuitBreaker) allow() error {
+	if b.conf.Failures <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		if retryAfter := b.retryAfter(); retryAfter > 0 {
+			b.m.breakerRejected()
+			return &depsmanager.UpstreamUnavailableError{RetryAfter: retryAfter}
+		}
+		b.transition(BreakerHalfOpen)
+	}
+	if b.state == BreakerHalfOpen {
+		if b.probes+b.succeeded >= b.conf.HalfOpenProbes {
+			b.m.breakerRejected()
+			// the probes answer soon, one timeout is the most to wait
+			return &depsmanager.UpstreamUnavailableError{RetryAfter: time.Second}
+		}
+		b.probes++
+	}
+	return nil
+}
+
+// done records result of an allowed call. Not found answers are successes, calls cancelled by the caller
+// say nothing about deps.dev and only release their probe.
+func (b *CircuitBreaker) done(ctx context.Context, err error) {
+	if b.conf.Failures <= 0 {
+		return
+	}
+	cancelled := err != nil && ctx.Err() != nil
+	failed := err != nil && !errors.Is(err, depsmanager.ErrProjectNotFound) && !cancelled
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerClosed:
+		if cancelled {
+			return
+		}
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.conf.Failures {
+			b.transition(BreakerOpen)
+			slog.WarnContext(ctx, "deps.dev circuit opened", "failures", b.failures, "error", err,
+				"open_timeout", b.conf.OpenTimeout.String())
+		}
+	case BreakerHalfOpen:
+		b.probes--
+		if cancelled {
+			return
+		}
+		if failed {
+			b.transition(BreakerOpen)
+			slog.WarnContext(ctx, "deps.dev circuit reopened, probe failed", "error", err)
+			return
+		}
+		b.succeeded++
+		if b.succeeded >= b.conf.HalfOpenProbes {
+			b.transition(BreakerClosed)
+			slog.InfoContext(ctx, "deps.dev circuit closed")
+		}
+	case BreakerOpen:
+		// a call allowed before the breaker opened, its result is stale
+	}
+}
+
+func (b *CircuitBreaker) transition(to BreakerState) {
+	b.state, b.failures, b.probes, b.succeeded = to, 0, 0, 0
+	if to == BreakerOpen {
+		b.openedAt = b.now()
+	}
+	b.m.setBreakerState(to)
+}
+
+func (b *CircuitBreaker) GetProjectVersions(ctx context.Context, project string) (*depsmanager.DepsGetVersionResp, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := b.next.GetProjectVersions(ctx, project)
+	b.done(ctx, err)
+	return resp, err
+}
+
+func (b *CircuitBreaker) GetProjectDependencies(ctx context.Context, system, project, version string) (*depsmanager.DepsProjectDependenciesResp, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := b.next.GetProjectDependencies(ctx, system, project, version)
+	b.done(ctx, err)
+	return resp, err
+}
+
+func (b *CircuitBreaker) GetVersionsBatch(ctx context.Context, projects []depsmanager.ProjectDependencies) (*depsmanager.DepsGetVersionsBatchResp, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := b.next.GetVersionsBatch(ctx, projects)
+	b.done(ctx, err)
+	return resp, err
+}
+
+func (b *CircuitBreaker) GetProjectsBatch(ctx context.Context, projects []string) (*depsmanager.DepsGetProjectBatchResp, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := b.next.GetProjectsBatch(ctx, projects)
+	b.done(ctx, err)
+	return resp, err
+}
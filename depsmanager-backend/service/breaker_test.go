@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/metrics"
+	"depsmanager/service/mocks"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newBreaker(t *testing.T, probes int) (*CircuitBreaker, *mocks.DepsClient, *Metrics, *time.Time) {
+	t.Helper()
+	dc := new(mocks.DepsClient)
+	m := NewMetrics(metrics.NewRegistry())
+	now := fixedNow()
+	b := NewCircuitBreaker(dc, depsmanager.DepsBreaker{Failures: 3, OpenTimeout: 30 * time.Second, HalfOpenProbes: probes},
+		m, func() time.Time { return now })
+	return b, dc, m, &now
+}
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	ctx := context.Background()
+	b, dc, m, now := newBreaker(t, 1)
+	upstreamErr := errors.New("bad response: 503")
+
+	// not found answers and interrupted failure streaks keep it closed
+	dc.On("GetProjectVersions", ctx, "missing").Return(nil, depsmanager.ErrProjectNotFound)
+	dc.On("GetProjectVersions", ctx, "react").Return(nil, upstreamErr).Times(2).
+		On("GetProjectVersions", ctx, "react").Return(&depsmanager.DepsGetVersionResp{}, nil).Once()
+	for range 3 {
+		_, _ = b.GetProjectVersions(ctx, "missing")
+	}
+	for range 3 {
+		_, _ = b.GetProjectVersions(ctx, "react")
+	}
+	assert.Equal(t, BreakerClosed, b.State())
+
+	dc.On("GetProjectDependencies", ctx, SystemNPM, "react", "18.3.1").Return(nil, upstreamErr).Times(3)
+	for range 3 {
+		_, err := b.GetProjectDependencies(ctx, SystemNPM, "react", "18.3.1")
+		require.ErrorIs(t, err, upstreamErr)
+	}
+	require.Equal(t, BreakerOpen, b.State())
+
+	// open fails fast without calling deps.dev, for every method
+	*now = now.Add(10 * time.Second)
+	_, err := b.GetProjectsBatch(ctx, []string{"github.com/facebook/react"})
+	require.ErrorIs(t, err, depsmanager.ErrUpstreamUnavailable)
+	var unavailable *depsmanager.UpstreamUnavailableError
+	require.ErrorAs(t, err, &unavailable)
+	assert.Equal(t, 20*time.Second, unavailable.RetryAfter)
+	require.ErrorIs(t, b.Check(ctx), depsmanager.ErrUpstreamUnavailable)
+
+	// half-open probe fails and opens it again
+	*now = now.Add(20 * time.Second)
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	require.NoError(t, b.Check(ctx))
+	dc.On("GetVersionsBatch", ctx, mock.Anything).Return(nil, upstreamErr).Once()
+	_, err = b.GetVersionsBatch(ctx, nil)
+	require.ErrorIs(t, err, upstreamErr)
+	require.Equal(t, BreakerOpen, b.State())
+
+	// successful probe closes it
+	*now = now.Add(30 * time.Second)
+	dc.On("GetVersionsBatch", ctx, mock.Anything).Return(&depsmanager.DepsGetVersionsBatchResp{}, nil).Once()
+	_, err = b.GetVersionsBatch(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, BreakerClosed, b.State())
+	dc.AssertExpectations(t)
+
+	body := doJSON(t, m.Handler(), http.MethodGet, "/metrics", nil).Body.String()
+	assert.Contains(t, body, "depsmanager_depsclient_circuit_state 0\n")
+	assert.Contains(t, body, "depsmanager_depsclient_circuit_rejections_total 1\n")
+	assert.Contains(t, body, `depsmanager_depsclient_circuit_transitions_total{state="open"} 2`)
+	assert.Contains(t, body, `depsmanager_depsclient_circuit_transitions_total{state="half_open"} 2`)
+	assert.Contains(t, body, `depsmanager_depsclient_circuit_transitions_total{state="closed"} 1`)
+}
+
+func TestCircuitBreaker_HalfOpenProbes(t *testing.T) {
+	ctx := context.Background()
+	b, dc, _, now := newBreaker(t, 2)
+	dc.On("GetProjectVersions", ctx, "react").Return(nil, errors.New("timeout")).Times(3)
+	for range 3 {
+		_, _ = b.GetProjectVersions(ctx, "react")
+	}
+	*now = now.Add(time.Minute)
+
+	// two probes run at once, a third call is rejected until they answer
+	release := make(chan time.Time)
+	dc.On("GetProjectVersions", ctx, "vue").WaitUntil(release).Return(&depsmanager.DepsGetVersionResp{}, nil).Twice()
+	done := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, err := b.GetProjectVersions(ctx, "vue")
+			done <- err
+		}()
+	}
+	require.Eventually(t, func() bool {
+		_, err := b.GetProjectVersions(ctx, "svelte")
+		return errors.Is(err, depsmanager.ErrUpstreamUnavailable)
+	}, time.Second, time.Millisecond)
+	close(release)
+	require.NoError(t, <-done)
+	require.NoError(t, <-done)
+	assert.Equal(t, BreakerClosed, b.State())
+}
+
+func TestCircuitBreaker_CancelledCallsDoNotCount(t *testing.T) {
+	b, dc, _, _ := newBreaker(t, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dc.On("GetProjectVersions", ctx, "react").Return(nil, context.Canceled).Times(5)
+	for range 5 {
+		_, _ = b.GetProjectVersions(ctx, "react")
+	}
+	assert.Equal(t, BreakerClosed, b.State())
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	ctx := context.Background()
+	dc := new(mocks.DepsClient)
+	b := NewCircuitBreaker(dc, depsmanager.DepsBreaker{}, nil, fixedNow)
+	dc.On("GetProjectVersions", ctx, "react").Return(nil, errors.New("timeout")).Times(10)
+	for range 10 {
+		_, err := b.GetProjectVersions(ctx, "react")
+		require.NotErrorIs(t, err, depsmanager.ErrUpstreamUnavailable)
+	}
+	dc.AssertExpectations(t)
+}
+
+func TestAPI_UpstreamUnavailable(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("FetchAndStoreProjectDependencies", mock.Anything, "react", "18.3.1").
+		Return(&depsmanager.UpstreamUnavailableError{RetryAfter: 1500 * time.Millisecond}).Once()
+
+	rr := doJSON(t, h, http.MethodPut, "/api/v2/projects/react/versions/18.3.1", nil)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code, rr.Body.String())
+	assert.Equal(t, "2", rr.Header().Get("Retry-After"))
+	assert.Contains(t, rr.Body.String(), `"code":"upstream_unavailable"`)
+	assert.Contains(t, rr.Body.String(), "deps.dev unavailable, retry after 2s")
+}
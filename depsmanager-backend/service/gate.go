@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/lockfile"
+	"depsmanager/pkg/policy"
+	"depsmanager/pkg/suppression"
+	"fmt"
+	"strings"
+)
+
+const gatePolicyName = "gate"
+
+func WithGateThresholds(thresholds depsmanager.GateThresholds) func(s *service) {
+	return func(s *service) {
+		s.gateThresholds = thresholds
+	}
+}
+
+// WithConfigPolicies evaluates policies of the config file next to the stored ones.
+func WithConfigPolicies(policies []depsmanager.Policy) func(s *service) {
+	return func(s *service) {
+		s.configPolicies = policies
+	}
+}
+
+// Reload replaces gate thresholds and config policies, e.g. on SIGHUP. Running evaluations keep the old ones.
+func (s *service) Reload(thresholds depsmanager.GateThresholds, policies []depsmanager.Policy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gateThresholds = thresholds
+	s.configPolicies = policies
+}
+
+// EvaluateLockfile scores packages of package-lock.json and checks them against thresholds,
+// nothing is stored. Thresholds missing in req fall back to the service defaults.
+// With a baseline only packages not present in the stored baseline project version, or present there
+// in another version, are checked. Baseline dependencies without a stored version count as unchanged.
+// Suppressed packages are reported with SuppressedBy and are not checked, project scoped suppressions
+// apply only with a baseline.
+func (s *service) EvaluateLockfile(ctx context.Context, lock []byte, req depsmanager.GateRequest) (depsmanager.GateResult, error) {
+	packages, err := lockfile.Parse(lock)
+	if err != nil {
+		return depsmanager.GateResult{}, err
+	}
+
+	result := depsmanager.GateResult{
+		Thresholds: s.mergeGateThresholds(req.Thresholds),
+		Packages:   []depsmanager.GatePackage{},
+	}
+
+	// previous holds baseline versions of changed packages
+	var previous map[string]string
+	if req.BaselineProject != "" {
+		baselineDeps, err := s.storage.ListProjectDependencies(ctx, req.BaselineProject, req.BaselineVersion)
+		if err != nil {
+			return depsmanager.GateResult{}, fmt.Errorf("s.storage.ListProjectDependencies() baseline: %s, error: %w", req.BaselineProject, err)
+		}
+		result.Baseline = &depsmanager.ProjectRequest{ProjectName: req.BaselineProject, Version: req.BaselineVersion}
+
+		baseline := make(map[string]string, len(baselineDeps))
+		for _, d := range baselineDeps {
+			baseline[d.Name] = d.Version
+		}
+		previous = make(map[string]string)
+
+		changed := packages[:0]
+		for _, p := range packages {
+			version, ok := baseline[p.Name]
+			switch {
+			case !ok:
+				changed = append(changed, p)
+			case version != "" && version != p.Version:
+				previous[p.Name] = version
+				changed = append(changed, p)
+			}
+		}
+		packages = changed
+	}
+
+	var deps []depsmanager.Dependency
+	if len(packages) > 0 {
+		active, err := s.activeSuppressions(ctx)
+		if err != nil {
+			return depsmanager.GateResult{}, err
+		}
+
+		projectDependencies := make([]depsmanager.ProjectDependencies, 0, len(packages))
+		relations := make(map[string]string, len(packages))
+		for _, p := range packages {
+			projectDependencies = append(projectDependencies, depsmanager.ProjectDependencies{
+				System:  strings.ToUpper(SystemNPM),
+				Name:    p.Name,
+				Version: p.Version,
+			})
+			relations[p.Name] = p.Relation
+		}
+
+		scores, err := s.scoreDependencies(ctx, projectDependencies, relations)
+		if err != nil {
+			return depsmanager.GateResult{}, fmt.Errorf("s.scoreDependencies(): %w", err)
+		}
+		scored := make(map[string]depsmanager.Dependency, len(scores))
+		for _, d := range scores {
+			scored[d.Name] = d
+		}
+
+		for _, p := range packages {
+			// packages without source repository are checked as unscored
+			d, ok := scored[p.Name]
+			if !ok {
+				d = depsmanager.Dependency{Name: p.Name, Relation: p.Relation}
+			}
+			d.Version, d.ProjectName, d.ProjectVersion = p.Version, req.BaselineProject, req.BaselineVersion
+			d = suppression.Apply(active, []depsmanager.Dependency{d})[0]
+			deps = append(deps, d)
+			change := depsmanager.GateChangeAdded
+			if _, ok := previous[p.Name]; ok {
+				change = depsmanager.GateChangeChanged
+			}
+			result.Packages = append(result.Packages, depsmanager.GatePackage{
+				Name:            p.Name,
+				Version:         p.Version,
+				Relation:        p.Relation,
+				Change:          change,
+				PreviousVersion: previous[p.Name],
+				Score:           d.Score,
+				UpdatedAt:       d.UpdatedAt,
+				SuppressedBy:    d.SuppressedBy,
+			})
+		}
+	}
+
+	result.Violations = policy.Evaluate([]depsmanager.Policy{gatePolicy(result.Thresholds)}, deps, s.tNow())
+	result.Verdict = policy.Verdict(result.Violations)
+	if result.Verdict == depsmanager.VerdictFail {
+		result.ExitCode = 1
+	}
+
+	return result, nil
+}
+
+func (s *service) mergeGateThresholds(t depsmanager.GateThresholds) depsmanager.GateThresholds {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if t.MinScore == nil {
+		t.MinScore = s.gateThresholds.MinScore
+	}
+	if t.MaxUnscored == nil {
+		t.MaxUnscored = s.gateThresholds.MaxUnscored
+	}
+	if t.MaxScorecardAgeDays == nil {
+		t.MaxScorecardAgeDays = s.gateThresholds.MaxScorecardAgeDays
+	}
+	return t
+}
+
+func gatePolicy(t depsmanager.GateThresholds) depsmanager.Policy {
+	p := depsmanager.Policy{Name: gatePolicyName}
+	if t.MinScore != nil {
+		p.Rules = append(p.Rules, depsmanager.PolicyRule{Type: depsmanager.PolicyRuleMinScore, Value: *t.MinScore})
+	}
+	if t.MaxScorecardAgeDays != nil {
+		p.Rules = append(p.Rules, depsmanager.PolicyRule{Type: depsmanager.PolicyRuleMaxScorecardAgeDays, Value: float64(*t.MaxScorecardAgeDays)})
+	}
+	if t.MaxUnscored != nil {
+		p.Rules = append(p.Rules, depsmanager.PolicyRule{Type: depsmanager.PolicyRuleMaxUnscored, Value: float64(*t.MaxUnscored)})
+	}
+	return p
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const gateLockfile = `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"a": "^1.0.0", "b": "^2.0.0"}},
+    "node_modules/a": {"version": "1.0.0"},
+    "node_modules/b": {"version": "2.0.0"},
+    "node_modules/c": {"version": "3.0.0"}
+  }
+}`
+
+func TestService_EvaluateLockfile_OnlyChangedPackagesChecked(t *testing.T) {
+	s, st, dc := newSvc(t)
+	ctx := context.Background()
+	minScore := 5.0
+	maxUnscored := 0
+
+	st.On("ListProjectDependencies", ctx, "app", "1.0.0").Return([]depsmanager.Dependency{
+		{Name: "a", Score: 1, UpdatedAt: fixedNow().Unix()},
+	}, nil).Once()
+	st.On("ListSuppressions", ctx).Return([]depsmanager.Suppression{}, nil).Once()
+
+	versionsJSON := `{"responses":[{"version":{"versionKey":{"name":"b"},"relatedProjects":[{"projectKey":{"id":"repo-b"},"relationType":"SOURCE_REPO"}]}}]}`
+	dc.On("GetVersionsBatch", ctx, []depsmanager.ProjectDependencies{
+		{System: "NPM", Name: "b", Version: "2.0.0"},
+		{System: "NPM", Name: "c", Version: "3.0.0"},
+	}).Return(versionsBatchFromJSON(t, versionsJSON), nil).Once()
+	projectsJSON := `{"responses":[{"project":{"projectKey":{"id":"repo-b"},"scorecard":{"date":"2023-11-01T00:00:00Z","overallScore":3.5}}}]}`
+	dc.On("GetProjectsBatch", ctx, []string{"repo-b"}).Return(projectsBatchFromJSON(t, projectsJSON), nil).Once()
+
+	res, err := s.EvaluateLockfile(ctx, []byte(gateLockfile), depsmanager.GateRequest{
+		BaselineProject: "app",
+		BaselineVersion: "1.0.0",
+		Thresholds:      depsmanager.GateThresholds{MinScore: &minScore, MaxUnscored: &maxUnscored},
+	})
+	require.NoError(t, err)
+	require.Equal(t, depsmanager.VerdictFail, res.Verdict)
+	require.Equal(t, 1, res.ExitCode)
+	require.Equal(t, &depsmanager.ProjectRequest{ProjectName: "app", Version: "1.0.0"}, res.Baseline)
+	require.Equal(t, []depsmanager.GatePackage{
+		{Name: "b", Version: "2.0.0", Relation: depsmanager.RelationDirect, Change: depsmanager.GateChangeAdded, Score: 3.5, UpdatedAt: 1698796800},
+		{Name: "c", Version: "3.0.0", Relation: depsmanager.RelationIndirect, Change: depsmanager.GateChangeAdded},
+	}, res.Packages)
+	require.Len(t, res.Violations, 2)
+	require.Equal(t, depsmanager.PolicyRuleMinScore, res.Violations[0].Rule)
+	require.Equal(t, "b", res.Violations[0].DependencyName)
+	require.Equal(t, depsmanager.PolicyRuleMaxUnscored, res.Violations[1].Rule)
+	st.AssertExpectations(t)
+	dc.AssertExpectations(t)
+}
+
+func TestService_EvaluateLockfile_ChangedVersionChecked(t *testing.T) {
+	s, st, dc := newSvc(t)
+	ctx := context.Background()
+	minScore := 5.0
+
+	// a is bumped from 0.9.0, b and c are unchanged, c has no stored version
+	st.On("ListProjectDependencies", ctx, "app", "1.0.0").Return([]depsmanager.Dependency{
+		{Name: "a", Version: "0.9.0", Score: 8}, {Name: "b", Version: "2.0.0", Score: 8}, {Name: "c"},
+	}, nil).Once()
+	st.On("ListSuppressions", ctx).Return([]depsmanager.Suppression{}, nil).Once()
+
+	versionsJSON := `{"responses":[{"version":{"versionKey":{"name":"a"},"relatedProjects":[{"projectKey":{"id":"repo-a"},"relationType":"SOURCE_REPO"}]}}]}`
+	dc.On("GetVersionsBatch", ctx, []depsmanager.ProjectDependencies{
+		{System: "NPM", Name: "a", Version: "1.0.0"},
+	}).Return(versionsBatchFromJSON(t, versionsJSON), nil).Once()
+	projectsJSON := `{"responses":[{"project":{"projectKey":{"id":"repo-a"},"scorecard":{"date":"2023-11-01T00:00:00Z","overallScore":2}}}]}`
+	dc.On("GetProjectsBatch", ctx, []string{"repo-a"}).Return(projectsBatchFromJSON(t, projectsJSON), nil).Once()
+
+	res, err := s.EvaluateLockfile(ctx, []byte(gateLockfile), depsmanager.GateRequest{
+		BaselineProject: "app",
+		BaselineVersion: "1.0.0",
+		Thresholds:      depsmanager.GateThresholds{MinScore: &minScore},
+	})
+	require.NoError(t, err)
+	require.Equal(t, depsmanager.VerdictFail, res.Verdict)
+	require.Equal(t, []depsmanager.GatePackage{
+		{Name: "a", Version: "1.0.0", Relation: depsmanager.RelationDirect, Change: depsmanager.GateChangeChanged,
+			PreviousVersion: "0.9.0", Score: 2, UpdatedAt: 1698796800},
+	}, res.Packages)
+	require.Len(t, res.Violations, 1)
+	require.Equal(t, depsmanager.PolicyRuleMinScore, res.Violations[0].Rule)
+	require.Equal(t, "a", res.Violations[0].DependencyName)
+	st.AssertExpectations(t)
+	dc.AssertExpectations(t)
+}
+
+func TestService_EvaluateLockfile_SplitsBatches(t *testing.T) {
+	s, st, dc := newSvc(t)
+	ctx := context.Background()
+
+	packages := map[string]any{"": map[string]any{}}
+	for i := range maxBatchRequests + 1 {
+		packages[fmt.Sprintf("node_modules/pkg-%d", i)] = map[string]string{"version": "1.0.0"}
+	}
+	lock, err := json.Marshal(map[string]any{"lockfileVersion": 3, "packages": packages})
+	require.NoError(t, err)
+
+	st.On("ListSuppressions", ctx).Return([]depsmanager.Suppression{}, nil).Once()
+	batchOf := func(n int) any {
+		return mock.MatchedBy(func(p []depsmanager.ProjectDependencies) bool { return len(p) == n })
+	}
+	dc.On("GetVersionsBatch", ctx, batchOf(maxBatchRequests)).Return(versionsBatchFromJSON(t, `{"responses":[]}`), nil).Once()
+	last := `{"responses":[{"version":{"versionKey":{"name":"pkg-999"},"relatedProjects":[{"projectKey":{"id":"repo"},"relationType":"SOURCE_REPO"}]}}]}`
+	dc.On("GetVersionsBatch", ctx, batchOf(1)).Return(versionsBatchFromJSON(t, last), nil).Once()
+	projectsJSON := `{"responses":[{"project":{"projectKey":{"id":"repo"},"scorecard":{"date":"2023-11-01T00:00:00Z","overallScore":7}}}]}`
+	dc.On("GetProjectsBatch", ctx, []string{"repo"}).Return(projectsBatchFromJSON(t, projectsJSON), nil).Once()
+
+	res, err := s.EvaluateLockfile(ctx, lock, depsmanager.GateRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.Packages, maxBatchRequests+1)
+	scored := 0
+	for _, p := range res.Packages {
+		if p.Score > 0 {
+			scored++
+			require.Equal(t, "pkg-999", p.Name)
+		}
+	}
+	require.Equal(t, 1, scored)
+	dc.AssertExpectations(t)
+}
+
+func TestService_EvaluateLockfile_DefaultThresholds_NothingChanged(t *testing.T) {
+	s, st, dc := newSvc(t)
+	minScore := 5.0
+	WithGateThresholds(depsmanager.GateThresholds{MinScore: &minScore})(s)
+	ctx := context.Background()
+
+	st.On("ListProjectDependencies", ctx, "app", "1.0.0").Return([]depsmanager.Dependency{
+		{Name: "a"}, {Name: "b"}, {Name: "c"},
+	}, nil).Once()
+
+	res, err := s.EvaluateLockfile(ctx, []byte(gateLockfile), depsmanager.GateRequest{BaselineProject: "app", BaselineVersion: "1.0.0"})
+	require.NoError(t, err)
+	require.Equal(t, depsmanager.VerdictPass, res.Verdict)
+	require.Equal(t, 0, res.ExitCode)
+	require.Equal(t, &minScore, res.Thresholds.MinScore)
+	require.Empty(t, res.Packages)
+	require.Empty(t, res.Violations)
+	dc.AssertNotCalled(t, "GetVersionsBatch")
+}
+
+func TestService_EvaluateLockfile_Errors(t *testing.T) {
+	s, st, dc := newSvc(t)
+	ctx := context.Background()
+
+	_, err := s.EvaluateLockfile(ctx, []byte("not json"), depsmanager.GateRequest{})
+	require.ErrorIs(t, err, depsmanager.ErrInvalidLockfile)
+
+	st.On("ListProjectDependencies", ctx, "app", "9.9.9").Return(nil, depsmanager.ErrProjectNotFound).Once()
+	_, err = s.EvaluateLockfile(ctx, []byte(gateLockfile), depsmanager.GateRequest{BaselineProject: "app", BaselineVersion: "9.9.9"})
+	require.ErrorIs(t, err, depsmanager.ErrProjectNotFound)
+
+	st.On("ListSuppressions", ctx).Return([]depsmanager.Suppression{}, nil).Once()
+	dc.On("GetVersionsBatch", ctx, mock.Anything).Return(nil, errors.New("vb error")).Once()
+	_, err = s.EvaluateLockfile(ctx, []byte(gateLockfile), depsmanager.GateRequest{})
+	require.Error(t, err)
+	st.AssertExpectations(t)
+	dc.AssertExpectations(t)
+}
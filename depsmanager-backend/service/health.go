@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// readyTimeout bounds every readiness check, a check still running after it fails.
+const readyTimeout = 2 * time.Second
+
+// HealthCheck is a dependency checked by /readyz, e.g. the database or deps.dev.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// CachedCheck reuses the result of check for ttl, so frequent probes do not call remote services every time.
+// Results of checks whose ctx ended are not reused, they tell about the caller rather than the service.
+func CachedCheck(check func(ctx context.Context) error, ttl time.Duration) func(ctx context.Context) error {
+	var (
+		mu        sync.Mutex
+		checkedAt time.Time
+		last      error
+	)
+	return func(ctx context.Context) error {
+		mu.Lock()
+		if !checkedAt.IsZero() && time.Since(checkedAt) < ttl {
+			defer mu.Unlock()
+			return last
+		}
+		mu.Unlock()
+
+		// not under mu, concurrent probes each wait for their own call with their own ctx
+		err := check(ctx)
+		if ctx.Err() != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		last, checkedAt = err, time.Now()
+		return err
+	}
+}
+
+// WithReadiness makes /readyz report ready only when all checks pass.
+func WithReadiness(checks ...HealthCheck) func(a *API) {
+	return func(a *API) {
+		a.readiness = append(a.readiness, checks...)
+	}
+}
+
+// WithVersion sets the build version returned by /version.
+func WithVersion(version string) func(a *API) {
+	return func(a *API) {
+		a.version = version
+	}
+}
+
+// HealthResponse is the body of /healthz and /readyz, Checks holds "ok" or the error of every check.
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// VersionResponse is the body of /version.
+type VersionResponse struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Healthz is the liveness probe, it answers while the process serves HTTP and checks no dependencies.
+// Probes are outside /api and left out of the API spec.
+func (a *API) Healthz(w http.ResponseWriter, _ *http.Request) {
+	writeHealth(w, http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// Readyz is the readiness probe, it answers 503 while any check of WithReadiness fails, e.g. the database
+// misses migrations or deps.dev is unreachable.
+func (a *API) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(a.readiness))
+	for _, c := range a.readiness {
+		go func() {
+			results <- result{name: c.Name, err: c.Check(ctx)}
+		}()
+	}
+
+	resp, status := HealthResponse{Status: "ok", Checks: make(map[string]string, len(a.readiness))}, http.StatusOK
+	for range a.readiness {
+		res := <-results
+		resp.Checks[res.name] = "ok"
+		if res.err != nil {
+			resp.Checks[res.name] = res.err.Error()
+			resp.Status, status = "unavailable", http.StatusServiceUnavailable
+		}
+	}
+	writeHealth(w, status, resp)
+}
+
+// Version returns the build version, the VCS revision and the Go version.
+func (a *API) Version(w http.ResponseWriter, _ *http.Request) {
+	resp := VersionResponse{Version: a.version, GoVersion: runtime.Version()}
+	if resp.Version == "" {
+		resp.Version = "dev"
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				resp.Revision = s.Value
+			}
+		}
+	}
+	writeHealth(w, http.StatusOK, resp)
+}
+
+func writeHealth(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	var depsCalls atomic.Int32
+	a := NewAPI(nil, WithAuth(true), WithVersion("v1.4.0"), WithReadiness(
+		HealthCheck{Name: "database", Check: func(context.Context) error { return nil }},
+		HealthCheck{Name: "deps.dev", Check: CachedCheck(func(context.Context) error {
+			depsCalls.Add(1)
+			return errors.New("dial tcp: i/o timeout")
+		}, time.Minute)},
+	))
+	h := a.GetHandler()
+
+	rr := doJSON(t, h, http.MethodGet, "/healthz", nil)
+	require.Equal(t, http.StatusOK, rr.Code, "probes do not need an api key")
+	assert.JSONEq(t, `{"status":"ok"}`, rr.Body.String())
+
+	for range 2 {
+		rr = doJSON(t, h, http.MethodGet, "/readyz", nil)
+		require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.JSONEq(t, `{"status":"unavailable","checks":{"database":"ok","deps.dev":"dial tcp: i/o timeout"}}`, rr.Body.String())
+	}
+	assert.Equal(t, int32(1), depsCalls.Load(), "deps.dev result is cached")
+
+	rr = doJSON(t, h, http.MethodGet, "/version", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var v VersionResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &v))
+	assert.Equal(t, "v1.4.0", v.Version)
+	assert.NotEmpty(t, v.GoVersion)
+}
+
+func TestCachedCheck_EndedContext(t *testing.T) {
+	var calls atomic.Int32
+	block := make(chan struct{})
+	check := CachedCheck(func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			<-block
+		}
+		return ctx.Err()
+	}, time.Minute)
+
+	// a call in progress does not block others
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- check(ctx) }()
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, check(context.Background()))
+
+	// the result of the cancelled call is not cached over the successful one
+	cancel()
+	close(block)
+	require.ErrorIs(t, <-done, context.Canceled)
+	require.NoError(t, check(context.Background()))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// nor cached when nothing was cached before
+	check = CachedCheck(func(ctx context.Context) error { return ctx.Err() }, time.Minute)
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, check(ctx), context.Canceled)
+	require.NoError(t, check(context.Background()))
+}
+
+func TestReadyz_Timeout(t *testing.T) {
+	a := NewAPI(nil, WithReadiness(HealthCheck{Name: "deps.dev", Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}))
+	h := a.GetHandler()
+
+	start := time.Now()
+	rr := doJSON(t, h, http.MethodGet, "/readyz", nil)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), "deadline exceeded")
+	assert.Less(t, time.Since(start), readyTimeout+time.Second)
+
+	a = NewAPI(nil)
+	rr = doJSON(t, a.GetHandler(), http.MethodGet, "/readyz", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rr.Body.String())
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/auth"
+	"depsmanager/pkg/ratelimit"
+	"fmt"
+	"net/http"
+)
+
+// LimitDepsClient lets calls of every DepsClient method through limiter, calls wait with priority of their
+// context, see ratelimit.WithPriority. Calls failing any of checks are rejected before they wait, e.g. with
+// CircuitBreaker.FailFast. Queue wait is recorded in m, m may be nil.
+func LimitDepsClient(next DepsClient, limiter *ratelimit.Limiter, m *Metrics, checks ...func(ctx context.Context) error) DepsClient {
+	return &limitedDepsClient{next: next, limiter: limiter, m: m, checks: checks}
+}
+
+type limitedDepsClient struct {
+	next    DepsClient
+	limiter *ratelimit.Limiter
+	m       *Metrics
+	checks  []func(ctx context.Context) error
+}
+
+func (c *limitedDepsClient) acquire(ctx context.Context) (func(), error) {
+	for _, check := range c.checks {
+		if err := check(ctx); err != nil {
+			return nil, err
+		}
+	}
+	p := ratelimit.PriorityFromContext(ctx)
+	release, waited, err := c.limiter.Acquire(ctx, p)
+	if c.m != nil {
+		c.m.depsQueueWait.Observe(waited.Seconds(), p.String())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("waiting for deps.dev rate limit: %w", err)
+	}
+	return release, nil
+}
+
+func (c *limitedDepsClient) GetProjectVersions(ctx context.Context, project string) (*depsmanager.DepsGetVersionResp, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return c.next.GetProjectVersions(ctx, project)
+}
+
+func (c *limitedDepsClient) GetProjectDependencies(ctx context.Context, system, project, version string) (*depsmanager.DepsProjectDependenciesResp, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return c.next.GetProjectDependencies(ctx, system, project, version)
+}
+
+func (c *limitedDepsClient) GetVersionsBatch(ctx context.Context, projects []depsmanager.ProjectDependencies) (*depsmanager.DepsGetVersionsBatchResp, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return c.next.GetVersionsBatch(ctx, projects)
+}
+
+func (c *limitedDepsClient) GetProjectsBatch(ctx context.Context, projects []string) (*depsmanager.DepsGetProjectBatchResp, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return c.next.GetProjectsBatch(ctx, projects)
+}
+
+// requestPriority lets requests of admins queue their deps.dev calls as background with ratelimit.PriorityHeader,
+// the header of other callers is ignored so they cannot push requests of others behind theirs. With auth
+// disabled every caller is trusted.
+func (a *API) requestPriority(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := auth.FromContext(r.Context())
+		trusted := !a.authEnabled || (ok && id.Role.Allows(depsmanager.RoleAdmin))
+		if p := ratelimit.PriorityFromRequest(r); trusted && p != ratelimit.Interactive {
+			r = r.WithContext(ratelimit.WithPriority(r.Context(), p))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
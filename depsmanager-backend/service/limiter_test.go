@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/metrics"
+	"depsmanager/pkg/ratelimit"
+	"depsmanager/service/mocks"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitDepsClient(t *testing.T) {
+	m := NewMetrics(metrics.NewRegistry())
+	dc := new(mocks.DepsClient)
+	limiter := ratelimit.NewLimiter(0, 0, 1)
+	client := LimitDepsClient(dc, limiter, m)
+
+	background := ratelimit.WithPriority(context.Background(), ratelimit.Background)
+	dc.On("GetProjectsBatch", background, mock.Anything).Return(&depsmanager.DepsGetProjectBatchResp{}, nil).Once()
+	_, err := client.GetProjectsBatch(background, nil)
+	require.NoError(t, err)
+
+	// the only slot is taken, a waiting call gives up with its context
+	release, _, err := limiter.Acquire(context.Background(), ratelimit.Interactive)
+	require.NoError(t, err)
+	defer release()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.GetProjectVersions(ctx, "react")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	dc.AssertExpectations(t)
+
+	body := doJSON(t, m.Handler(), http.MethodGet, "/metrics", nil).Body.String()
+	assert.Contains(t, body, `depsmanager_depsclient_queue_wait_seconds_count{priority="background"} 1`)
+	assert.Contains(t, body, `depsmanager_depsclient_queue_wait_seconds_count{priority="interactive"} 1`)
+	assert.Contains(t, body, `depsmanager_depsclient_queue_wait_seconds_bucket{priority="interactive",le="0.01"} 0`)
+}
+
+func TestLimitDepsClient_BreakerProbeDoesNotWait(t *testing.T) {
+	ctx := context.Background()
+	b, dc, _, now := newBreaker(t, 1)
+	limiter := ratelimit.NewLimiter(0, 0, 1)
+	client := LimitDepsClient(b, limiter, nil, b.FailFast)
+
+	upstreamErr := errors.New("bad response: 503")
+	dc.On("GetProjectVersions", mock.Anything, "react").Return(nil, upstreamErr).Times(3)
+	for range 3 {
+		_, _ = client.GetProjectVersions(ctx, "react")
+	}
+	require.Equal(t, BreakerOpen, b.State())
+
+	// open fails fast, without waiting for the taken slot
+	release, _, err := limiter.Acquire(ctx, ratelimit.Interactive)
+	require.NoError(t, err)
+	_, err = client.GetProjectVersions(ctx, "react")
+	require.ErrorIs(t, err, depsmanager.ErrUpstreamUnavailable)
+
+	// a half-open call waiting for the slot has not taken the probe yet
+	*now = now.Add(30 * time.Second)
+	dc.On("GetProjectVersions", mock.Anything, "react").Return(&depsmanager.DepsGetVersionResp{}, nil)
+	waited := make(chan error)
+	go func() {
+		_, err := client.GetProjectVersions(ctx, "react")
+		waited <- err
+	}()
+	require.Eventually(t, func() bool { return limiter.Waiting(ratelimit.Interactive) == 1 }, time.Second, time.Millisecond)
+	_, err = b.GetProjectVersions(ctx, "react")
+	require.NoError(t, err)
+	assert.Equal(t, BreakerClosed, b.State())
+
+	release()
+	require.NoError(t, <-waited)
+}
+
+func TestAPI_RequestPriority(t *testing.T) {
+	h, svc := setupAuth(t)
+	var got ratelimit.Priority
+	svc.On("FetchAndStoreProjectDependencies", mock.MatchedBy(func(ctx context.Context) bool {
+		got = ratelimit.PriorityFromContext(ctx)
+		return true
+	}), "react", "18.3.1").Return(nil)
+
+	for key, want := range map[string]ratelimit.Priority{
+		"dm_editor": ratelimit.Interactive, // header of others is ignored
+		"dm_admin":  ratelimit.Background,
+	} {
+		req := httptest.NewRequest(http.MethodPut, fetchPath, nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set(ratelimit.PriorityHeader, "background")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+		assert.Equal(t, want, got, key)
+	}
+}
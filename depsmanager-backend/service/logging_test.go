@@ -0,0 +1,78 @@
+package service
+
+import (
+	"bytes"
+	"depsmanager"
+	"depsmanager/pkg/logging"
+	"depsmanager/pkg/requestid"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs makes the default logger write JSON lines to the returned buffer until the test ends.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var b bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(logging.New(&b, slog.LevelDebug))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &b
+}
+
+func logLines(t *testing.T, b *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, l := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal([]byte(l), &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestAccessLog(t *testing.T) {
+	logs := captureLogs(t)
+	h, svc := setup(t)
+	svc.On("ListProjects", mock.Anything, mock.Anything).Return(nil, errors.New("boom")).Once()
+	svc.On("FetchAndStoreProjectDependencies", mock.Anything, "react", "18.3.1").Return(depsmanager.ErrProjectNotFound).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/projects/", nil)
+	req.Header.Set(requestid.Header, "req-1")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	require.Equal(t, http.StatusNotFound, doJSON(t, h, http.MethodPut, "/api/v2/projects/react/versions/18.3.1", nil).Code)
+
+	lines := logLines(t, logs)
+	require.Len(t, lines, 4)
+
+	assert.Equal(t, "ERROR", lines[0]["level"])
+	assert.Equal(t, "request failed", lines[0]["msg"])
+	assert.Equal(t, float64(http.StatusInternalServerError), lines[0]["status"])
+	assert.Equal(t, "req-1", lines[0]["request_id"])
+
+	assert.Equal(t, "INFO", lines[1]["level"])
+	assert.Equal(t, "request", lines[1]["msg"])
+	assert.Equal(t, "req-1", lines[1]["request_id"])
+	assert.Equal(t, http.MethodGet, lines[1]["method"])
+	assert.Equal(t, "/api/v1/projects/", lines[1]["path"])
+	assert.Equal(t, "/api/v1/projects", lines[1]["route"])
+	assert.Equal(t, float64(http.StatusInternalServerError), lines[1]["status"])
+	assert.Greater(t, lines[1]["bytes"], float64(0))
+	assert.Contains(t, lines[1], "duration_ms")
+
+	assert.Equal(t, "INFO", lines[2]["level"], "client errors are not logged as errors")
+	assert.Equal(t, "/api/v2/projects/{name}/versions/{version}", lines[3]["route"])
+	assert.Equal(t, lines[2]["request_id"], lines[3]["request_id"])
+	assert.Equal(t, "req-1", rr.Header().Get(requestid.Header))
+	svc.AssertExpectations(t)
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/labels"
+	"fmt"
+)
+
+func (s *service) GetProjectMetadata(ctx context.Context, projectName string) (depsmanager.ProjectMetadata, error) {
+	m, err := s.storage.GetProjectMetadata(ctx, projectName)
+	if err != nil {
+		return depsmanager.ProjectMetadata{}, fmt.Errorf("s.storage.GetProjectMetadata() projectName: %s, error: %w", projectName, err)
+	}
+	return m, nil
+}
+
+// SetProjectMetadata replaces owner, description, repo URL and labels of every version of the project.
+func (s *service) SetProjectMetadata(ctx context.Context, projectName string, req depsmanager.ProjectMetadataRequest) (depsmanager.ProjectMetadata, error) {
+	m := depsmanager.ProjectMetadata{
+		ProjectName: projectName,
+		Owner:       req.Owner,
+		Description: req.Description,
+		RepoURL:     req.RepoURL,
+		Labels:      req.Labels,
+		UpdatedAt:   s.tNow().Unix(),
+	}
+	if m.Labels == nil {
+		m.Labels = map[string]string{}
+	}
+
+	if err := s.storage.SaveProjectMetadata(ctx, m); err != nil {
+		return depsmanager.ProjectMetadata{}, fmt.Errorf("s.storage.SaveProjectMetadata() projectName: %s, error: %w", projectName, err)
+	}
+	return m, nil
+}
+
+func (s *service) DeleteProjectMetadata(ctx context.Context, projectName string) error {
+	if err := s.storage.DeleteProjectMetadata(ctx, projectName); err != nil {
+		return fmt.Errorf("s.storage.DeleteProjectMetadata() projectName: %s, error: %w", projectName, err)
+	}
+	return nil
+}
+
+func selectProjects(projects []depsmanager.Project, selector labels.Selector) []depsmanager.Project {
+	if selector.Empty() {
+		return projects
+	}
+	selected := make([]depsmanager.Project, 0, len(projects))
+	for _, p := range projects {
+		if selector.Matches(p.Labels) {
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
+
+// selectDependencies keeps deps of projects matching selector, deps need ProjectTenant and ProjectName.
+func (s *service) selectDependencies(ctx context.Context, deps []depsmanager.Dependency, selector labels.Selector) ([]depsmanager.Dependency, error) {
+	if selector.Empty() {
+		return deps, nil
+	}
+	projects, err := s.storage.ListProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.storage.ListProjects(): %w", err)
+	}
+
+	type key struct{ tenant, name string }
+	matching := make(map[key]struct{})
+	for _, p := range selectProjects(projects, selector) {
+		matching[key{p.Tenant, p.Name}] = struct{}{}
+	}
+	selected := make([]depsmanager.Dependency, 0, len(deps))
+	for _, d := range deps {
+		if _, ok := matching[key{d.ProjectTenant, d.ProjectName}]; ok {
+			selected = append(selected, d)
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/labels"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SetProjectMetadata(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	want := depsmanager.ProjectMetadata{ProjectName: "react", Owner: "web", Labels: map[string]string{}, UpdatedAt: fixedNow().Unix()}
+	st.On("SaveProjectMetadata", ctx, want).Return(nil).Once()
+
+	got, err := s.SetProjectMetadata(ctx, "react", depsmanager.ProjectMetadataRequest{Owner: "web"})
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	st.AssertExpectations(t)
+}
+
+func TestService_Selectors(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+	selector, err := labels.Parse("team=payments,env!=dev")
+	require.NoError(t, err)
+
+	projects := []depsmanager.Project{
+		{Tenant: "default", Name: "checkout", Version: "1.0.0", Labels: map[string]string{"team": "payments", "env": "prod"}},
+		{Tenant: "default", Name: "ledger", Version: "2.0.0", Labels: map[string]string{"team": "payments", "env": "dev"}},
+		{Tenant: "default", Name: "search", Version: "1.0.0"},
+	}
+	deps := []depsmanager.Dependency{
+		{ProjectTenant: "default", ProjectName: "checkout", ProjectVersion: "1.0.0", Name: "lodash", Score: 4, UpdatedAt: 100},
+		{ProjectTenant: "default", ProjectName: "ledger", ProjectVersion: "2.0.0", Name: "moment", Score: 4, UpdatedAt: 100},
+		{ProjectTenant: "default", ProjectName: "search", ProjectVersion: "1.0.0", Name: "axios", Score: 4, UpdatedAt: 100},
+	}
+	st.On("ListProjects", ctx).Return(projects, nil).Times(3)
+	st.On("GetProjectsByDependency", ctx, "lodash").Return(projects[:2], nil).Once()
+	st.On("ListAllDependencies", ctx).Return(deps, nil).Twice()
+	st.On("ListSuppressions", ctx).Return([]depsmanager.Suppression{}, nil).Twice()
+
+	got, err := s.ListProjects(ctx, selector)
+	require.NoError(t, err)
+	assert.Equal(t, projects[:1], got)
+
+	got, err = s.GetProjectsByDependency(ctx, "lodash", selector)
+	require.NoError(t, err)
+	assert.Equal(t, projects[:1], got)
+
+	names, err := s.GetDependenciesByExactScore(ctx, 4, selector)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lodash"}, names)
+
+	summary, err := s.GetPortfolioSummary(ctx, selector)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.DependencyCount)
+
+	st.AssertExpectations(t)
+	st.AssertNotCalled(t, "GetDependenciesByExactScore", mock.Anything, mock.Anything)
+}
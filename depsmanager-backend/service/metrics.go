@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/metrics"
+	"depsmanager/pkg/trace"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Metrics are collectors filled by the API middleware, by the Storage, DepsClient and Service decorators
+// and by RefreshPortfolioMetrics.
+type Metrics struct {
+	registry *metrics.Registry
+
+	httpRequests *metrics.CounterVec
+	httpDuration *metrics.HistogramVec
+	rateLimited  *metrics.CounterVec
+
+	depsRequests *metrics.CounterVec
+	depsErrors   *metrics.CounterVec
+	depsDuration *metrics.HistogramVec
+
+	depsQueueWait *metrics.HistogramVec
+	depsMirrors   *metrics.CounterVec
+
+	breakerState       *metrics.GaugeVec
+	breakerRejections  *metrics.CounterVec
+	breakerTransitions *metrics.CounterVec
+
+	storageDuration *metrics.HistogramVec
+	storageErrors   *metrics.CounterVec
+
+	fetchDuration     *metrics.HistogramVec
+	fetchDependencies *metrics.HistogramVec
+
+	portfolio portfolioGauges
+}
+
+// NewMetrics registers collectors of the service in registry.
+func NewMetrics(registry *metrics.Registry) *Metrics {
+	return &Metrics{
+		registry: registry,
+
+		httpRequests: registry.NewCounterVec("depsmanager_http_requests_total",
+			"HTTP requests by method, chi route pattern and status code.", "method", "route", "status"),
+		httpDuration: registry.NewHistogramVec("depsmanager_http_request_duration_seconds",
+			"HTTP request latency by method and chi route pattern.", metrics.DefaultBuckets, "method", "route"),
+
+		rateLimited: registry.NewCounterVec("depsmanager_http_rate_limited_total",
+			"Requests rejected with 429 by rate limit quota class (default, expensive, address).", "class"),
+
+		depsRequests: registry.NewCounterVec("depsmanager_depsclient_requests_total",
+			"deps.dev calls by DepsClient method.", "method"),
+		depsMirrors: registry.NewCounterVec("depsmanager_depsclient_mirror_attempts_total",
+			"deps.dev attempts by mirror base URL and result (answered, failed), failed ones fail over to the next mirror.",
+			"mirror", "result"),
+		depsErrors: registry.NewCounterVec("depsmanager_depsclient_errors_total",
+			"Failed deps.dev calls by DepsClient method, not found answers are not counted.", "method"),
+		depsDuration: registry.NewHistogramVec("depsmanager_depsclient_request_duration_seconds",
+			"deps.dev call latency by DepsClient method.", metrics.DefaultBuckets, "method"),
+
+		depsQueueWait: registry.NewHistogramVec("depsmanager_depsclient_queue_wait_seconds",
+			"Time deps.dev calls waited for the rate and concurrency limit by priority (interactive, background).",
+			[]float64{0, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}, "priority"),
+
+		breakerState: registry.NewGaugeVec("depsmanager_depsclient_circuit_state",
+			"State of the deps.dev circuit breaker: 0 closed, 1 half-open, 2 open."),
+		breakerRejections: registry.NewCounterVec("depsmanager_depsclient_circuit_rejections_total",
+			"deps.dev calls failed fast by the circuit breaker."),
+		breakerTransitions: registry.NewCounterVec("depsmanager_depsclient_circuit_transitions_total",
+			"Circuit breaker state changes by the new state.", "state"),
+
+		storageDuration: registry.NewHistogramVec("depsmanager_storage_query_duration_seconds",
+			"SQLite latency by Storage method.", metrics.DefaultBuckets, "method"),
+		storageErrors: registry.NewCounterVec("depsmanager_storage_errors_total",
+			"Failed Storage calls by method, not found errors included.", "method"),
+
+		fetchDuration: registry.NewHistogramVec("depsmanager_fetch_duration_seconds",
+			"Duration of fetching and storing project dependencies by result (ok, not_found, error).",
+			[]float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}, "result"),
+		fetchDependencies: registry.NewHistogramVec("depsmanager_fetch_dependencies_stored",
+			"Dependencies stored per fetch.", []float64{0, 10, 50, 100, 250, 500, 1000, 2500, 5000}),
+
+		portfolio: newPortfolioGauges(registry),
+	}
+}
+
+func (m *Metrics) setBreakerState(s BreakerState) {
+	if m == nil {
+		return
+	}
+	m.breakerState.Set(float64(s))
+	m.breakerTransitions.Inc(s.String())
+}
+
+func (m *Metrics) breakerRejected() {
+	if m != nil {
+		m.breakerRejections.Inc()
+	}
+}
+
+// ObserveMirror counts a deps.dev attempt of mirror, see clients.WithMirrorObserver.
+func (m *Metrics) ObserveMirror(mirror, result string) {
+	if m != nil {
+		m.depsMirrors.Inc(mirror, result)
+	}
+}
+
+// Handler serves collected metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return m.registry.Handler()
+}
+
+// WithMetrics exposes m on /metrics and records every request in it.
+func WithMetrics(m *Metrics) func(a *API) {
+	return func(a *API) {
+		a.metrics = m
+	}
+}
+
+// instrument records requests by route pattern, so path parameters do not create new series.
+func (m *Metrics) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		route := "unmatched"
+		if rc := chi.RouteContext(r.Context()); rc != nil && rc.RoutePattern() != "" {
+			route = rc.RoutePattern()
+		}
+		method := metricMethod(r.Method)
+		m.httpRequests.Inc(method, route, strconv.Itoa(sw.status))
+		m.httpDuration.Observe(time.Since(start).Seconds(), method, route)
+	})
+}
+
+// metricMethod returns "other" for methods outside the standard ones, clients choose the method.
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace:
+		return method
+	}
+	return "other"
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// InstrumentDepsClient counts calls, errors and latency of every DepsClient method and traces each call
+// as a client span, the span is propagated to deps.dev when the client sends traceparent. m and tracer may be nil.
+func InstrumentDepsClient(next DepsClient, m *Metrics, tracer *trace.Tracer) DepsClient {
+	return &instrumentedDepsClient{next: next, m: m, tracer: tracer}
+}
+
+type instrumentedDepsClient struct {
+	next   DepsClient
+	m      *Metrics
+	tracer *trace.Tracer
+}
+
+// start starts span of method, the returned func records the result and ends it.
+// Not found answers are expected and do not count as errors.
+func (c *instrumentedDepsClient) start(ctx context.Context, method string, attrs ...trace.Attribute) (context.Context, func(err *error)) {
+	start := time.Now()
+	ctx, span := c.tracer.Start(ctx, "depsclient."+method, trace.KindClient, attrs...)
+	return ctx, func(err *error) {
+		failed := *err != nil && !errors.Is(*err, depsmanager.ErrProjectNotFound)
+		if c.m != nil {
+			c.m.depsRequests.Inc(method)
+			c.m.depsDuration.Observe(time.Since(start).Seconds(), method)
+			if failed {
+				c.m.depsErrors.Inc(method)
+			}
+		}
+		if failed {
+			span.RecordError(*err)
+		}
+		span.End()
+	}
+}
+
+func (c *instrumentedDepsClient) GetProjectVersions(ctx context.Context, project string) (_ *depsmanager.DepsGetVersionResp, err error) {
+	ctx, done := c.start(ctx, "GetProjectVersions", trace.String("project.name", project))
+	defer done(&err)
+	return c.next.GetProjectVersions(ctx, project)
+}
+
+func (c *instrumentedDepsClient) GetProjectDependencies(ctx context.Context, system, project, version string) (_ *depsmanager.DepsProjectDependenciesResp, err error) {
+	ctx, done := c.start(ctx, "GetProjectDependencies", trace.String("project.system", system),
+		trace.String("project.name", project), trace.String("project.version", version))
+	defer done(&err)
+	return c.next.GetProjectDependencies(ctx, system, project, version)
+}
+
+func (c *instrumentedDepsClient) GetVersionsBatch(ctx context.Context, projects []depsmanager.ProjectDependencies) (_ *depsmanager.DepsGetVersionsBatchResp, err error) {
+	ctx, done := c.start(ctx, "GetVersionsBatch", trace.Int("batch.size", len(projects)))
+	defer done(&err)
+	return c.next.GetVersionsBatch(ctx, projects)
+}
+
+func (c *instrumentedDepsClient) GetProjectsBatch(ctx context.Context, projects []string) (_ *depsmanager.DepsGetProjectBatchResp, err error) {
+	ctx, done := c.start(ctx, "GetProjectsBatch", trace.Int("batch.size", len(projects)))
+	defer done(&err)
+	return c.next.GetProjectsBatch(ctx, projects)
+}
+
+// InstrumentService records duration of fetches and traces them, other methods are passed through.
+// m and tracer may be nil.
+func InstrumentService(next Service, m *Metrics, tracer *trace.Tracer) Service {
+	return &instrumentedService{Service: next, m: m, tracer: tracer}
+}
+
+type instrumentedService struct {
+	Service
+	m      *Metrics
+	tracer *trace.Tracer
+}
+
+func (s *instrumentedService) FetchAndStoreProjectDependencies(ctx context.Context, projectName, version string) error {
+	start := time.Now()
+	ctx, span := s.tracer.Start(ctx, "service.FetchAndStoreProjectDependencies", trace.KindInternal,
+		trace.String("project.name", projectName), trace.String("project.version", version))
+	defer span.End()
+	err := s.Service.FetchAndStoreProjectDependencies(ctx, projectName, version)
+
+	result := "ok"
+	switch {
+	case errors.Is(err, depsmanager.ErrProjectNotFound):
+		result = "not_found"
+	case err != nil:
+		result = "error"
+		span.RecordError(err)
+	}
+	span.SetAttributes(trace.String("fetch.result", result))
+	if s.m != nil {
+		s.m.fetchDuration.Observe(time.Since(start).Seconds(), result)
+	}
+	return err
+}
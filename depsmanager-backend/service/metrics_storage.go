@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/trace"
+	"time"
+)
+
+// InstrumentStorage records latency and errors of every Storage method and dependencies stored per fetch,
+// each call is traced as a storage.<Method> span. m and tracer may be nil.
+func InstrumentStorage(next Storage, m *Metrics, tracer *trace.Tracer) Storage {
+	return &instrumentedStorage{next: next, m: m, tracer: tracer}
+}
+
+type instrumentedStorage struct {
+	next   Storage
+	m      *Metrics
+	tracer *trace.Tracer
+}
+
+// start starts span of method, the returned func records the result and ends it.
+func (st *instrumentedStorage) start(ctx context.Context, method string, attrs ...trace.Attribute) (context.Context, func(err *error)) {
+	start := time.Now()
+	ctx, span := st.tracer.Start(ctx, "storage."+method, trace.KindInternal, attrs...)
+	return ctx, func(err *error) {
+		if st.m != nil {
+			st.m.storageDuration.Observe(time.Since(start).Seconds(), method)
+			if *err != nil {
+				st.m.storageErrors.Inc(method)
+			}
+		}
+		span.RecordError(*err)
+		span.End()
+	}
+}
+
+func (st *instrumentedStorage) StoreDependencies(ctx context.Context, deps depsmanager.ProjectDependencyRecord) (err error) {
+	ctx, done := st.start(ctx, "StoreDependencies", trace.String("project.name", deps.Project.Name),
+		trace.String("project.version", deps.Project.Version), trace.Int("dependency.count", len(deps.Dependencies)))
+	defer done(&err)
+	if err = st.next.StoreDependencies(ctx, deps); err == nil && st.m != nil {
+		st.m.fetchDependencies.Observe(float64(len(deps.Dependencies)))
+	}
+	return err
+}
+
+func (st *instrumentedStorage) DeleteProject(ctx context.Context, projectName, version string) (err error) {
+	ctx, done := st.start(ctx, "DeleteProject", trace.String("project.name", projectName), trace.String("project.version", version))
+	defer done(&err)
+	return st.next.DeleteProject(ctx, projectName, version)
+}
+
+func (st *instrumentedStorage) ListProjectDependencies(ctx context.Context, projectName, version string) (_ []depsmanager.Dependency, err error) {
+	ctx, done := st.start(ctx, "ListProjectDependencies", trace.String("project.name", projectName), trace.String("project.version", version))
+	defer done(&err)
+	return st.next.ListProjectDependencies(ctx, projectName, version)
+}
+
+func (st *instrumentedStorage) ListAllDependencies(ctx context.Context) (_ []depsmanager.Dependency, err error) {
+	ctx, done := st.start(ctx, "ListAllDependencies")
+	defer done(&err)
+	return st.next.ListAllDependencies(ctx)
+}
+
+func (st *instrumentedStorage) ListProjects(ctx context.Context) (_ []depsmanager.Project, err error) {
+	ctx, done := st.start(ctx, "ListProjects")
+	defer done(&err)
+	return st.next.ListProjects(ctx)
+}
+
+func (st *instrumentedStorage) GetDependenciesByExactScore(ctx context.Context, score float64) (_ []string, err error) {
+	ctx, done := st.start(ctx, "GetDependenciesByExactScore")
+	defer done(&err)
+	return st.next.GetDependenciesByExactScore(ctx, score)
+}
+
+func (st *instrumentedStorage) GetProjectsByDependency(ctx context.Context, depName string) (_ []depsmanager.Project, err error) {
+	ctx, done := st.start(ctx, "GetProjectsByDependency", trace.String("dependency.name", depName))
+	defer done(&err)
+	return st.next.GetProjectsByDependency(ctx, depName)
+}
+
+func (st *instrumentedStorage) AddDependency(ctx context.Context, projectName, version string, dep depsmanager.Dependency) (err error) {
+	ctx, done := st.start(ctx, "AddDependency", trace.String("project.name", projectName), trace.String("project.version", version), trace.String("dependency.name", dep.Name))
+	defer done(&err)
+	return st.next.AddDependency(ctx, projectName, version, dep)
+}
+
+func (st *instrumentedStorage) UpdateDependency(ctx context.Context, projectName, version string, dep depsmanager.Dependency) (err error) {
+	ctx, done := st.start(ctx, "UpdateDependency", trace.String("project.name", projectName), trace.String("project.version", version), trace.String("dependency.name", dep.Name))
+	defer done(&err)
+	return st.next.UpdateDependency(ctx, projectName, version, dep)
+}
+
+func (st *instrumentedStorage) DeleteDependency(ctx context.Context, projectName, version, depName string) (err error) {
+	ctx, done := st.start(ctx, "DeleteDependency", trace.String("project.name", projectName), trace.String("project.version", version), trace.String("dependency.name", depName))
+	defer done(&err)
+	return st.next.DeleteDependency(ctx, projectName, version, depName)
+}
+
+func (st *instrumentedStorage) ClearScoreOverride(ctx context.Context, projectName, version, depName string) (err error) {
+	ctx, done := st.start(ctx, "ClearScoreOverride", trace.String("project.name", projectName), trace.String("project.version", version), trace.String("dependency.name", depName))
+	defer done(&err)
+	return st.next.ClearScoreOverride(ctx, projectName, version, depName)
+}
+
+func (st *instrumentedStorage) SavePolicy(ctx context.Context, p depsmanager.Policy) (err error) {
+	ctx, done := st.start(ctx, "SavePolicy")
+	defer done(&err)
+	return st.next.SavePolicy(ctx, p)
+}
+
+func (st *instrumentedStorage) ListPolicies(ctx context.Context, projectName string) (_ []depsmanager.Policy, err error) {
+	ctx, done := st.start(ctx, "ListPolicies", trace.String("project.name", projectName))
+	defer done(&err)
+	return st.next.ListPolicies(ctx, projectName)
+}
+
+func (st *instrumentedStorage) DeletePolicy(ctx context.Context, projectName, name string) (err error) {
+	ctx, done := st.start(ctx, "DeletePolicy", trace.String("project.name", projectName))
+	defer done(&err)
+	return st.next.DeletePolicy(ctx, projectName, name)
+}
+
+func (st *instrumentedStorage) StorePolicyEvaluation(ctx context.Context, eval depsmanager.PolicyEvaluation) (err error) {
+	ctx, done := st.start(ctx, "StorePolicyEvaluation")
+	defer done(&err)
+	return st.next.StorePolicyEvaluation(ctx, eval)
+}
+
+func (st *instrumentedStorage) GetPolicyEvaluation(ctx context.Context, projectName, version string) (_ depsmanager.PolicyEvaluation, err error) {
+	ctx, done := st.start(ctx, "GetPolicyEvaluation", trace.String("project.name", projectName), trace.String("project.version", version))
+	defer done(&err)
+	return st.next.GetPolicyEvaluation(ctx, projectName, version)
+}
+
+func (st *instrumentedStorage) CreateAPIKey(ctx context.Context, key depsmanager.APIKey) (_ depsmanager.APIKey, err error) {
+	ctx, done := st.start(ctx, "CreateAPIKey")
+	defer done(&err)
+	return st.next.CreateAPIKey(ctx, key)
+}
+
+func (st *instrumentedStorage) ListAPIKeys(ctx context.Context) (_ []depsmanager.APIKey, err error) {
+	ctx, done := st.start(ctx, "ListAPIKeys")
+	defer done(&err)
+	return st.next.ListAPIKeys(ctx)
+}
+
+func (st *instrumentedStorage) GetActiveAPIKey(ctx context.Context, keyHash string) (_ depsmanager.APIKey, err error) {
+	ctx, done := st.start(ctx, "GetActiveAPIKey")
+	defer done(&err)
+	return st.next.GetActiveAPIKey(ctx, keyHash)
+}
+
+func (st *instrumentedStorage) RevokeAPIKey(ctx context.Context, id int64, revokedAt int64) (err error) {
+	ctx, done := st.start(ctx, "RevokeAPIKey")
+	defer done(&err)
+	return st.next.RevokeAPIKey(ctx, id, revokedAt)
+}
+
+func (st *instrumentedStorage) CreateTenant(ctx context.Context, t depsmanager.Tenant) (err error) {
+	ctx, done := st.start(ctx, "CreateTenant")
+	defer done(&err)
+	return st.next.CreateTenant(ctx, t)
+}
+
+func (st *instrumentedStorage) ListTenants(ctx context.Context) (_ []depsmanager.Tenant, err error) {
+	ctx, done := st.start(ctx, "ListTenants")
+	defer done(&err)
+	return st.next.ListTenants(ctx)
+}
+
+func (st *instrumentedStorage) GetTenant(ctx context.Context, name string) (_ depsmanager.Tenant, err error) {
+	ctx, done := st.start(ctx, "GetTenant")
+	defer done(&err)
+	return st.next.GetTenant(ctx, name)
+}
+
+func (st *instrumentedStorage) ListAudit(ctx context.Context, filter depsmanager.AuditFilter) (_ []depsmanager.AuditEntry, err error) {
+	ctx, done := st.start(ctx, "ListAudit")
+	defer done(&err)
+	return st.next.ListAudit(ctx, filter)
+}
+
+func (st *instrumentedStorage) CreateSuppression(ctx context.Context, s depsmanager.Suppression) (_ depsmanager.Suppression, err error) {
+	ctx, done := st.start(ctx, "CreateSuppression")
+	defer done(&err)
+	return st.next.CreateSuppression(ctx, s)
+}
+
+func (st *instrumentedStorage) ListSuppressions(ctx context.Context) (_ []depsmanager.Suppression, err error) {
+	ctx, done := st.start(ctx, "ListSuppressions")
+	defer done(&err)
+	return st.next.ListSuppressions(ctx)
+}
+
+func (st *instrumentedStorage) DeleteSuppression(ctx context.Context, id int64) (err error) {
+	ctx, done := st.start(ctx, "DeleteSuppression")
+	defer done(&err)
+	return st.next.DeleteSuppression(ctx, id)
+}
+
+func (st *instrumentedStorage) GetProjectMetadata(ctx context.Context, projectName string) (_ depsmanager.ProjectMetadata, err error) {
+	ctx, done := st.start(ctx, "GetProjectMetadata", trace.String("project.name", projectName))
+	defer done(&err)
+	return st.next.GetProjectMetadata(ctx, projectName)
+}
+
+func (st *instrumentedStorage) SaveProjectMetadata(ctx context.Context, m depsmanager.ProjectMetadata) (err error) {
+	ctx, done := st.start(ctx, "SaveProjectMetadata")
+	defer done(&err)
+	return st.next.SaveProjectMetadata(ctx, m)
+}
+
+func (st *instrumentedStorage) DeleteProjectMetadata(ctx context.Context, projectName string) (err error) {
+	ctx, done := st.start(ctx, "DeleteProjectMetadata", trace.String("project.name", projectName))
+	defer done(&err)
+	return st.next.DeleteProjectMetadata(ctx, projectName)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/metrics"
+	"depsmanager/service/mocks"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_HTTPAndFetch(t *testing.T) {
+	svc := new(mocks.Service)
+	m := NewMetrics(metrics.NewRegistry())
+	a := NewAPI(InstrumentService(svc, m, nil), WithMetrics(m))
+	h := a.GetHandler()
+
+	svc.On("FetchAndStoreProjectDependencies", mock.Anything, "react", "18.3.1").Return(nil).Once()
+	svc.On("FetchAndStoreProjectDependencies", mock.Anything, "missing", "1.0.0").Return(depsmanager.ErrProjectNotFound).Once()
+
+	require.Equal(t, http.StatusNoContent, doJSON(t, h, http.MethodPut, "/api/v2/projects/react/versions/18.3.1", nil).Code)
+	require.Equal(t, http.StatusNotFound, doJSON(t, h, http.MethodPut, "/api/v2/projects/missing/versions/1.0.0", nil).Code)
+	doJSON(t, h, "PROPFIND", "/api/v2/projects", nil)
+	doJSON(t, h, "X-RANDOM-1", "/api/v2/projects", nil)
+
+	rr := doJSON(t, h, http.MethodGet, "/metrics", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, metrics.ContentType, rr.Header().Get("Content-Type"))
+	body := rr.Body.String()
+	assert.Contains(t, body, `depsmanager_http_requests_total{method="PUT",route="/api/v2/projects/{name}/versions/{version}",status="204"} 1`)
+	assert.Contains(t, body, `depsmanager_http_requests_total{method="PUT",route="/api/v2/projects/{name}/versions/{version}",status="404"} 1`)
+	assert.Contains(t, body, `depsmanager_http_request_duration_seconds_count{method="PUT",route="/api/v2/projects/{name}/versions/{version}"} 2`)
+	assert.Contains(t, body, `depsmanager_http_request_duration_seconds_count{method="other",route="unmatched"} 2`)
+	assert.NotContains(t, body, "PROPFIND")
+	assert.Contains(t, body, `depsmanager_fetch_duration_seconds_count{result="ok"} 1`)
+	assert.Contains(t, body, `depsmanager_fetch_duration_seconds_count{result="not_found"} 1`)
+	svc.AssertExpectations(t)
+}
+
+func TestMetrics_Decorators(t *testing.T) {
+	ctx := context.Background()
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry)
+
+	st := new(mocks.Storage)
+	st.On("StoreDependencies", ctx, mock.Anything).Return(nil).Once()
+	st.On("GetTenant", ctx, "missing").Return(depsmanager.Tenant{}, depsmanager.ErrTenantNotFound).Once()
+	storage := InstrumentStorage(st, m, nil)
+	require.NoError(t, storage.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+		Dependencies: []depsmanager.Dependency{{Name: "a"}, {Name: "b"}},
+	}))
+	_, err := storage.GetTenant(ctx, "missing")
+	require.ErrorIs(t, err, depsmanager.ErrTenantNotFound)
+
+	dc := new(mocks.DepsClient)
+	dc.On("GetProjectVersions", ctx, "react").Return(nil, errors.New("timeout")).Once()
+	dc.On("GetProjectVersions", ctx, "missing").Return(nil, depsmanager.ErrProjectNotFound).Once()
+	client := InstrumentDepsClient(dc, m, nil)
+	_, err = client.GetProjectVersions(ctx, "react")
+	require.Error(t, err)
+	_, err = client.GetProjectVersions(ctx, "missing")
+	require.ErrorIs(t, err, depsmanager.ErrProjectNotFound)
+
+	rr := doJSON(t, m.Handler(), http.MethodGet, "/metrics", nil)
+	body := rr.Body.String()
+	assert.Contains(t, body, "depsmanager_fetch_dependencies_stored_sum 2\n")
+	assert.Contains(t, body, `depsmanager_storage_query_duration_seconds_count{method="StoreDependencies"} 1`)
+	assert.Contains(t, body, `depsmanager_storage_errors_total{method="GetTenant"} 1`)
+	assert.Contains(t, body, `depsmanager_depsclient_requests_total{method="GetProjectVersions"} 2`)
+	assert.Contains(t, body, `depsmanager_depsclient_errors_total{method="GetProjectVersions"} 1`)
+
+	m.ObserveMirror("https://mirror.example", "failed")
+	body = doJSON(t, m.Handler(), http.MethodGet, "/metrics", nil).Body.String()
+	assert.Contains(t, body, `depsmanager_depsclient_mirror_attempts_total{mirror="https://mirror.example",result="failed"} 1`)
+	st.AssertExpectations(t)
+	dc.AssertExpectations(t)
+}
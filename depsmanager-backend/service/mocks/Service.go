@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 	depsmanager "depsmanager"
+	labels "depsmanager/pkg/labels"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -32,6 +33,136 @@ func (_m *Service) AddDependency(ctx context.Context, projectName string, versio
 	return r0
 }
 
+// Authenticate provides a mock function with given fields: ctx, key
+func (_m *Service) Authenticate(ctx context.Context, key string) (depsmanager.Identity, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authenticate")
+	}
+
+	var r0 depsmanager.Identity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (depsmanager.Identity, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) depsmanager.Identity); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(depsmanager.Identity)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClearScoreOverride provides a mock function with given fields: ctx, projectName, version, depName
+func (_m *Service) ClearScoreOverride(ctx context.Context, projectName string, version string, depName string) error {
+	ret := _m.Called(ctx, projectName, version, depName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClearScoreOverride")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, projectName, version, depName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateAPIKey provides a mock function with given fields: ctx, name, role, tenantName
+func (_m *Service) CreateAPIKey(ctx context.Context, name string, role depsmanager.Role, tenantName string) (depsmanager.CreatedAPIKey, error) {
+	ret := _m.Called(ctx, name, role, tenantName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 depsmanager.CreatedAPIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, depsmanager.Role, string) (depsmanager.CreatedAPIKey, error)); ok {
+		return rf(ctx, name, role, tenantName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, depsmanager.Role, string) depsmanager.CreatedAPIKey); ok {
+		r0 = rf(ctx, name, role, tenantName)
+	} else {
+		r0 = ret.Get(0).(depsmanager.CreatedAPIKey)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, depsmanager.Role, string) error); ok {
+		r1 = rf(ctx, name, role, tenantName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateSuppression provides a mock function with given fields: ctx, req
+func (_m *Service) CreateSuppression(ctx context.Context, req depsmanager.CreateSuppressionRequest) (depsmanager.Suppression, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSuppression")
+	}
+
+	var r0 depsmanager.Suppression
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.CreateSuppressionRequest) (depsmanager.Suppression, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.CreateSuppressionRequest) depsmanager.Suppression); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(depsmanager.Suppression)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, depsmanager.CreateSuppressionRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateTenant provides a mock function with given fields: ctx, name
+func (_m *Service) CreateTenant(ctx context.Context, name string) (depsmanager.Tenant, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTenant")
+	}
+
+	var r0 depsmanager.Tenant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (depsmanager.Tenant, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) depsmanager.Tenant); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Get(0).(depsmanager.Tenant)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteDependency provides a mock function with given fields: ctx, projectName, version, depName
 func (_m *Service) DeleteDependency(ctx context.Context, projectName string, version string, depName string) error {
 	ret := _m.Called(ctx, projectName, version, depName)
@@ -50,6 +181,24 @@ func (_m *Service) DeleteDependency(ctx context.Context, projectName string, ver
 	return r0
 }
 
+// DeletePolicy provides a mock function with given fields: ctx, projectName, name
+func (_m *Service) DeletePolicy(ctx context.Context, projectName string, name string) error {
+	ret := _m.Called(ctx, projectName, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePolicy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, projectName, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteProject provides a mock function with given fields: ctx, projectName, version
 func (_m *Service) DeleteProject(ctx context.Context, projectName string, version string) error {
 	ret := _m.Called(ctx, projectName, version)
@@ -68,6 +217,98 @@ func (_m *Service) DeleteProject(ctx context.Context, projectName string, versio
 	return r0
 }
 
+// DeleteProjectMetadata provides a mock function with given fields: ctx, projectName
+func (_m *Service) DeleteProjectMetadata(ctx context.Context, projectName string) error {
+	ret := _m.Called(ctx, projectName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProjectMetadata")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, projectName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSuppression provides a mock function with given fields: ctx, id
+func (_m *Service) DeleteSuppression(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSuppression")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EvaluateLockfile provides a mock function with given fields: ctx, lock, req
+func (_m *Service) EvaluateLockfile(ctx context.Context, lock []byte, req depsmanager.GateRequest) (depsmanager.GateResult, error) {
+	ret := _m.Called(ctx, lock, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EvaluateLockfile")
+	}
+
+	var r0 depsmanager.GateResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, depsmanager.GateRequest) (depsmanager.GateResult, error)); ok {
+		return rf(ctx, lock, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, depsmanager.GateRequest) depsmanager.GateResult); ok {
+		r0 = rf(ctx, lock, req)
+	} else {
+		r0 = ret.Get(0).(depsmanager.GateResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte, depsmanager.GateRequest) error); ok {
+		r1 = rf(ctx, lock, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EvaluatePolicies provides a mock function with given fields: ctx, projectName, version
+func (_m *Service) EvaluatePolicies(ctx context.Context, projectName string, version string) (depsmanager.PolicyEvaluation, error) {
+	ret := _m.Called(ctx, projectName, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EvaluatePolicies")
+	}
+
+	var r0 depsmanager.PolicyEvaluation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (depsmanager.PolicyEvaluation, error)); ok {
+		return rf(ctx, projectName, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) depsmanager.PolicyEvaluation); ok {
+		r0 = rf(ctx, projectName, version)
+	} else {
+		r0 = ret.Get(0).(depsmanager.PolicyEvaluation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, projectName, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FetchAndStoreProjectDependencies provides a mock function with given fields: ctx, projectName, version
 func (_m *Service) FetchAndStoreProjectDependencies(ctx context.Context, projectName string, version string) error {
 	ret := _m.Called(ctx, projectName, version)
@@ -86,9 +327,9 @@ func (_m *Service) FetchAndStoreProjectDependencies(ctx context.Context, project
 	return r0
 }
 
-// GetDependenciesByExactScore provides a mock function with given fields: ctx, score
-func (_m *Service) GetDependenciesByExactScore(ctx context.Context, score float64) ([]string, error) {
-	ret := _m.Called(ctx, score)
+// GetDependenciesByExactScore provides a mock function with given fields: ctx, score, selector
+func (_m *Service) GetDependenciesByExactScore(ctx context.Context, score float64, selector labels.Selector) ([]string, error) {
+	ret := _m.Called(ctx, score, selector)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetDependenciesByExactScore")
@@ -96,19 +337,19 @@ func (_m *Service) GetDependenciesByExactScore(ctx context.Context, score float6
 
 	var r0 []string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, float64) ([]string, error)); ok {
-		return rf(ctx, score)
+	if rf, ok := ret.Get(0).(func(context.Context, float64, labels.Selector) ([]string, error)); ok {
+		return rf(ctx, score, selector)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, float64) []string); ok {
-		r0 = rf(ctx, score)
+	if rf, ok := ret.Get(0).(func(context.Context, float64, labels.Selector) []string); ok {
+		r0 = rf(ctx, score, selector)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]string)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, float64) error); ok {
-		r1 = rf(ctx, score)
+	if rf, ok := ret.Get(1).(func(context.Context, float64, labels.Selector) error); ok {
+		r1 = rf(ctx, score, selector)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -116,9 +357,121 @@ func (_m *Service) GetDependenciesByExactScore(ctx context.Context, score float6
 	return r0, r1
 }
 
-// GetProjectsByDependency provides a mock function with given fields: ctx, depName
-func (_m *Service) GetProjectsByDependency(ctx context.Context, depName string) ([]depsmanager.Project, error) {
-	ret := _m.Called(ctx, depName)
+// GetPolicyEvaluation provides a mock function with given fields: ctx, projectName, version
+func (_m *Service) GetPolicyEvaluation(ctx context.Context, projectName string, version string) (depsmanager.PolicyEvaluation, error) {
+	ret := _m.Called(ctx, projectName, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPolicyEvaluation")
+	}
+
+	var r0 depsmanager.PolicyEvaluation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (depsmanager.PolicyEvaluation, error)); ok {
+		return rf(ctx, projectName, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) depsmanager.PolicyEvaluation); ok {
+		r0 = rf(ctx, projectName, version)
+	} else {
+		r0 = ret.Get(0).(depsmanager.PolicyEvaluation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, projectName, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPortfolioSummary provides a mock function with given fields: ctx, selector
+func (_m *Service) GetPortfolioSummary(ctx context.Context, selector labels.Selector) (depsmanager.RiskSummary, error) {
+	ret := _m.Called(ctx, selector)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPortfolioSummary")
+	}
+
+	var r0 depsmanager.RiskSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, labels.Selector) (depsmanager.RiskSummary, error)); ok {
+		return rf(ctx, selector)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, labels.Selector) depsmanager.RiskSummary); ok {
+		r0 = rf(ctx, selector)
+	} else {
+		r0 = ret.Get(0).(depsmanager.RiskSummary)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, labels.Selector) error); ok {
+		r1 = rf(ctx, selector)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProjectMetadata provides a mock function with given fields: ctx, projectName
+func (_m *Service) GetProjectMetadata(ctx context.Context, projectName string) (depsmanager.ProjectMetadata, error) {
+	ret := _m.Called(ctx, projectName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProjectMetadata")
+	}
+
+	var r0 depsmanager.ProjectMetadata
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (depsmanager.ProjectMetadata, error)); ok {
+		return rf(ctx, projectName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) depsmanager.ProjectMetadata); ok {
+		r0 = rf(ctx, projectName)
+	} else {
+		r0 = ret.Get(0).(depsmanager.ProjectMetadata)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, projectName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProjectSummary provides a mock function with given fields: ctx, projectName, version
+func (_m *Service) GetProjectSummary(ctx context.Context, projectName string, version string) (depsmanager.RiskSummary, error) {
+	ret := _m.Called(ctx, projectName, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProjectSummary")
+	}
+
+	var r0 depsmanager.RiskSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (depsmanager.RiskSummary, error)); ok {
+		return rf(ctx, projectName, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) depsmanager.RiskSummary); ok {
+		r0 = rf(ctx, projectName, version)
+	} else {
+		r0 = ret.Get(0).(depsmanager.RiskSummary)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, projectName, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProjectsByDependency provides a mock function with given fields: ctx, depName, selector
+func (_m *Service) GetProjectsByDependency(ctx context.Context, depName string, selector labels.Selector) ([]depsmanager.Project, error) {
+	ret := _m.Called(ctx, depName, selector)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetProjectsByDependency")
@@ -126,19 +479,105 @@ func (_m *Service) GetProjectsByDependency(ctx context.Context, depName string)
 
 	var r0 []depsmanager.Project
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) ([]depsmanager.Project, error)); ok {
-		return rf(ctx, depName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, labels.Selector) ([]depsmanager.Project, error)); ok {
+		return rf(ctx, depName, selector)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) []depsmanager.Project); ok {
-		r0 = rf(ctx, depName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, labels.Selector) []depsmanager.Project); ok {
+		r0 = rf(ctx, depName, selector)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]depsmanager.Project)
 		}
 	}
 
+	if rf, ok := ret.Get(1).(func(context.Context, string, labels.Selector) error); ok {
+		r1 = rf(ctx, depName, selector)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTenant provides a mock function with given fields: ctx, name
+func (_m *Service) GetTenant(ctx context.Context, name string) (depsmanager.Tenant, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTenant")
+	}
+
+	var r0 depsmanager.Tenant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (depsmanager.Tenant, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) depsmanager.Tenant); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Get(0).(depsmanager.Tenant)
+	}
+
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, depName)
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAPIKeys provides a mock function with given fields: ctx
+func (_m *Service) ListAPIKeys(ctx context.Context) ([]depsmanager.APIKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAPIKeys")
+	}
+
+	var r0 []depsmanager.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]depsmanager.APIKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []depsmanager.APIKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]depsmanager.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAudit provides a mock function with given fields: ctx, filter
+func (_m *Service) ListAudit(ctx context.Context, filter depsmanager.AuditFilter) (depsmanager.AuditPage, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAudit")
+	}
+
+	var r0 depsmanager.AuditPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.AuditFilter) (depsmanager.AuditPage, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.AuditFilter) depsmanager.AuditPage); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(depsmanager.AuditPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, depsmanager.AuditFilter) error); ok {
+		r1 = rf(ctx, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -174,6 +613,36 @@ func (_m *Service) ListDependencies(ctx context.Context, projectName string, ver
 	return r0, r1
 }
 
+// ListPolicies provides a mock function with given fields: ctx, projectName
+func (_m *Service) ListPolicies(ctx context.Context, projectName string) ([]depsmanager.Policy, error) {
+	ret := _m.Called(ctx, projectName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPolicies")
+	}
+
+	var r0 []depsmanager.Policy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]depsmanager.Policy, error)); ok {
+		return rf(ctx, projectName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []depsmanager.Policy); ok {
+		r0 = rf(ctx, projectName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]depsmanager.Policy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, projectName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListProjectVersions provides a mock function with given fields: ctx, projectName
 func (_m *Service) ListProjectVersions(ctx context.Context, projectName string) ([]string, error) {
 	ret := _m.Called(ctx, projectName)
@@ -204,9 +673,9 @@ func (_m *Service) ListProjectVersions(ctx context.Context, projectName string)
 	return r0, r1
 }
 
-// ListProjects provides a mock function with given fields: ctx
-func (_m *Service) ListProjects(ctx context.Context) ([]depsmanager.Project, error) {
-	ret := _m.Called(ctx)
+// ListProjects provides a mock function with given fields: ctx, selector
+func (_m *Service) ListProjects(ctx context.Context, selector labels.Selector) ([]depsmanager.Project, error) {
+	ret := _m.Called(ctx, selector)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ListProjects")
@@ -214,14 +683,44 @@ func (_m *Service) ListProjects(ctx context.Context) ([]depsmanager.Project, err
 
 	var r0 []depsmanager.Project
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]depsmanager.Project, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, labels.Selector) ([]depsmanager.Project, error)); ok {
+		return rf(ctx, selector)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, labels.Selector) []depsmanager.Project); ok {
+		r0 = rf(ctx, selector)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]depsmanager.Project)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, labels.Selector) error); ok {
+		r1 = rf(ctx, selector)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSuppressions provides a mock function with given fields: ctx
+func (_m *Service) ListSuppressions(ctx context.Context) ([]depsmanager.Suppression, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSuppressions")
+	}
+
+	var r0 []depsmanager.Suppression
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]depsmanager.Suppression, error)); ok {
 		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []depsmanager.Project); ok {
+	if rf, ok := ret.Get(0).(func(context.Context) []depsmanager.Suppression); ok {
 		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]depsmanager.Project)
+			r0 = ret.Get(0).([]depsmanager.Suppression)
 		}
 	}
 
@@ -234,6 +733,110 @@ func (_m *Service) ListProjects(ctx context.Context) ([]depsmanager.Project, err
 	return r0, r1
 }
 
+// ListTenants provides a mock function with given fields: ctx
+func (_m *Service) ListTenants(ctx context.Context) ([]depsmanager.Tenant, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTenants")
+	}
+
+	var r0 []depsmanager.Tenant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]depsmanager.Tenant, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []depsmanager.Tenant); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]depsmanager.Tenant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, id
+func (_m *Service) RevokeAPIKey(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SavePolicy provides a mock function with given fields: ctx, document
+func (_m *Service) SavePolicy(ctx context.Context, document []byte) (depsmanager.Policy, error) {
+	ret := _m.Called(ctx, document)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePolicy")
+	}
+
+	var r0 depsmanager.Policy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (depsmanager.Policy, error)); ok {
+		return rf(ctx, document)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) depsmanager.Policy); ok {
+		r0 = rf(ctx, document)
+	} else {
+		r0 = ret.Get(0).(depsmanager.Policy)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, document)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetProjectMetadata provides a mock function with given fields: ctx, projectName, req
+func (_m *Service) SetProjectMetadata(ctx context.Context, projectName string, req depsmanager.ProjectMetadataRequest) (depsmanager.ProjectMetadata, error) {
+	ret := _m.Called(ctx, projectName, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetProjectMetadata")
+	}
+
+	var r0 depsmanager.ProjectMetadata
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, depsmanager.ProjectMetadataRequest) (depsmanager.ProjectMetadata, error)); ok {
+		return rf(ctx, projectName, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, depsmanager.ProjectMetadataRequest) depsmanager.ProjectMetadata); ok {
+		r0 = rf(ctx, projectName, req)
+	} else {
+		r0 = ret.Get(0).(depsmanager.ProjectMetadata)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, depsmanager.ProjectMetadataRequest) error); ok {
+		r1 = rf(ctx, projectName, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateDependency provides a mock function with given fields: ctx, projectName, version, dep
 func (_m *Service) UpdateDependency(ctx context.Context, projectName string, version string, dep depsmanager.Dependency) error {
 	ret := _m.Called(ctx, projectName, version, dep)
@@ -252,6 +855,34 @@ func (_m *Service) UpdateDependency(ctx context.Context, projectName string, ver
 	return r0
 }
 
+// UpsertDependency provides a mock function with given fields: ctx, projectName, version, dep
+func (_m *Service) UpsertDependency(ctx context.Context, projectName string, version string, dep depsmanager.Dependency) (bool, error) {
+	ret := _m.Called(ctx, projectName, version, dep)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertDependency")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, depsmanager.Dependency) (bool, error)); ok {
+		return rf(ctx, projectName, version, dep)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, depsmanager.Dependency) bool); ok {
+		r0 = rf(ctx, projectName, version, dep)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, depsmanager.Dependency) error); ok {
+		r1 = rf(ctx, projectName, version, dep)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewService creates a new instance of Service. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewService(t interface {
@@ -32,6 +32,98 @@ func (_m *Storage) AddDependency(ctx context.Context, projectName string, versio
 	return r0
 }
 
+// ClearScoreOverride provides a mock function with given fields: ctx, projectName, version, depName
+func (_m *Storage) ClearScoreOverride(ctx context.Context, projectName string, version string, depName string) error {
+	ret := _m.Called(ctx, projectName, version, depName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClearScoreOverride")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, projectName, version, depName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateAPIKey provides a mock function with given fields: ctx, key
+func (_m *Storage) CreateAPIKey(ctx context.Context, key depsmanager.APIKey) (depsmanager.APIKey, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 depsmanager.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.APIKey) (depsmanager.APIKey, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.APIKey) depsmanager.APIKey); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(depsmanager.APIKey)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, depsmanager.APIKey) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateSuppression provides a mock function with given fields: ctx, s
+func (_m *Storage) CreateSuppression(ctx context.Context, s depsmanager.Suppression) (depsmanager.Suppression, error) {
+	ret := _m.Called(ctx, s)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSuppression")
+	}
+
+	var r0 depsmanager.Suppression
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.Suppression) (depsmanager.Suppression, error)); ok {
+		return rf(ctx, s)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.Suppression) depsmanager.Suppression); ok {
+		r0 = rf(ctx, s)
+	} else {
+		r0 = ret.Get(0).(depsmanager.Suppression)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, depsmanager.Suppression) error); ok {
+		r1 = rf(ctx, s)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateTenant provides a mock function with given fields: ctx, t
+func (_m *Storage) CreateTenant(ctx context.Context, t depsmanager.Tenant) error {
+	ret := _m.Called(ctx, t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTenant")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.Tenant) error); ok {
+		r0 = rf(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteDependency provides a mock function with given fields: ctx, projectName, version, depName
 func (_m *Storage) DeleteDependency(ctx context.Context, projectName string, version string, depName string) error {
 	ret := _m.Called(ctx, projectName, version, depName)
@@ -50,6 +142,24 @@ func (_m *Storage) DeleteDependency(ctx context.Context, projectName string, ver
 	return r0
 }
 
+// DeletePolicy provides a mock function with given fields: ctx, projectName, name
+func (_m *Storage) DeletePolicy(ctx context.Context, projectName string, name string) error {
+	ret := _m.Called(ctx, projectName, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePolicy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, projectName, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteProject provides a mock function with given fields: ctx, projectName, version
 func (_m *Storage) DeleteProject(ctx context.Context, projectName string, version string) error {
 	ret := _m.Called(ctx, projectName, version)
@@ -68,6 +178,70 @@ func (_m *Storage) DeleteProject(ctx context.Context, projectName string, versio
 	return r0
 }
 
+// DeleteProjectMetadata provides a mock function with given fields: ctx, projectName
+func (_m *Storage) DeleteProjectMetadata(ctx context.Context, projectName string) error {
+	ret := _m.Called(ctx, projectName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProjectMetadata")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, projectName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSuppression provides a mock function with given fields: ctx, id
+func (_m *Storage) DeleteSuppression(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSuppression")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetActiveAPIKey provides a mock function with given fields: ctx, keyHash
+func (_m *Storage) GetActiveAPIKey(ctx context.Context, keyHash string) (depsmanager.APIKey, error) {
+	ret := _m.Called(ctx, keyHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActiveAPIKey")
+	}
+
+	var r0 depsmanager.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (depsmanager.APIKey, error)); ok {
+		return rf(ctx, keyHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) depsmanager.APIKey); ok {
+		r0 = rf(ctx, keyHash)
+	} else {
+		r0 = ret.Get(0).(depsmanager.APIKey)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, keyHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDependenciesByExactScore provides a mock function with given fields: ctx, score
 func (_m *Storage) GetDependenciesByExactScore(ctx context.Context, score float64) ([]string, error) {
 	ret := _m.Called(ctx, score)
@@ -98,6 +272,62 @@ func (_m *Storage) GetDependenciesByExactScore(ctx context.Context, score float6
 	return r0, r1
 }
 
+// GetPolicyEvaluation provides a mock function with given fields: ctx, projectName, version
+func (_m *Storage) GetPolicyEvaluation(ctx context.Context, projectName string, version string) (depsmanager.PolicyEvaluation, error) {
+	ret := _m.Called(ctx, projectName, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPolicyEvaluation")
+	}
+
+	var r0 depsmanager.PolicyEvaluation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (depsmanager.PolicyEvaluation, error)); ok {
+		return rf(ctx, projectName, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) depsmanager.PolicyEvaluation); ok {
+		r0 = rf(ctx, projectName, version)
+	} else {
+		r0 = ret.Get(0).(depsmanager.PolicyEvaluation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, projectName, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProjectMetadata provides a mock function with given fields: ctx, projectName
+func (_m *Storage) GetProjectMetadata(ctx context.Context, projectName string) (depsmanager.ProjectMetadata, error) {
+	ret := _m.Called(ctx, projectName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProjectMetadata")
+	}
+
+	var r0 depsmanager.ProjectMetadata
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (depsmanager.ProjectMetadata, error)); ok {
+		return rf(ctx, projectName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) depsmanager.ProjectMetadata); ok {
+		r0 = rf(ctx, projectName)
+	} else {
+		r0 = ret.Get(0).(depsmanager.ProjectMetadata)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, projectName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetProjectsByDependency provides a mock function with given fields: ctx, depName
 func (_m *Storage) GetProjectsByDependency(ctx context.Context, depName string) ([]depsmanager.Project, error) {
 	ret := _m.Called(ctx, depName)
@@ -128,6 +358,154 @@ func (_m *Storage) GetProjectsByDependency(ctx context.Context, depName string)
 	return r0, r1
 }
 
+// GetTenant provides a mock function with given fields: ctx, name
+func (_m *Storage) GetTenant(ctx context.Context, name string) (depsmanager.Tenant, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTenant")
+	}
+
+	var r0 depsmanager.Tenant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (depsmanager.Tenant, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) depsmanager.Tenant); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Get(0).(depsmanager.Tenant)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAPIKeys provides a mock function with given fields: ctx
+func (_m *Storage) ListAPIKeys(ctx context.Context) ([]depsmanager.APIKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAPIKeys")
+	}
+
+	var r0 []depsmanager.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]depsmanager.APIKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []depsmanager.APIKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]depsmanager.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAllDependencies provides a mock function with given fields: ctx
+func (_m *Storage) ListAllDependencies(ctx context.Context) ([]depsmanager.Dependency, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAllDependencies")
+	}
+
+	var r0 []depsmanager.Dependency
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]depsmanager.Dependency, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []depsmanager.Dependency); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]depsmanager.Dependency)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAudit provides a mock function with given fields: ctx, filter
+func (_m *Storage) ListAudit(ctx context.Context, filter depsmanager.AuditFilter) ([]depsmanager.AuditEntry, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAudit")
+	}
+
+	var r0 []depsmanager.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.AuditFilter) ([]depsmanager.AuditEntry, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.AuditFilter) []depsmanager.AuditEntry); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]depsmanager.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, depsmanager.AuditFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPolicies provides a mock function with given fields: ctx, projectName
+func (_m *Storage) ListPolicies(ctx context.Context, projectName string) ([]depsmanager.Policy, error) {
+	ret := _m.Called(ctx, projectName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPolicies")
+	}
+
+	var r0 []depsmanager.Policy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]depsmanager.Policy, error)); ok {
+		return rf(ctx, projectName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []depsmanager.Policy); ok {
+		r0 = rf(ctx, projectName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]depsmanager.Policy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, projectName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListProjectDependencies provides a mock function with given fields: ctx, projectName, version
 func (_m *Storage) ListProjectDependencies(ctx context.Context, projectName string, version string) ([]depsmanager.Dependency, error) {
 	ret := _m.Called(ctx, projectName, version)
@@ -188,6 +566,120 @@ func (_m *Storage) ListProjects(ctx context.Context) ([]depsmanager.Project, err
 	return r0, r1
 }
 
+// ListSuppressions provides a mock function with given fields: ctx
+func (_m *Storage) ListSuppressions(ctx context.Context) ([]depsmanager.Suppression, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSuppressions")
+	}
+
+	var r0 []depsmanager.Suppression
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]depsmanager.Suppression, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []depsmanager.Suppression); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]depsmanager.Suppression)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListTenants provides a mock function with given fields: ctx
+func (_m *Storage) ListTenants(ctx context.Context) ([]depsmanager.Tenant, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTenants")
+	}
+
+	var r0 []depsmanager.Tenant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]depsmanager.Tenant, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []depsmanager.Tenant); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]depsmanager.Tenant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, id, revokedAt
+func (_m *Storage) RevokeAPIKey(ctx context.Context, id int64, revokedAt int64) error {
+	ret := _m.Called(ctx, id, revokedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, id, revokedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SavePolicy provides a mock function with given fields: ctx, p
+func (_m *Storage) SavePolicy(ctx context.Context, p depsmanager.Policy) error {
+	ret := _m.Called(ctx, p)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePolicy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.Policy) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveProjectMetadata provides a mock function with given fields: ctx, m
+func (_m *Storage) SaveProjectMetadata(ctx context.Context, m depsmanager.ProjectMetadata) error {
+	ret := _m.Called(ctx, m)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveProjectMetadata")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.ProjectMetadata) error); ok {
+		r0 = rf(ctx, m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // StoreDependencies provides a mock function with given fields: ctx, deps
 func (_m *Storage) StoreDependencies(ctx context.Context, deps depsmanager.ProjectDependencyRecord) error {
 	ret := _m.Called(ctx, deps)
@@ -206,6 +698,24 @@ func (_m *Storage) StoreDependencies(ctx context.Context, deps depsmanager.Proje
 	return r0
 }
 
+// StorePolicyEvaluation provides a mock function with given fields: ctx, eval
+func (_m *Storage) StorePolicyEvaluation(ctx context.Context, eval depsmanager.PolicyEvaluation) error {
+	ret := _m.Called(ctx, eval)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StorePolicyEvaluation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, depsmanager.PolicyEvaluation) error); ok {
+		r0 = rf(ctx, eval)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateDependency provides a mock function with given fields: ctx, projectName, version, dep
 func (_m *Storage) UpdateDependency(ctx context.Context, projectName string, version string, dep depsmanager.Dependency) error {
 	ret := _m.Called(ctx, projectName, version, dep)
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/policy"
+	"fmt"
+)
+
+// SavePolicy parses YAML document and creates or replaces the policy with the same name and project.
+func (s *service) SavePolicy(ctx context.Context, document []byte) (depsmanager.Policy, error) {
+	p, err := policy.Parse(document)
+	if err != nil {
+		return depsmanager.Policy{}, fmt.Errorf("policy.Parse(): %w", err)
+	}
+	p.UpdatedAt = s.tNow().Unix()
+
+	if err := s.storage.SavePolicy(ctx, p); err != nil {
+		return depsmanager.Policy{}, fmt.Errorf("s.storage.SavePolicy() policy: %s, error: %w", p.Name, err)
+	}
+
+	return p, nil
+}
+
+// ListPolicies returns global policies and policies of projectName with decoded rules,
+// policies of the config file come first.
+func (s *service) ListPolicies(ctx context.Context, projectName string) ([]depsmanager.Policy, error) {
+	stored, err := s.storage.ListPolicies(ctx, projectName)
+	if err != nil {
+		return nil, fmt.Errorf("s.storage.ListPolicies() projectName: %s, error: %w", projectName, err)
+	}
+
+	policies := make([]depsmanager.Policy, 0, len(stored))
+	s.mu.RLock()
+	for _, p := range s.configPolicies {
+		if p.ProjectName == "" || p.ProjectName == projectName {
+			policies = append(policies, p)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, sp := range stored {
+		p, err := policy.Parse([]byte(sp.Document))
+		if err != nil {
+			return nil, fmt.Errorf("policy.Parse() policy: %s, error: %w", sp.Name, err)
+		}
+		p.ProjectName = sp.ProjectName
+		p.UpdatedAt = sp.UpdatedAt
+		policies = append(policies, p)
+	}
+
+	return policies, nil
+}
+
+func (s *service) DeletePolicy(ctx context.Context, projectName, name string) error {
+	if err := s.storage.DeletePolicy(ctx, projectName, name); err != nil {
+		return fmt.Errorf("s.storage.DeletePolicy() policy: %s, error: %w", name, err)
+	}
+	return nil
+}
+
+// EvaluatePolicies evaluates global and project policies against stored dependencies
+// of the project version and keeps the result as the latest evaluation.
+func (s *service) EvaluatePolicies(ctx context.Context, projectName, version string) (depsmanager.PolicyEvaluation, error) {
+	deps, err := s.storage.ListProjectDependencies(ctx, projectName, version)
+	if err != nil {
+		return depsmanager.PolicyEvaluation{}, fmt.Errorf("s.storage.ListProjectDependencies() projectName: %s, error: %w", projectName, err)
+	}
+	if deps, err = s.applySuppressions(ctx, deps); err != nil {
+		return depsmanager.PolicyEvaluation{}, err
+	}
+
+	policies, err := s.ListPolicies(ctx, projectName)
+	if err != nil {
+		return depsmanager.PolicyEvaluation{}, err
+	}
+
+	now := s.tNow()
+	violations := policy.Evaluate(policies, deps, now)
+	eval := depsmanager.PolicyEvaluation{
+		ProjectName: projectName,
+		Version:     version,
+		Verdict:     policy.Verdict(violations),
+		Violations:  violations,
+		EvaluatedAt: now.Unix(),
+	}
+
+	if err := s.storage.StorePolicyEvaluation(ctx, eval); err != nil {
+		return depsmanager.PolicyEvaluation{}, fmt.Errorf("s.storage.StorePolicyEvaluation() projectName: %s, error: %w", projectName, err)
+	}
+
+	return eval, nil
+}
+
+// GetPolicyEvaluation returns the latest stored evaluation, e.g. the one done after fetch.
+func (s *service) GetPolicyEvaluation(ctx context.Context, projectName, version string) (depsmanager.PolicyEvaluation, error) {
+	eval, err := s.storage.GetPolicyEvaluation(ctx, projectName, version)
+	if err != nil {
+		return depsmanager.PolicyEvaluation{}, fmt.Errorf("s.storage.GetPolicyEvaluation() projectName: %s, error: %w", projectName, err)
+	}
+	return eval, nil
+}
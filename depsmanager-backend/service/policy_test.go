@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const strictPolicy = `
+name: strict
+rules:
+  - type: min_score
+    relation: direct
+    value: 4
+`
+
+func TestService_SavePolicy_Success(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	st.On("SavePolicy", ctx, mock.MatchedBy(func(p depsmanager.Policy) bool {
+		return p.Name == "strict" && p.Document == strictPolicy && p.UpdatedAt == fixedNow().Unix()
+	})).Return(nil).Once()
+
+	p, err := s.SavePolicy(ctx, []byte(strictPolicy))
+	require.NoError(t, err)
+	require.Equal(t, "strict", p.Name)
+	require.Len(t, p.Rules, 1)
+	st.AssertExpectations(t)
+}
+
+func TestService_SavePolicy_Invalid(t *testing.T) {
+	s, st, _ := newSvc(t)
+
+	_, err := s.SavePolicy(context.Background(), []byte("name: x\n"))
+	require.ErrorIs(t, err, depsmanager.ErrInvalidPolicy)
+	st.AssertNotCalled(t, "SavePolicy", mock.Anything, mock.Anything)
+}
+
+func TestService_EvaluatePolicies_Fail(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	st.On("ListProjectDependencies", ctx, "p", "1.0.0").Return([]depsmanager.Dependency{
+		{Name: "low", Score: 2, UpdatedAt: fixedNow().Unix(), Relation: depsmanager.RelationDirect},
+		{Name: "low-indirect", Score: 2, UpdatedAt: fixedNow().Unix(), Relation: depsmanager.RelationIndirect},
+	}, nil).Once()
+	st.On("ListSuppressions", ctx).Return([]depsmanager.Suppression{}, nil).Once()
+	st.On("ListPolicies", ctx, "p").Return([]depsmanager.Policy{
+		{Name: "strict", ProjectName: "p", Document: strictPolicy, UpdatedAt: 1},
+	}, nil).Once()
+	st.On("StorePolicyEvaluation", ctx, mock.MatchedBy(func(eval depsmanager.PolicyEvaluation) bool {
+		return eval.Verdict == depsmanager.VerdictFail && len(eval.Violations) == 1
+	})).Return(nil).Once()
+
+	eval, err := s.EvaluatePolicies(ctx, "p", "1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, depsmanager.VerdictFail, eval.Verdict)
+	require.Equal(t, fixedNow().Unix(), eval.EvaluatedAt)
+	require.Len(t, eval.Violations, 1)
+	require.Equal(t, "low", eval.Violations[0].DependencyName)
+	st.AssertExpectations(t)
+}
+
+func TestService_ListPolicies_ConfigPolicies(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+	global := depsmanager.Policy{Name: "baseline", Rules: []depsmanager.PolicyRule{{Type: depsmanager.PolicyRuleMaxUnscored, Value: 5}}}
+	other := depsmanager.Policy{Name: "other-only", ProjectName: "other", Rules: global.Rules}
+	WithConfigPolicies([]depsmanager.Policy{global, other})(s)
+
+	st.On("ListPolicies", ctx, "p").Return([]depsmanager.Policy{
+		{Name: "strict", ProjectName: "p", Document: strictPolicy, UpdatedAt: 1},
+	}, nil).Twice()
+
+	policies, err := s.ListPolicies(ctx, "p")
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	require.Equal(t, "baseline", policies[0].Name)
+	require.Equal(t, "strict", policies[1].Name)
+
+	minScore := 6.0
+	s.Reload(depsmanager.GateThresholds{MinScore: &minScore}, nil)
+	policies, err = s.ListPolicies(ctx, "p")
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	require.Equal(t, &minScore, s.mergeGateThresholds(depsmanager.GateThresholds{}).MinScore)
+	st.AssertExpectations(t)
+}
+
+func TestService_EvaluatePolicies_ProjectNotFound(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	st.On("ListProjectDependencies", ctx, "p", "1.0.0").Return(nil, depsmanager.ErrProjectNotFound).Once()
+
+	_, err := s.EvaluatePolicies(ctx, "p", "1.0.0")
+	require.ErrorIs(t, err, depsmanager.ErrProjectNotFound)
+	st.AssertExpectations(t)
+}
+
+func TestService_FetchAndStore_StoresRelation_EvaluationErrorIgnored(t *testing.T) {
+	s, st, dc := newSvc(t)
+	ctx := context.Background()
+
+	depsJSON := `{"nodes":[{"versionKey":{"system":"npm","name":"a","version":"1.0.0"},"relation":"DIRECT"}]}`
+	dc.On("GetProjectDependencies", ctx, SystemNPM, "p", "1.0.0").
+		Return(depsRespFromJSON(t, depsJSON), nil).Once()
+	versionsJSON := `{"responses":[{"version":{"versionKey":{"name":"a"},"relatedProjects":[{"projectKey":{"id":"repo-1"},"relationType":"SOURCE_REPO"}]}}]}`
+	dc.On("GetVersionsBatch", ctx, mock.Anything).Return(versionsBatchFromJSON(t, versionsJSON), nil).Once()
+	pbJSON := `{"responses":[{"project":{"projectKey":{"id":"repo-1"},"scorecard":{"date":"2024-01-01T00:00:00Z","overallScore":3}}}]}`
+	dc.On("GetProjectsBatch", ctx, []string{"repo-1"}).Return(projectsBatchFromJSON(t, pbJSON), nil).Once()
+
+	st.On("StoreDependencies", ctx, mock.MatchedBy(func(rec depsmanager.ProjectDependencyRecord) bool {
+		return len(rec.Dependencies) == 1 && rec.Dependencies[0].Relation == depsmanager.RelationDirect
+	})).Return(nil).Once()
+	st.On("ListProjectDependencies", ctx, "p", "1.0.0").Return(nil, errors.New("db error")).Once()
+
+	require.NoError(t, s.FetchAndStoreProjectDependencies(ctx, "p", "1.0.0"))
+	st.AssertExpectations(t)
+	dc.AssertExpectations(t)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/metrics"
+	"depsmanager/pkg/ratelimit"
+	"depsmanager/pkg/summary"
+	"depsmanager/pkg/tenant"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// portfolioGauges describe risk of every stored project version. Each project version adds one series
+// to every labeled gauge, so the number of exported versions is capped, see RefreshPortfolioMetrics.
+type portfolioGauges struct {
+	minScore       *metrics.GaugeVec
+	meanScore      *metrics.GaugeVec
+	belowThreshold *metrics.GaugeVec
+	unscored       *metrics.GaugeVec
+	oldestAge      *metrics.GaugeVec
+
+	versions    *metrics.GaugeVec
+	dropped     *metrics.GaugeVec
+	refreshedAt *metrics.GaugeVec
+}
+
+func newPortfolioGauges(registry *metrics.Registry) portfolioGauges {
+	labels := []string{"tenant", "project", "version"}
+	return portfolioGauges{
+		minScore: registry.NewGaugeVec("depsmanager_portfolio_min_score",
+			"Lowest score of scored dependencies of project version.", labels...),
+		meanScore: registry.NewGaugeVec("depsmanager_portfolio_mean_score",
+			"Average score of scored dependencies of project version.", labels...),
+		belowThreshold: registry.NewGaugeVec("depsmanager_portfolio_dependencies_below_threshold",
+			"Scored dependencies of project version below PORTFOLIO_SCORE_THRESHOLD.", labels...),
+		unscored: registry.NewGaugeVec("depsmanager_portfolio_unscored_dependencies",
+			"Dependencies of project version without scorecard.", labels...),
+		oldestAge: registry.NewGaugeVec("depsmanager_portfolio_oldest_scorecard_age_seconds",
+			"Age of the oldest scorecard of project version.", labels...),
+
+		versions: registry.NewGaugeVec("depsmanager_portfolio_project_versions",
+			"Project versions with dependencies in all tenants."),
+		dropped: registry.NewGaugeVec("depsmanager_portfolio_project_versions_dropped",
+			"Project versions left out of labeled gauges by PORTFOLIO_METRICS_MAX_SERIES."),
+		refreshedAt: registry.NewGaugeVec("depsmanager_portfolio_refreshed_timestamp_seconds",
+			"Time of the last successful refresh of portfolio gauges."),
+	}
+}
+
+// RunPortfolioMetrics refreshes portfolio gauges of m every interval until ctx is done, with background priority.
+func (s *service) RunPortfolioMetrics(ctx context.Context, m *Metrics, conf depsmanager.PortfolioMetricsConfig) {
+	if conf.PortfolioMetricsInterval <= 0 {
+		return
+	}
+	ctx = ratelimit.WithPriority(ctx, ratelimit.Background)
+	ticker := time.NewTicker(conf.PortfolioMetricsInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.RefreshPortfolioMetrics(ctx, m, conf); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "portfolio metrics refresh failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshPortfolioMetrics recomputes portfolio gauges from dependencies of all tenants, suppressed ones
+// are left out like in summaries. Only conf.PortfolioMetricsMaxSeries project versions with the lowest
+// minimum score are exported with labels, the rest is counted in depsmanager_portfolio_project_versions_dropped.
+// MaxSeries 0 exports all of them.
+func (s *service) RefreshPortfolioMetrics(ctx context.Context, m *Metrics, conf depsmanager.PortfolioMetricsConfig) error {
+	ctx = tenant.NewContext(ctx, tenant.All)
+	deps, err := s.storage.ListAllDependencies(ctx)
+	if err != nil {
+		return fmt.Errorf("s.storage.ListAllDependencies(): %w", err)
+	}
+	if deps, err = s.applySuppressions(ctx, deps); err != nil {
+		return err
+	}
+
+	type key struct{ tenant, name, version string }
+	type projectRisk struct {
+		key
+		summary depsmanager.RiskSummary
+		below   int
+	}
+	grouped := make(map[key][]depsmanager.Dependency)
+	for _, d := range deps {
+		k := key{d.ProjectTenant, d.ProjectName, d.ProjectVersion}
+		grouped[k] = append(grouped[k], d)
+	}
+	risks := make([]projectRisk, 0, len(grouped))
+	for k, projectDeps := range grouped {
+		r := projectRisk{key: k, summary: summary.Summarize(projectDeps)}
+		for _, d := range projectDeps {
+			if d.SuppressedBy == nil && d.UpdatedAt != 0 && d.Score < conf.PortfolioScoreThreshold {
+				r.below++
+			}
+		}
+		risks = append(risks, r)
+	}
+	// riskiest first, so a cap drops the healthy project versions
+	sort.Slice(risks, func(i, j int) bool {
+		a, b := risks[i], risks[j]
+		if a.summary.MinScore != b.summary.MinScore {
+			return a.summary.MinScore < b.summary.MinScore
+		}
+		if a.tenant != b.tenant {
+			return a.tenant < b.tenant
+		}
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		return a.version < b.version
+	})
+	dropped := 0
+	if limit := conf.PortfolioMetricsMaxSeries; limit > 0 && len(risks) > limit {
+		dropped = len(risks) - limit
+		risks = risks[:limit]
+	}
+
+	now := s.tNow()
+	var minScore, meanScore, below, unscored, oldestAge []metrics.Sample
+	for _, r := range risks {
+		labels := []string{r.tenant, r.name, r.version}
+		below = append(below, metrics.Sample{Value: float64(r.below), Labels: labels})
+		unscored = append(unscored, metrics.Sample{Value: float64(r.summary.UnscoredCount), Labels: labels})
+		if r.summary.OldestScorecardAt == 0 {
+			// nothing scored, there is no score or age to report
+			continue
+		}
+		minScore = append(minScore, metrics.Sample{Value: r.summary.MinScore, Labels: labels})
+		meanScore = append(meanScore, metrics.Sample{Value: r.summary.MeanScore, Labels: labels})
+		age := now.Sub(time.Unix(r.summary.OldestScorecardAt, 0)).Seconds()
+		oldestAge = append(oldestAge, metrics.Sample{Value: age, Labels: labels})
+	}
+
+	g := m.portfolio
+	g.minScore.Replace(minScore)
+	g.meanScore.Replace(meanScore)
+	g.belowThreshold.Replace(below)
+	g.unscored.Replace(unscored)
+	g.oldestAge.Replace(oldestAge)
+	g.versions.Set(float64(len(grouped)))
+	g.dropped.Set(float64(dropped))
+	g.refreshedAt.Set(float64(now.Unix()))
+	return nil
+}
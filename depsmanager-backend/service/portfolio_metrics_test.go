@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/metrics"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RefreshPortfolioMetrics(t *testing.T) {
+	s, st, _ := newSvc(t)
+	m := NewMetrics(metrics.NewRegistry())
+	now := fixedNow().Unix()
+	conf := depsmanager.PortfolioMetricsConfig{PortfolioScoreThreshold: 4, PortfolioMetricsMaxSeries: 2}
+
+	st.On("ListAllDependencies", mock.Anything).Return([]depsmanager.Dependency{
+		{ProjectTenant: "default", ProjectName: "react", ProjectVersion: "18.3.1", Name: "a", Score: 2, UpdatedAt: now - 3600},
+		{ProjectTenant: "default", ProjectName: "react", ProjectVersion: "18.3.1", Name: "b", Score: 6, UpdatedAt: now - 60},
+		{ProjectTenant: "default", ProjectName: "react", ProjectVersion: "18.3.1", Name: "c"},
+		{ProjectTenant: "team-a", ProjectName: "vue", ProjectVersion: "3.0.0", Name: "a", Score: 3, UpdatedAt: now},
+		{ProjectTenant: "team-a", ProjectName: "vue", ProjectVersion: "3.0.0", Name: "lodash", Score: 1, UpdatedAt: now},
+		{ProjectTenant: "team-a", ProjectName: "svelte", ProjectVersion: "4.0.0", Name: "a", Score: 9, UpdatedAt: now},
+	}, nil).Once()
+	st.On("ListSuppressions", mock.Anything).Return([]depsmanager.Suppression{
+		{ID: 1, Tenant: "team-a", DependencyName: "lodash", ExpiresAt: now + 60},
+	}, nil).Once()
+
+	require.NoError(t, s.RefreshPortfolioMetrics(context.Background(), m, conf))
+
+	body := doJSON(t, m.Handler(), http.MethodGet, "/metrics", nil).Body.String()
+	react := `{tenant="default",project="react",version="18.3.1"}`
+	vue := `{tenant="team-a",project="vue",version="3.0.0"}`
+	for _, line := range []string{
+		"depsmanager_portfolio_min_score" + react + " 2\n",
+		"depsmanager_portfolio_mean_score" + react + " 4\n",
+		"depsmanager_portfolio_dependencies_below_threshold" + react + " 1\n",
+		"depsmanager_portfolio_unscored_dependencies" + react + " 1\n",
+		"depsmanager_portfolio_oldest_scorecard_age_seconds" + react + " 3600\n",
+		// suppressed lodash is left out
+		"depsmanager_portfolio_min_score" + vue + " 3\n",
+		"depsmanager_portfolio_dependencies_below_threshold" + vue + " 1\n",
+		"depsmanager_portfolio_project_versions 3\n",
+		"depsmanager_portfolio_project_versions_dropped 1\n",
+	} {
+		assert.Contains(t, body, line)
+	}
+	// the healthiest project version is over the series cap
+	assert.NotContains(t, body, `project="svelte"`)
+
+	// no cap exports all project versions
+	st.On("ListAllDependencies", mock.Anything).Return([]depsmanager.Dependency{
+		{ProjectTenant: "team-a", ProjectName: "svelte", ProjectVersion: "4.0.0", Name: "a", Score: 9, UpdatedAt: now},
+	}, nil).Once()
+	st.On("ListSuppressions", mock.Anything).Return([]depsmanager.Suppression{}, nil).Once()
+	conf.PortfolioMetricsMaxSeries = 0
+	require.NoError(t, s.RefreshPortfolioMetrics(context.Background(), m, conf))
+	body = doJSON(t, m.Handler(), http.MethodGet, "/metrics", nil).Body.String()
+	assert.Contains(t, body, `depsmanager_portfolio_min_score{tenant="team-a",project="svelte",version="4.0.0"} 9`)
+	assert.Contains(t, body, "depsmanager_portfolio_project_versions_dropped 0\n")
+	st.AssertExpectations(t)
+}
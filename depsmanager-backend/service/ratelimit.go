@@ -0,0 +1,199 @@
+package service
+
+import (
+	"depsmanager"
+	"depsmanager/pkg/auth"
+	customErr "depsmanager/pkg/errors"
+	"depsmanager/pkg/ratelimit"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Classes of rate limit quotas, a route takes a token of either default or expensive.
+const (
+	rateClassDefault   = "default"
+	rateClassExpensive = "expensive"
+	// rateClassAddress is taken by every request before authentication.
+	rateClassAddress = "address"
+)
+
+// expensiveRoutes call deps.dev, they take a token of the expensive quota instead of the default one.
+// Keys are methods and patterns of routes without trailing slash, the middleware runs before routing so it
+// cannot be marked on them.
+var expensiveRoutes = map[string]bool{
+	"POST /api/v1/projects":                          true,
+	"GET /api/v1/projects/versions":                  true,
+	"POST /api/v1/gate":                              true,
+	"GET /api/v2/projects/{name}/versions":           true,
+	"PUT /api/v2/projects/{name}/versions/{version}": true,
+	"POST /api/v2/gate":                              true,
+}
+
+type rateLimiter struct {
+	conf    depsmanager.RateLimitConfig
+	buckets *ratelimit.Buckets
+}
+
+// WithRateLimit limits /api requests per api key, or per client address without one, when conf is enabled.
+func WithRateLimit(conf depsmanager.RateLimitConfig) func(a *API) {
+	return func(a *API) {
+		if conf.RateLimitEnabled {
+			a.rateLimit = &rateLimiter{conf: conf, buckets: ratelimit.NewBuckets(time.Now)}
+		}
+	}
+}
+
+// limits returns limits of class for the caller: of its api key, or of the client address without
+// authentication, and of its tenant with a tenant override. An api key override replaces the key quota.
+func (l *rateLimiter) limits(r *http.Request, class string) []ratelimit.Limit {
+	pick := func(o depsmanager.RateLimitOverride) *depsmanager.RateLimitQuota {
+		if class == rateClassExpensive {
+			return o.Expensive
+		}
+		return o.Default
+	}
+
+	q := l.conf.RateLimitDefault
+	if class == rateClassExpensive {
+		q = l.conf.RateLimitExpensive
+	}
+	id, authenticated := auth.FromContext(r.Context())
+	if !authenticated {
+		return quotaLimits(ratelimit.Limit{Key: class + "|ip:" + clientAddr(r), Rate: q.Rate, Burst: q.Burst})
+	}
+
+	// the bootstrap key and client certificates have no key id, their names are unique
+	client := "identity:" + id.Name
+	if id.KeyID != 0 {
+		client = "key:" + strconv.FormatInt(id.KeyID, 10)
+	}
+	var tenantQuota depsmanager.RateLimitQuota
+	for _, o := range l.conf.RateLimitOverrides {
+		oq := pick(o)
+		switch {
+		case oq == nil:
+		case o.APIKeyID != 0 && o.APIKeyID == id.KeyID:
+			q = *oq
+		case o.Tenant != "" && o.Tenant == id.Tenant:
+			tenantQuota = *oq
+		}
+	}
+	return quotaLimits(
+		ratelimit.Limit{Key: class + "|" + client, Rate: q.Rate, Burst: q.Burst},
+		ratelimit.Limit{Key: class + "|tenant:" + id.Tenant, Rate: tenantQuota.Rate, Burst: tenantQuota.Burst},
+	)
+}
+
+// quotaLimits drops unlimited ones of limits.
+func quotaLimits(limits ...ratelimit.Limit) []ratelimit.Limit {
+	kept := limits[:0]
+	for _, l := range limits {
+		if l.Rate > 0 {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
+
+// limitAddresses takes a token of the address quota of the client address before authentication, so requests
+// without a valid api key are limited too. Requests over the quota get 429 with Retry-After and RateLimit-*
+// headers, the others report the quota of the caller, see limitRequests. It is a no-op without WithRateLimit.
+func (a *API) limitAddresses(next http.Handler) http.Handler {
+	if a.rateLimit == nil || a.rateLimit.conf.RateLimitAddress.Rate <= 0 {
+		return next
+	}
+
+	q := a.rateLimit.conf.RateLimitAddress
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		customErr.HandleError(func(w http.ResponseWriter, r *http.Request) error {
+			res := a.rateLimit.buckets.Allow(rateClassAddress+"|"+clientAddr(r), q.Rate, q.Burst)
+			if !res.Allowed {
+				return a.rejectRequest(w, rateClassAddress, res)
+			}
+			next.ServeHTTP(w, r)
+			return nil
+		})(w, r)
+	})
+}
+
+// limitRequests takes a token of the quota of the route class for the caller and its tenant, routes of
+// expensiveRoutes are found in routes. Requests over a quota get 429 with Retry-After. Every response reports
+// the quota closest to running out in RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers.
+// It is a no-op without WithRateLimit.
+func (a *API) limitRequests(routes chi.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if a.rateLimit == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			customErr.HandleError(func(w http.ResponseWriter, r *http.Request) error {
+				class := rateClassDefault
+				if expensiveRoutes[r.Method+" "+routePattern(routes, r)] {
+					class = rateClassExpensive
+				}
+				limits := a.rateLimit.limits(r, class)
+				if len(limits) == 0 {
+					next.ServeHTTP(w, r)
+					return nil
+				}
+
+				res := a.rateLimit.buckets.AllowAll(limits...)
+				if !res.Allowed {
+					return a.rejectRequest(w, class, res)
+				}
+				setRateLimitHeaders(w, res)
+				next.ServeHTTP(w, r)
+				return nil
+			})(w, r)
+		})
+	}
+}
+
+// rejectRequest returns the 429 error of a request over the quota of class reported in res.
+func (a *API) rejectRequest(w http.ResponseWriter, class string, res ratelimit.Result) error {
+	if a.metrics != nil {
+		a.metrics.rateLimited.Inc(class)
+	}
+	retryAfter := max(1, ceilSeconds(res.RetryAfter))
+	setRateLimitHeaders(w, res)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	return customErr.NewTooManyRequests(fmt.Errorf("%w: %s quota, retry in %ds", depsmanager.ErrRateLimited, class, retryAfter))
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, res ratelimit.Result) {
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+}
+
+// routePattern returns pattern of the route of r in routes without trailing slash, the router serves routes
+// with and without it. Escaped paths are matched like the router does.
+func routePattern(routes chi.Routes, r *http.Request) string {
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	return strings.TrimSuffix(routes.Find(chi.NewRouteContext(), r.Method, path), "/")
+}
+
+// clientAddr returns host of the connection, forwarding headers are not trusted.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"depsmanager"
+	"depsmanager/pkg/metrics"
+	"depsmanager/service/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRateLimit(t *testing.T, conf depsmanager.RateLimitConfig) (http.Handler, *mocks.Service, *Metrics) {
+	t.Helper()
+	svc := new(mocks.Service)
+	m := NewMetrics(metrics.NewRegistry())
+	conf.RateLimitEnabled = true
+	a := NewAPI(svc, WithAuth(true), WithMetrics(m), WithRateLimit(conf))
+
+	// key names are unique only within a tenant
+	for _, id := range []depsmanager.Identity{
+		{Name: "ci", Role: depsmanager.RoleEditor, Tenant: "team-a", KeyID: 1},
+		{Name: "web", Role: depsmanager.RoleEditor, Tenant: "team-a", KeyID: 2},
+		{Name: "ci", Role: depsmanager.RoleEditor, Tenant: "team-b", KeyID: 3},
+	} {
+		svc.On("Authenticate", mock.Anything, id.Tenant+"_"+id.Name).Return(id, nil).Maybe()
+	}
+	svc.On("Authenticate", mock.Anything, mock.Anything).Return(depsmanager.Identity{}, depsmanager.ErrUnauthorized).Maybe()
+	svc.On("ListProjects", mock.Anything, mock.Anything).Return([]depsmanager.Project{}, nil).Maybe()
+	svc.On("FetchAndStoreProjectDependencies", mock.Anything, mock.Anything, "18.3.1").Return(nil).Maybe()
+	return a.GetHandler(), svc, m
+}
+
+const fetchPath = "/api/v2/projects/react/versions/18.3.1"
+
+func TestRateLimit_TooManyRequests(t *testing.T) {
+	h, _, m := setupRateLimit(t, depsmanager.RateLimitConfig{
+		RateLimitDefault:   depsmanager.RateLimitQuota{Rate: 0.1, Burst: 2},
+		RateLimitExpensive: depsmanager.RateLimitQuota{Rate: 1, Burst: 10},
+	})
+
+	rr := doAuth(h, http.MethodGet, "/api/v2/projects", "team-a_ci")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "10", rr.Header().Get("RateLimit-Reset"))
+
+	doAuth(h, http.MethodGet, "/api/v2/projects", "team-a_ci")
+	rr = doAuth(h, http.MethodGet, "/api/v2/projects", "team-a_ci")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "10", rr.Header().Get("Retry-After"))
+	assert.Contains(t, rr.Body.String(), `"code":"rate_limited"`)
+
+	// other keys keep their own budget, probes are not limited
+	assert.Equal(t, http.StatusOK, doAuth(h, http.MethodGet, "/api/v2/projects", "team-a_web").Code)
+	assert.Equal(t, http.StatusOK, doAuth(h, http.MethodGet, "/healthz", "").Code)
+
+	body := doJSON(t, m.Handler(), http.MethodGet, "/metrics", nil).Body.String()
+	assert.Contains(t, body, `depsmanager_http_rate_limited_total{class="default"} 1`)
+}
+
+func TestRateLimit_ExpensiveRoutes(t *testing.T) {
+	h, svc, m := setupRateLimit(t, depsmanager.RateLimitConfig{
+		RateLimitDefault:   depsmanager.RateLimitQuota{Rate: 0.01, Burst: 1},
+		RateLimitExpensive: depsmanager.RateLimitQuota{Rate: 0.01, Burst: 2},
+	})
+
+	// expensive routes take only the expensive quota, escaped names are matched like the router does
+	for _, path := range []string{fetchPath, "/api/v2/projects/%40babel%2Fcore/versions/18.3.1"} {
+		rr := doAuth(h, http.MethodPut, path, "team-a_ci")
+		require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+		assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+	}
+	rr := doAuth(h, http.MethodPut, fetchPath, "team-a_ci")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	svc.AssertNumberOfCalls(t, "FetchAndStoreProjectDependencies", 2)
+
+	// cheap reads still pass with the expensive quota spent
+	rr = doAuth(h, http.MethodGet, "/api/v2/projects", "team-a_ci")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+
+	body := doJSON(t, m.Handler(), http.MethodGet, "/metrics", nil).Body.String()
+	assert.Contains(t, body, `depsmanager_http_rate_limited_total{class="expensive"} 1`)
+	assert.NotContains(t, body, `depsmanager_http_rate_limited_total{class="default"}`)
+}
+
+func TestRateLimit_ExpensiveRoutesTrailingSlash(t *testing.T) {
+	h, svc, m := setupRateLimit(t, depsmanager.RateLimitConfig{
+		RateLimitDefault:   depsmanager.RateLimitQuota{Rate: 1, Burst: 10},
+		RateLimitExpensive: depsmanager.RateLimitQuota{Rate: 0.01, Burst: 1},
+	})
+	svc.On("FetchAndStoreProjectDependencies", mock.Anything, "react", "18.3.0").Return(nil).Maybe()
+	require.Equal(t, http.StatusNoContent, doAuth(h, http.MethodPut, fetchPath, "team-a_ci").Code)
+
+	// the router serves the fetch routes with a trailing slash too, they take the spent expensive quota
+	for _, req := range []struct{ method, path, body string }{
+		{http.MethodPost, "/api/v1/projects/", `{"project_name":"react","version":"18.3.0"}`},
+		{http.MethodPut, fetchPath + "/", ""},
+	} {
+		r := httptest.NewRequest(req.method, req.path, strings.NewReader(req.body))
+		r.Header.Set("Authorization", "Bearer team-a_ci")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		require.Equal(t, http.StatusTooManyRequests, rr.Code, req.path)
+	}
+	svc.AssertNumberOfCalls(t, "FetchAndStoreProjectDependencies", 1)
+
+	body := doJSON(t, m.Handler(), http.MethodGet, "/metrics", nil).Body.String()
+	assert.Contains(t, body, `depsmanager_http_rate_limited_total{class="expensive"} 2`)
+}
+
+func TestRateLimit_ExpensiveRoutesExist(t *testing.T) {
+	a := NewAPI(new(mocks.Service))
+	routes := make(map[string]bool)
+	err := chi.Walk(a.GetHandler(), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes[method+" "+strings.TrimSuffix(route, "/")] = true
+		return nil
+	})
+	require.NoError(t, err)
+	for route := range expensiveRoutes {
+		assert.True(t, routes[route], route)
+	}
+}
+
+func TestRateLimit_Overrides(t *testing.T) {
+	h, _, _ := setupRateLimit(t, depsmanager.RateLimitConfig{
+		RateLimitDefault: depsmanager.RateLimitQuota{Rate: 1, Burst: 10},
+		RateLimitOverrides: []depsmanager.RateLimitOverride{
+			{Tenant: "team-a", Default: &depsmanager.RateLimitQuota{Rate: 0.01, Burst: 3}},
+			{APIKeyID: 3, Default: &depsmanager.RateLimitQuota{Rate: 1, Burst: 50}},
+		},
+	})
+
+	// the key override is of the ci key of team-b, not of the one of team-a
+	rr := doAuth(h, http.MethodGet, "/api/v2/projects", "team-b_ci")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "50", rr.Header().Get("RateLimit-Limit"))
+
+	// keys of team-a share the tenant quota, headers report the one closer to running out
+	rr = doAuth(h, http.MethodGet, "/api/v2/projects", "team-a_ci")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "3", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "2", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, http.StatusOK, doAuth(h, http.MethodGet, "/api/v2/projects", "team-a_web").Code)
+	assert.Equal(t, http.StatusOK, doAuth(h, http.MethodGet, "/api/v2/projects", "team-a_ci").Code)
+	rr = doAuth(h, http.MethodGet, "/api/v2/projects", "team-a_web")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "100", rr.Header().Get("Retry-After"))
+}
+
+func TestRateLimit_AddressBeforeAuthentication(t *testing.T) {
+	h, _, m := setupRateLimit(t, depsmanager.RateLimitConfig{
+		RateLimitDefault: depsmanager.RateLimitQuota{Rate: 1, Burst: 10},
+		RateLimitAddress: depsmanager.RateLimitQuota{Rate: 0.01, Burst: 2},
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, doAuth(h, http.MethodGet, "/api/v2/projects", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, doAuth(h, http.MethodGet, "/api/v2/projects", "dm_guess").Code)
+	rr := doAuth(h, http.MethodGet, "/api/v2/projects", "team-a_ci")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "100", rr.Header().Get("Retry-After"))
+
+	body := doJSON(t, m.Handler(), http.MethodGet, "/metrics", nil).Body.String()
+	assert.Contains(t, body, `depsmanager_http_rate_limited_total{class="address"} 1`)
+}
+
+func TestRateLimit_ClientAddress(t *testing.T) {
+	svc := new(mocks.Service)
+	a := NewAPI(svc, WithRateLimit(depsmanager.RateLimitConfig{
+		RateLimitEnabled: true,
+		RateLimitDefault: depsmanager.RateLimitQuota{Rate: 0.1, Burst: 1},
+	}))
+	h := a.GetHandler()
+	svc.On("ListProjects", mock.Anything, mock.Anything).Return([]depsmanager.Project{}, nil)
+
+	get := func(addr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/projects", nil)
+		req.RemoteAddr = addr
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusOK, get("10.0.0.1:5000"))
+	assert.Equal(t, http.StatusTooManyRequests, get("10.0.0.1:5001"))
+	assert.Equal(t, http.StatusOK, get("10.0.0.2:5000"))
+}
+
+func TestRateLimit_Disabled(t *testing.T) {
+	h, svc := setup(t)
+	svc.On("ListProjects", mock.Anything, mock.Anything).Return([]depsmanager.Project{}, nil)
+	rr := doJSON(t, h, http.MethodGet, "/api/v2/projects", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("RateLimit-Limit"))
+}
@@ -3,16 +3,29 @@ package service
 import (
 	"context"
 	"depsmanager"
+	"depsmanager/pkg/labels"
+	"depsmanager/pkg/summary"
+	"depsmanager/pkg/suppression"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sort"
+	"sync"
 	"time"
 )
 
 const SystemNPM = "npm"
 
+// maxBatchRequests is the most requests deps.dev accepts in one versionbatch or projectbatch call.
+const maxBatchRequests = 5000
+
 type Storage interface {
 	StoreDependencies(ctx context.Context, deps depsmanager.ProjectDependencyRecord) error
 	DeleteProject(ctx context.Context, projectName, version string) error
 	ListProjectDependencies(ctx context.Context, projectName, version string) ([]depsmanager.Dependency, error)
+	ListAllDependencies(ctx context.Context) ([]depsmanager.Dependency, error)
 	ListProjects(ctx context.Context) ([]depsmanager.Project, error)
 	GetDependenciesByExactScore(ctx context.Context, score float64) ([]string, error)
 	GetProjectsByDependency(ctx context.Context, depName string) ([]depsmanager.Project, error)
@@ -20,6 +33,32 @@ type Storage interface {
 	AddDependency(ctx context.Context, projectName, version string, dep depsmanager.Dependency) error
 	UpdateDependency(ctx context.Context, projectName, version string, dep depsmanager.Dependency) error
 	DeleteDependency(ctx context.Context, projectName, version, depName string) error
+	ClearScoreOverride(ctx context.Context, projectName, version, depName string) error
+
+	SavePolicy(ctx context.Context, p depsmanager.Policy) error
+	ListPolicies(ctx context.Context, projectName string) ([]depsmanager.Policy, error)
+	DeletePolicy(ctx context.Context, projectName, name string) error
+	StorePolicyEvaluation(ctx context.Context, eval depsmanager.PolicyEvaluation) error
+	GetPolicyEvaluation(ctx context.Context, projectName, version string) (depsmanager.PolicyEvaluation, error)
+
+	CreateAPIKey(ctx context.Context, key depsmanager.APIKey) (depsmanager.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]depsmanager.APIKey, error)
+	GetActiveAPIKey(ctx context.Context, keyHash string) (depsmanager.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64, revokedAt int64) error
+
+	CreateTenant(ctx context.Context, t depsmanager.Tenant) error
+	ListTenants(ctx context.Context) ([]depsmanager.Tenant, error)
+	GetTenant(ctx context.Context, name string) (depsmanager.Tenant, error)
+
+	ListAudit(ctx context.Context, filter depsmanager.AuditFilter) ([]depsmanager.AuditEntry, error)
+
+	CreateSuppression(ctx context.Context, s depsmanager.Suppression) (depsmanager.Suppression, error)
+	ListSuppressions(ctx context.Context) ([]depsmanager.Suppression, error)
+	DeleteSuppression(ctx context.Context, id int64) error
+
+	GetProjectMetadata(ctx context.Context, projectName string) (depsmanager.ProjectMetadata, error)
+	SaveProjectMetadata(ctx context.Context, m depsmanager.ProjectMetadata) error
+	DeleteProjectMetadata(ctx context.Context, projectName string) error
 }
 
 type DepsClient interface {
@@ -32,6 +71,12 @@ type service struct {
 	storage    Storage
 	depsClient DepsClient
 
+	// mu guards settings replaced by Reload
+	mu             sync.RWMutex
+	gateThresholds depsmanager.GateThresholds
+	configPolicies []depsmanager.Policy
+	bootstrapKey   string
+
 	tNow func() time.Time
 }
 
@@ -62,13 +107,28 @@ func WithTimeNow(tNow func() time.Time) func(s *service) {
 	}
 }
 
+// FetchAndStoreProjectDependencies fetches and stores dependencies of the project,
+// then evaluates policies against them. Evaluation failure does not fail the fetch.
 func (s *service) FetchAndStoreProjectDependencies(ctx context.Context, projectName, version string) error {
+	if err := s.fetchAndStoreProjectDependencies(ctx, projectName, version); err != nil {
+		return err
+	}
+
+	if _, err := s.EvaluatePolicies(ctx, projectName, version); err != nil {
+		slog.WarnContext(ctx, "policy evaluation after fetch failed", "project", projectName, "version", version, "error", err)
+	}
+
+	return nil
+}
+
+func (s *service) fetchAndStoreProjectDependencies(ctx context.Context, projectName, version string) error {
 	dependencies, err := s.depsClient.GetProjectDependencies(ctx, SystemNPM, projectName, version)
 	if err != nil {
 		return fmt.Errorf("s.depsClient.GetProjectDependencies: %w", err)
 	}
 
 	var projectDependencies []depsmanager.ProjectDependencies
+	relations := make(map[string]string)
 	seen := make(map[string]struct{})
 	for _, deps := range dependencies.Nodes {
 		if deps.Relation == "SELF" {
@@ -80,6 +140,7 @@ func (s *service) FetchAndStoreProjectDependencies(ctx context.Context, projectN
 			continue
 		}
 		seen[key] = struct{}{}
+		relations[deps.VersionKey.Name] = deps.Relation
 		projectDependencies = append(projectDependencies, depsmanager.ProjectDependencies{
 			System:  deps.VersionKey.System,
 			Name:    deps.VersionKey.Name,
@@ -95,11 +156,30 @@ func (s *service) FetchAndStoreProjectDependencies(ctx context.Context, projectN
 		return nil
 	}
 
-	batch, err := s.depsClient.GetVersionsBatch(ctx, projectDependencies)
+	dependencyScores, err := s.scoreDependencies(ctx, projectDependencies, relations)
 	if err != nil {
 		return err
 	}
 
+	if err = s.storeProjectWithDependencies(ctx, projectName, version, dependencyScores); err != nil {
+		return fmt.Errorf("s.storeProjectWithDependencies(): %w", err)
+	}
+
+	return nil
+}
+
+// scoreDependencies resolves source repositories of projectDependencies and returns their scorecards.
+// Dependencies without a source repository are left out.
+func (s *service) scoreDependencies(ctx context.Context, projectDependencies []depsmanager.ProjectDependencies, relations map[string]string) ([]depsmanager.Dependency, error) {
+	batch, err := s.getVersionsBatch(ctx, projectDependencies)
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[string]string, len(projectDependencies))
+	for _, p := range projectDependencies {
+		versions[p.Name] = p.Version
+	}
+
 	deps := make(map[string][]string)
 	for _, b := range batch.Responses {
 		for _, relatedProjects := range b.Version.RelatedProjects {
@@ -110,11 +190,8 @@ func (s *service) FetchAndStoreProjectDependencies(ctx context.Context, projectN
 		}
 	}
 	if len(deps) == 0 {
-		// No mappable repos - store empty deps for this project/version
-		if err := s.storeProjectWithDependencies(ctx, projectName, version, nil); err != nil {
-			return fmt.Errorf("s.storeProjectWithDependencies(): %w", err)
-		}
-		return nil
+		// No mappable repos
+		return nil, nil
 	}
 
 	var uniqueDepsLinks []string
@@ -122,9 +199,9 @@ func (s *service) FetchAndStoreProjectDependencies(ctx context.Context, projectN
 		uniqueDepsLinks = append(uniqueDepsLinks, k)
 	}
 
-	projectsBatch, err := s.depsClient.GetProjectsBatch(ctx, uniqueDepsLinks)
+	projectsBatch, err := s.getProjectsBatch(ctx, uniqueDepsLinks)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var dependencyScores []depsmanager.Dependency
@@ -139,7 +216,9 @@ func (s *service) FetchAndStoreProjectDependencies(ctx context.Context, projectN
 					dependencyScores = append(dependencyScores, depsmanager.Dependency{
 						Score:     pBatch.Project.Scorecard.OverallScore,
 						Name:      name,
+						Version:   versions[name],
 						UpdatedAt: updatedAt,
+						Relation:  relations[name],
 					})
 				}
 				break
@@ -147,11 +226,33 @@ func (s *service) FetchAndStoreProjectDependencies(ctx context.Context, projectN
 		}
 	}
 
-	if err = s.storeProjectWithDependencies(ctx, projectName, version, dependencyScores); err != nil {
-		return fmt.Errorf("s.storeProjectWithDependencies(): %w", err)
+	return dependencyScores, nil
+}
+
+// getVersionsBatch splits projects into batches of at most maxBatchRequests and merges their responses.
+func (s *service) getVersionsBatch(ctx context.Context, projects []depsmanager.ProjectDependencies) (*depsmanager.DepsGetVersionsBatchResp, error) {
+	merged := &depsmanager.DepsGetVersionsBatchResp{}
+	for chunk := range slices.Chunk(projects, maxBatchRequests) {
+		batch, err := s.depsClient.GetVersionsBatch(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		merged.Responses = append(merged.Responses, batch.Responses...)
 	}
+	return merged, nil
+}
 
-	return nil
+// getProjectsBatch splits ids into batches of at most maxBatchRequests and merges their responses.
+func (s *service) getProjectsBatch(ctx context.Context, ids []string) (*depsmanager.DepsGetProjectBatchResp, error) {
+	merged := &depsmanager.DepsGetProjectBatchResp{}
+	for chunk := range slices.Chunk(ids, maxBatchRequests) {
+		batch, err := s.depsClient.GetProjectsBatch(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		merged.Responses = append(merged.Responses, batch.Responses...)
+	}
+	return merged, nil
 }
 
 func (s *service) ListDependencies(ctx context.Context, projectName, version string) (depsmanager.ListDependenciesResponse, error) {
@@ -159,6 +260,9 @@ func (s *service) ListDependencies(ctx context.Context, projectName, version str
 	if err != nil {
 		return depsmanager.ListDependenciesResponse{}, fmt.Errorf("s.storage.ListProjectDependencies() projectName: %s, error: %w", projectName, err)
 	}
+	if deps, err = s.applySuppressions(ctx, deps); err != nil {
+		return depsmanager.ListDependenciesResponse{}, err
+	}
 
 	return depsmanager.ListDependenciesResponse{
 		ProjectName:  projectName,
@@ -166,6 +270,37 @@ func (s *service) ListDependencies(ctx context.Context, projectName, version str
 	}, nil
 }
 
+func (s *service) GetProjectSummary(ctx context.Context, projectName, version string) (depsmanager.RiskSummary, error) {
+	deps, err := s.storage.ListProjectDependencies(ctx, projectName, version)
+	if err != nil {
+		return depsmanager.RiskSummary{}, fmt.Errorf("s.storage.ListProjectDependencies() projectName: %s, error: %w", projectName, err)
+	}
+	if deps, err = s.applySuppressions(ctx, deps); err != nil {
+		return depsmanager.RiskSummary{}, err
+	}
+
+	result := summary.Summarize(deps)
+	result.ProjectName = projectName
+	result.Version = version
+	return result, nil
+}
+
+// GetPortfolioSummary summarizes dependencies of all projects matching selector.
+func (s *service) GetPortfolioSummary(ctx context.Context, selector labels.Selector) (depsmanager.RiskSummary, error) {
+	deps, err := s.storage.ListAllDependencies(ctx)
+	if err != nil {
+		return depsmanager.RiskSummary{}, fmt.Errorf("s.storage.ListAllDependencies(): %w", err)
+	}
+	if deps, err = s.selectDependencies(ctx, deps, selector); err != nil {
+		return depsmanager.RiskSummary{}, err
+	}
+	if deps, err = s.applySuppressions(ctx, deps); err != nil {
+		return depsmanager.RiskSummary{}, err
+	}
+
+	return summary.Summarize(deps), nil
+}
+
 func (s *service) DeleteProject(ctx context.Context, projectName, version string) error {
 	if err := s.storage.DeleteProject(ctx, projectName, version); err != nil {
 		return fmt.Errorf("s.storage.DeleteProject() projectName: %s, error: %w", projectName, err)
@@ -174,8 +309,12 @@ func (s *service) DeleteProject(ctx context.Context, projectName, version string
 	return nil
 }
 
-func (s *service) ListProjects(ctx context.Context) ([]depsmanager.Project, error) {
-	return s.storage.ListProjects(ctx)
+func (s *service) ListProjects(ctx context.Context, selector labels.Selector) ([]depsmanager.Project, error) {
+	projects, err := s.storage.ListProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return selectProjects(projects, selector), nil
 }
 
 func (s *service) ListProjectVersions(ctx context.Context, projectName string) ([]string, error) {
@@ -191,12 +330,45 @@ func (s *service) ListProjectVersions(ctx context.Context, projectName string) (
 	return versions, nil
 }
 
-func (s *service) GetDependenciesByExactScore(ctx context.Context, score float64) ([]string, error) {
-	return s.storage.GetDependenciesByExactScore(ctx, score)
+// GetDependenciesByExactScore returns names of dependencies with score, suppressed ones are left out.
+// A name is returned while at least one of its project versions is not suppressed and matches selector.
+func (s *service) GetDependenciesByExactScore(ctx context.Context, score float64, selector labels.Selector) ([]string, error) {
+	active, err := s.activeSuppressions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(active) == 0 && selector.Empty() {
+		return s.storage.GetDependenciesByExactScore(ctx, score)
+	}
+
+	// version ranges and labels cannot be matched in SQL, the scores are filtered here
+	deps, err := s.storage.ListAllDependencies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.storage.ListAllDependencies(): %w", err)
+	}
+	if deps, err = s.selectDependencies(ctx, deps, selector); err != nil {
+		return nil, err
+	}
+	const eps = 1e-9
+	seen := make(map[string]struct{})
+	var names []string
+	for _, d := range suppression.Unsuppressed(suppression.Apply(active, deps)) {
+		if _, ok := seen[d.Name]; ok || math.Abs(d.Score-score) > eps {
+			continue
+		}
+		seen[d.Name] = struct{}{}
+		names = append(names, d.Name)
+	}
+	sort.Strings(names)
+	return names, nil
 }
 
-func (s *service) GetProjectsByDependency(ctx context.Context, depName string) ([]depsmanager.Project, error) {
-	return s.storage.GetProjectsByDependency(ctx, depName)
+func (s *service) GetProjectsByDependency(ctx context.Context, depName string, selector labels.Selector) ([]depsmanager.Project, error) {
+	projects, err := s.storage.GetProjectsByDependency(ctx, depName)
+	if err != nil {
+		return nil, err
+	}
+	return selectProjects(projects, selector), nil
 }
 
 func (s *service) AddDependency(ctx context.Context, projectName, version string, dep depsmanager.Dependency) error {
@@ -204,15 +376,38 @@ func (s *service) AddDependency(ctx context.Context, projectName, version string
 	return s.storage.AddDependency(ctx, projectName, version, dep)
 }
 
+// UpdateDependency changes score of manual dependency, fetched one gets an override ending at dep.Override.ExpiresAt.
 func (s *service) UpdateDependency(ctx context.Context, projectName, version string, dep depsmanager.Dependency) error {
 	dep.UpdatedAt = time.Now().Unix()
 	return s.storage.UpdateDependency(ctx, projectName, version, dep)
 }
 
+// UpsertDependency adds manual dependency to the project version or updates it when it already exists,
+// see UpdateDependency. It reports whether the dependency was created.
+func (s *service) UpsertDependency(ctx context.Context, projectName, version string, dep depsmanager.Dependency) (bool, error) {
+	err := s.AddDependency(ctx, projectName, version, dep)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, depsmanager.ErrDependencyAlreadyExists) {
+		return false, err
+	}
+
+	if err := s.UpdateDependency(ctx, projectName, version, dep); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
 func (s *service) DeleteDependency(ctx context.Context, projectName, version, depName string) error {
 	return s.storage.DeleteDependency(ctx, projectName, version, depName)
 }
 
+// ClearScoreOverride makes score of fetched dependency follow upstream again.
+func (s *service) ClearScoreOverride(ctx context.Context, projectName, version, depName string) error {
+	return s.storage.ClearScoreOverride(ctx, projectName, version, depName)
+}
+
 func (s *service) storeProjectWithDependencies(ctx context.Context, projectName, version string, dependencyScores []depsmanager.Dependency) error {
 	if err := s.storage.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
 		Project: depsmanager.Project{
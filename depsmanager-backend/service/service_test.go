@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"depsmanager"
+	"depsmanager/pkg/labels"
 	"depsmanager/service/mocks"
 	"encoding/json"
 	"errors"
@@ -81,6 +82,16 @@ func matchRecord(project, version string, expectedDeps []depsmanager.Dependency,
 	})
 }
 
+// expectPolicyEvaluation sets up storage calls done by policy evaluation after successful fetch.
+func expectPolicyEvaluation(st *mocks.Storage, ctx context.Context, project, version string, verdict string) {
+	st.On("ListProjectDependencies", ctx, project, version).Return([]depsmanager.Dependency{}, nil).Once()
+	st.On("ListSuppressions", ctx).Return([]depsmanager.Suppression{}, nil).Once()
+	st.On("ListPolicies", ctx, project).Return([]depsmanager.Policy{}, nil).Once()
+	st.On("StorePolicyEvaluation", ctx, mock.MatchedBy(func(eval depsmanager.PolicyEvaluation) bool {
+		return eval.ProjectName == project && eval.Version == version && eval.Verdict == verdict
+	})).Return(nil).Once()
+}
+
 // --- FetchAndStoreProjectDependencies ---
 
 func TestService_FetchAndStore_GetProjectDependencies_Error(t *testing.T) {
@@ -106,6 +117,7 @@ func TestService_FetchAndStore_NoDeps_SavesEmpty_WithInjectedTime(t *testing.T)
 
 	st.On("StoreDependencies", ctx, matchRecord("p", "1.0.0", nil, fixedNow().Unix())).
 		Return(nil).Once()
+	expectPolicyEvaluation(st, ctx, "p", "1.0.0", depsmanager.VerdictPass)
 
 	require.NoError(t, s.FetchAndStoreProjectDependencies(ctx, "p", "1.0.0"))
 	st.AssertExpectations(t)
@@ -144,6 +156,7 @@ func TestService_FetchAndStore_NoSourceRepo_SavesEmpty(t *testing.T) {
 
 	st.On("StoreDependencies", ctx, matchRecord("p", "1.0.0", nil, fixedNow().Unix())).
 		Return(nil).Once()
+	expectPolicyEvaluation(st, ctx, "p", "1.0.0", depsmanager.VerdictPass)
 
 	require.NoError(t, s.FetchAndStoreProjectDependencies(ctx, "p", "1.0.0"))
 	st.AssertExpectations(t)
@@ -212,6 +225,7 @@ func TestService_FetchAndStore_Success_SavesDeps_WithInjectedTime(t *testing.T)
 	}
 	st.On("StoreDependencies", ctx, matchRecord("p", "1.0.0", expected, fixedNow().Unix())).
 		Return(nil).Once()
+	expectPolicyEvaluation(st, ctx, "p", "1.0.0", depsmanager.VerdictPass)
 
 	require.NoError(t, s.FetchAndStoreProjectDependencies(ctx, "p", "1.0.0"))
 	st.AssertExpectations(t)
@@ -245,6 +259,7 @@ func TestService_ListDependencies_Success(t *testing.T) {
 	out := []depsmanager.Dependency{{Name: "x", Score: 1.2, UpdatedAt: 123}}
 	st.On("ListProjectDependencies", ctx, "p", "1.0.0").
 		Return(out, nil).Once()
+	st.On("ListSuppressions", ctx).Return([]depsmanager.Suppression{}, nil).Once()
 
 	resp, err := s.ListDependencies(ctx, "p", "1.0.0")
 	require.NoError(t, err)
@@ -303,7 +318,7 @@ func TestService_ListProjects_Success(t *testing.T) {
 	projects := []depsmanager.Project{{Name: "a", Version: "1.0.0", UpdatedAt: 1}}
 	st.On("ListProjects", ctx).Return(projects, nil).Once()
 
-	got, err := s.ListProjects(ctx)
+	got, err := s.ListProjects(ctx, labels.Selector{})
 	require.NoError(t, err)
 	require.Len(t, got, 1)
 	require.Equal(t, "a", got[0].Name)
@@ -317,7 +332,7 @@ func TestService_ListProjects_StorageError(t *testing.T) {
 
 	st.On("ListProjects", ctx).Return(nil, errors.New("db error")).Once()
 
-	_, err := s.ListProjects(ctx)
+	_, err := s.ListProjects(ctx, labels.Selector{})
 	require.Error(t, err)
 	st.AssertExpectations(t)
 }
@@ -355,3 +370,111 @@ func TestService_ListProjectVersions_ClientError(t *testing.T) {
 	require.Contains(t, err.Error(), "s.depsClient.GetProjectVersions")
 	dc.AssertExpectations(t)
 }
+
+// --- Summaries ---
+
+func TestService_GetProjectSummary_Success(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	st.On("ListProjectDependencies", ctx, "p", "1.0.0").Return([]depsmanager.Dependency{
+		{Name: "a", Score: 4, UpdatedAt: 100},
+		{Name: "b", Score: 8, UpdatedAt: 200},
+		{Name: "c", Score: 0, UpdatedAt: 0},
+	}, nil).Once()
+	st.On("ListSuppressions", ctx).Return([]depsmanager.Suppression{}, nil).Once()
+
+	got, err := s.GetProjectSummary(ctx, "p", "1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "p", got.ProjectName)
+	require.Equal(t, "1.0.0", got.Version)
+	require.Equal(t, 3, got.DependencyCount)
+	require.Equal(t, 1, got.UnscoredCount)
+	require.InDelta(t, 6.0, got.MeanScore, 1e-9)
+	require.InDelta(t, 4.0, got.MinScore, 1e-9)
+	require.Equal(t, int64(100), got.OldestScorecardAt)
+	st.AssertExpectations(t)
+}
+
+func TestService_GetProjectSummary_StorageError(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	st.On("ListProjectDependencies", ctx, "p", "1.0.0").
+		Return(nil, depsmanager.ErrProjectNotFound).Once()
+
+	_, err := s.GetProjectSummary(ctx, "p", "1.0.0")
+	require.ErrorIs(t, err, depsmanager.ErrProjectNotFound)
+	st.AssertExpectations(t)
+}
+
+func TestService_GetPortfolioSummary_Success(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	st.On("ListAllDependencies", ctx).Return([]depsmanager.Dependency{
+		{ProjectID: 1, Name: "a", Score: 3, UpdatedAt: 100},
+		{ProjectID: 2, Name: "a", Score: 5, UpdatedAt: 100},
+	}, nil).Once()
+	st.On("ListSuppressions", ctx).Return([]depsmanager.Suppression{}, nil).Once()
+
+	got, err := s.GetPortfolioSummary(ctx, labels.Selector{})
+	require.NoError(t, err)
+	require.Empty(t, got.ProjectName)
+	require.Equal(t, 2, got.DependencyCount)
+	require.InDelta(t, 4.0, got.MedianScore, 1e-9)
+	st.AssertExpectations(t)
+}
+
+func TestService_GetPortfolioSummary_StorageError(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	st.On("ListAllDependencies", ctx).Return(nil, errors.New("db error")).Once()
+
+	_, err := s.GetPortfolioSummary(ctx, labels.Selector{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "s.storage.ListAllDependencies")
+	st.AssertExpectations(t)
+}
+
+// --- UpsertDependency ---
+
+func TestService_UpsertDependency_Created(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	st.On("AddDependency", ctx, "p", "1.0.0", mock.MatchedBy(func(d depsmanager.Dependency) bool {
+		return d.Name == "@babel/core" && d.Score == 5
+	})).Return(nil).Once()
+
+	created, err := s.UpsertDependency(ctx, "p", "1.0.0", depsmanager.Dependency{Name: "@babel/core", Score: 5})
+	require.NoError(t, err)
+	require.True(t, created)
+	st.AssertExpectations(t)
+}
+
+func TestService_UpsertDependency_Updated(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	st.On("AddDependency", ctx, "p", "1.0.0", mock.Anything).
+		Return(fmt.Errorf("storage: %w", depsmanager.ErrDependencyAlreadyExists)).Once()
+	st.On("UpdateDependency", ctx, "p", "1.0.0", mock.Anything).Return(nil).Once()
+
+	created, err := s.UpsertDependency(ctx, "p", "1.0.0", depsmanager.Dependency{Name: "lodash", Score: 5})
+	require.NoError(t, err)
+	require.False(t, created)
+	st.AssertExpectations(t)
+}
+
+func TestService_UpsertDependency_AddError(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+
+	st.On("AddDependency", ctx, "p", "1.0.0", mock.Anything).Return(depsmanager.ErrProjectNotFound).Once()
+
+	_, err := s.UpsertDependency(ctx, "p", "1.0.0", depsmanager.Dependency{Name: "lodash", Score: 5})
+	require.ErrorIs(t, err, depsmanager.ErrProjectNotFound)
+	st.AssertExpectations(t)
+}
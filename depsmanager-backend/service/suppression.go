@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/suppression"
+	"fmt"
+)
+
+func (s *service) CreateSuppression(ctx context.Context, req depsmanager.CreateSuppressionRequest) (depsmanager.Suppression, error) {
+	created, err := s.storage.CreateSuppression(ctx, depsmanager.Suppression{
+		DependencyName: req.DependencyName,
+		ProjectName:    req.ProjectName,
+		ProjectVersion: req.ProjectVersion,
+		VersionRange:   req.VersionRange,
+		Justification:  req.Justification,
+		Approver:       req.Approver,
+		ExpiresAt:      req.ExpiresAt,
+		CreatedAt:      s.tNow().Unix(),
+	})
+	if err != nil {
+		return depsmanager.Suppression{}, fmt.Errorf("s.storage.CreateSuppression: %w", err)
+	}
+	return created, nil
+}
+
+// ListSuppressions returns active and expired suppressions, expired ones are marked.
+func (s *service) ListSuppressions(ctx context.Context) ([]depsmanager.Suppression, error) {
+	all, err := s.storage.ListSuppressions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.storage.ListSuppressions: %w", err)
+	}
+	now := s.tNow().Unix()
+	for i := range all {
+		all[i].Expired = all[i].ExpiresAt <= now
+	}
+	return all, nil
+}
+
+func (s *service) DeleteSuppression(ctx context.Context, id int64) error {
+	if err := s.storage.DeleteSuppression(ctx, id); err != nil {
+		return fmt.Errorf("s.storage.DeleteSuppression: %w", err)
+	}
+	return nil
+}
+
+func (s *service) activeSuppressions(ctx context.Context) ([]depsmanager.Suppression, error) {
+	all, err := s.storage.ListSuppressions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.storage.ListSuppressions: %w", err)
+	}
+	return suppression.Active(all, s.tNow()), nil
+}
+
+// applySuppressions flags deps accepted by suppressions active now, see suppression.Apply.
+func (s *service) applySuppressions(ctx context.Context, deps []depsmanager.Dependency) ([]depsmanager.Dependency, error) {
+	active, err := s.activeSuppressions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return suppression.Apply(active, deps), nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/labels"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ListSuppressions_MarksExpired(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+	now := fixedNow().Unix()
+
+	st.On("ListSuppressions", ctx).Return([]depsmanager.Suppression{
+		{ID: 1, DependencyName: "a", ExpiresAt: now},
+		{ID: 2, DependencyName: "b", ExpiresAt: now + 1},
+	}, nil).Once()
+
+	got, err := s.ListSuppressions(ctx)
+	require.NoError(t, err)
+	assert.True(t, got[0].Expired)
+	assert.False(t, got[1].Expired)
+	st.AssertExpectations(t)
+}
+
+func TestService_Suppressions_AppliedToScoresAndSummaries(t *testing.T) {
+	s, st, _ := newSvc(t)
+	ctx := context.Background()
+	now := fixedNow().Unix()
+
+	suppressions := []depsmanager.Suppression{
+		{ID: 1, DependencyName: "lodash", ProjectName: "app", VersionRange: "<4.17.21", ExpiresAt: now + 100},
+		{ID: 2, DependencyName: "scheduler", ExpiresAt: now},
+	}
+	deps := func() []depsmanager.Dependency {
+		return []depsmanager.Dependency{
+			{ProjectName: "app", ProjectVersion: "1.0.0", Name: "lodash", Version: "4.17.20", Score: 3, UpdatedAt: 100},
+			{ProjectName: "web", ProjectVersion: "2.0.0", Name: "lodash", Version: "4.17.20", Score: 3, UpdatedAt: 100},
+			{ProjectName: "app", ProjectVersion: "1.0.0", Name: "scheduler", Version: "0.23.2", Score: 3, UpdatedAt: 100},
+		}
+	}
+	st.On("ListSuppressions", ctx).Return(suppressions, nil).Times(2)
+	st.On("ListAllDependencies", ctx).Return(deps(), nil).Once()
+	st.On("ListAllDependencies", ctx).Return(deps(), nil).Once()
+
+	// lodash of web and scheduler with expired suppression still have the score
+	names, err := s.GetDependenciesByExactScore(ctx, 3, labels.Selector{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lodash", "scheduler"}, names)
+
+	summary, err := s.GetPortfolioSummary(ctx, labels.Selector{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.DependencyCount)
+	assert.Equal(t, 1, summary.SuppressedCount)
+	st.AssertExpectations(t)
+	st.AssertNotCalled(t, "GetDependenciesByExactScore", ctx, 3.0)
+}
+
+func TestService_EvaluateLockfile_SuppressedPackageNotChecked(t *testing.T) {
+	s, st, dc := newSvc(t)
+	ctx := context.Background()
+	maxUnscored := 0
+
+	st.On("ListSuppressions", ctx).Return([]depsmanager.Suppression{
+		{ID: 7, DependencyName: "c", VersionRange: "3.x", ExpiresAt: fixedNow().Unix() + 1},
+	}, nil).Once()
+	dc.On("GetVersionsBatch", ctx, []depsmanager.ProjectDependencies{
+		{System: "NPM", Name: "a", Version: "1.0.0"},
+		{System: "NPM", Name: "b", Version: "2.0.0"},
+		{System: "NPM", Name: "c", Version: "3.0.0"},
+	}).Return(&depsmanager.DepsGetVersionsBatchResp{}, nil).Once()
+
+	res, err := s.EvaluateLockfile(ctx, []byte(gateLockfile), depsmanager.GateRequest{
+		Thresholds: depsmanager.GateThresholds{MaxUnscored: &maxUnscored},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Packages, 3)
+	require.NotNil(t, res.Packages[2].SuppressedBy)
+	assert.Equal(t, int64(7), *res.Packages[2].SuppressedBy)
+	// a and b are unscored, c is suppressed
+	require.Len(t, res.Violations, 1)
+	assert.Contains(t, res.Violations[0].Message, "2 dependencies")
+	st.AssertExpectations(t)
+}
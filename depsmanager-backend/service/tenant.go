@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"fmt"
+)
+
+func (s *service) CreateTenant(ctx context.Context, name string) (depsmanager.Tenant, error) {
+	t := depsmanager.Tenant{Name: name, CreatedAt: s.tNow().Unix()}
+	if err := s.storage.CreateTenant(ctx, t); err != nil {
+		return depsmanager.Tenant{}, fmt.Errorf("s.storage.CreateTenant: %w", err)
+	}
+	return t, nil
+}
+
+func (s *service) ListTenants(ctx context.Context) ([]depsmanager.Tenant, error) {
+	tenants, err := s.storage.ListTenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.storage.ListTenants: %w", err)
+	}
+	return tenants, nil
+}
+
+func (s *service) GetTenant(ctx context.Context, name string) (depsmanager.Tenant, error) {
+	t, err := s.storage.GetTenant(ctx, name)
+	if err != nil {
+		return depsmanager.Tenant{}, fmt.Errorf("s.storage.GetTenant: %w", err)
+	}
+	return t, nil
+}
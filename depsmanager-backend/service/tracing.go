@@ -0,0 +1,50 @@
+package service
+
+import (
+	"depsmanager/pkg/trace"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// WithTracer starts a server span for every request, continuing the trace of the traceparent header.
+func WithTracer(t *trace.Tracer) func(a *API) {
+	return func(a *API) {
+		a.tracer = t
+	}
+}
+
+// untraced are paths of scrapes and probes.
+var untraced = map[string]bool{"/metrics": true, "/healthz": true, "/readyz": true}
+
+// traceRequest names the span by route pattern once chi has routed the request.
+func (a *API) traceRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if untraced[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		if parent, ok := trace.Extract(r.Header); ok {
+			ctx = trace.ContextWithRemoteParent(ctx, parent)
+		}
+		ctx, span := a.tracer.Start(ctx, r.Method, trace.KindServer,
+			trace.String("http.request.method", r.Method), trace.String("url.path", r.URL.Path))
+		defer span.End()
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		route := "unmatched"
+		if rc := chi.RouteContext(ctx); rc != nil && rc.RoutePattern() != "" {
+			route = rc.RoutePattern()
+		}
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(trace.String("http.route", route), trace.Int("http.response.status_code", sw.status))
+		if sw.status >= http.StatusInternalServerError {
+			span.RecordError(fmt.Errorf("%d %s", sw.status, http.StatusText(sw.status)))
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"depsmanager"
+	"depsmanager/pkg/trace"
+	"depsmanager/service/mocks"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracing_Fetch(t *testing.T) {
+	exp := trace.NewInMemoryExporter()
+	tracer := trace.NewTracer(exp)
+
+	st := new(mocks.Storage)
+	dc := new(mocks.DepsClient)
+	svc := NewService(
+		WithStorage(InstrumentStorage(st, nil, tracer)),
+		WithDepsClient(InstrumentDepsClient(dc, nil, tracer)),
+		WithTimeNow(fixedNow),
+	)
+	a := NewAPI(InstrumentService(svc, nil, tracer), WithTracer(tracer))
+	h := a.GetHandler()
+
+	dc.On("GetProjectDependencies", mock.Anything, SystemNPM, "react", "18.3.1").
+		Return(depsRespFromJSON(t, `{"nodes":[{"versionKey":{"system":"NPM","name":"react","version":"18.3.1"},"relation":"SELF"}]}`), nil).Once()
+	st.On("StoreDependencies", mock.Anything, mock.Anything).Return(nil).Once()
+	st.On("ListProjectDependencies", mock.Anything, "react", "18.3.1").Return(nil, errors.New("db is locked")).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v2/projects/react/versions/18.3.1", nil)
+	req.Header.Set(trace.Header, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	server, ok := exp.Span("PUT /api/v2/projects/{name}/versions/{version}")
+	require.True(t, ok)
+	assert.Equal(t, trace.KindServer, server.Kind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.String())
+	assert.Equal(t, "/api/v2/projects/{name}/versions/{version}", server.Attribute("http.route"))
+	assert.Equal(t, int64(http.StatusNoContent), server.Attribute("http.response.status_code"))
+
+	fetch, ok := exp.Span("service.FetchAndStoreProjectDependencies")
+	require.True(t, ok)
+	assert.Equal(t, server.SpanContext.SpanID, fetch.Parent)
+	assert.Equal(t, "react", fetch.Attribute("project.name"))
+	assert.Equal(t, "18.3.1", fetch.Attribute("project.version"))
+	assert.Equal(t, "ok", fetch.Attribute("fetch.result"))
+
+	client, ok := exp.Span("depsclient.GetProjectDependencies")
+	require.True(t, ok)
+	assert.Equal(t, trace.KindClient, client.Kind)
+	assert.Equal(t, fetch.SpanContext.SpanID, client.Parent)
+
+	store, ok := exp.Span("storage.StoreDependencies")
+	require.True(t, ok)
+	assert.Equal(t, fetch.SpanContext.SpanID, store.Parent)
+	assert.Equal(t, int64(0), store.Attribute("dependency.count"))
+
+	list, ok := exp.Span("storage.ListProjectDependencies")
+	require.True(t, ok)
+	assert.True(t, list.Error)
+	assert.Equal(t, "db is locked", list.StatusMessage)
+	st.AssertExpectations(t)
+	dc.AssertExpectations(t)
+}
+
+func TestTracing_Decorators(t *testing.T) {
+	exp := trace.NewInMemoryExporter()
+	tracer := trace.NewTracer(exp)
+	ctx, parent := tracer.Start(context.Background(), "parent", trace.KindInternal)
+
+	st := new(mocks.Storage)
+	st.On("StoreDependencies", mock.Anything, mock.Anything).Return(nil).Once()
+	require.NoError(t, InstrumentStorage(st, nil, tracer).StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+		Project:      depsmanager.Project{Name: "react", Version: "18.3.1"},
+		Dependencies: []depsmanager.Dependency{{Name: "a"}, {Name: "b"}},
+	}))
+	store, ok := exp.Span("storage.StoreDependencies")
+	require.True(t, ok)
+	assert.Equal(t, parent.SpanContext().SpanID, store.Parent)
+	assert.Equal(t, "react", store.Attribute("project.name"))
+	assert.Equal(t, "18.3.1", store.Attribute("project.version"))
+	assert.Equal(t, int64(2), store.Attribute("dependency.count"))
+
+	// the client span is current when the request is sent, so deps.dev sees it as parent
+	dc := new(mocks.DepsClient)
+	dc.On("GetProjectVersions", mock.Anything, "missing").Return(nil, depsmanager.ErrProjectNotFound).Once()
+	dc.On("GetProjectsBatch", mock.Anything, []string{"a", "b"}).Return(nil, errors.New("timeout")).Once().
+		Run(func(args mock.Arguments) {
+			h := http.Header{}
+			trace.Inject(args.Get(0).(context.Context), h)
+			sc, ok := trace.Extract(h)
+			require.True(t, ok)
+			assert.Equal(t, parent.SpanContext().TraceID, sc.TraceID)
+			assert.NotEqual(t, parent.SpanContext().SpanID, sc.SpanID)
+		})
+	client := InstrumentDepsClient(dc, nil, tracer)
+	_, err := client.GetProjectVersions(ctx, "missing")
+	require.ErrorIs(t, err, depsmanager.ErrProjectNotFound)
+	_, err = client.GetProjectsBatch(ctx, []string{"a", "b"})
+	require.Error(t, err)
+
+	versions, ok := exp.Span("depsclient.GetProjectVersions")
+	require.True(t, ok)
+	assert.False(t, versions.Error, "not found is not an error")
+	batch, ok := exp.Span("depsclient.GetProjectsBatch")
+	require.True(t, ok)
+	assert.True(t, batch.Error)
+	assert.Equal(t, int64(2), batch.Attribute("batch.size"))
+	st.AssertExpectations(t)
+	dc.AssertExpectations(t)
+}
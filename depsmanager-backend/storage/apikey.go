@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"depsmanager"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+func (s *Storage) CreateAPIKey(ctx context.Context, key depsmanager.APIKey) (depsmanager.APIKey, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return depsmanager.APIKey{}, fmt.Errorf("s.db.BeginTx(): %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO api_keys(tenant, name, role, key_hash, prefix, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		key.Tenant, key.Name, key.Role, key.KeyHash, key.Prefix, key.CreatedAt,
+	)
+	if err != nil {
+		return depsmanager.APIKey{}, fmt.Errorf("tx.ExecContext(insert api key %s): %w", key.Name, err)
+	}
+	key.ID, err = res.LastInsertId()
+	if err != nil {
+		return depsmanager.APIKey{}, fmt.Errorf("LastInsertId: %w", err)
+	}
+
+	// APIKey never marshals its hash, only metadata of the key is recorded
+	err = appendAudit(ctx, tx, s.now().Unix(), depsmanager.AuditEntry{
+		Tenant: key.Tenant,
+		Action: depsmanager.AuditAPIKeyCreate,
+		Target: strconv.FormatInt(key.ID, 10),
+	}, nil, key)
+	if err != nil {
+		return depsmanager.APIKey{}, fmt.Errorf("appendAudit(): %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return depsmanager.APIKey{}, fmt.Errorf("tx.Commit(): %w", err)
+	}
+	return key, nil
+}
+
+// ListAPIKeys returns all keys of tenant, including revoked ones.
+func (s *Storage) ListAPIKeys(ctx context.Context) ([]depsmanager.APIKey, error) {
+	filter, args := tenantFilter(ctx, "tenant")
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, tenant, name, role, prefix, created_at, revoked_at
+		FROM api_keys
+		WHERE `+filter+`
+		ORDER BY id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.QueryContext(ListAPIKeys): %w", err)
+	}
+	defer rows.Close()
+
+	keys := []depsmanager.APIKey{}
+	for rows.Next() {
+		var k depsmanager.APIKey
+		if err := rows.Scan(&k.ID, &k.Tenant, &k.Name, &k.Role, &k.Prefix, &k.CreatedAt, &k.RevokedAt); err != nil {
+			return nil, fmt.Errorf("rows.Scan(api key): %w", err)
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return keys, nil
+}
+
+// GetActiveAPIKey returns not revoked key by its hash. It is not scoped by tenant,
+// the key determines tenant of the request.
+func (s *Storage) GetActiveAPIKey(ctx context.Context, keyHash string) (depsmanager.APIKey, error) {
+	var k depsmanager.APIKey
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, tenant, name, role, prefix, created_at
+		FROM api_keys
+		WHERE key_hash = ? AND revoked_at IS NULL
+	`, keyHash).Scan(&k.ID, &k.Tenant, &k.Name, &k.Role, &k.Prefix, &k.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return depsmanager.APIKey{}, depsmanager.ErrAPIKeyNotFound
+		}
+		return depsmanager.APIKey{}, fmt.Errorf("s.db.QueryRowContext(GetActiveAPIKey): %w", err)
+	}
+	return k, nil
+}
+
+// RevokeAPIKey marks key of tenant as revoked, the row is kept to show who had access.
+func (s *Storage) RevokeAPIKey(ctx context.Context, id int64, revokedAt int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("s.db.BeginTx(): %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	filter, args := tenantFilter(ctx, "tenant")
+	var before depsmanager.APIKey
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, tenant, name, role, prefix, created_at
+		FROM api_keys
+		WHERE id = ? AND revoked_at IS NULL AND `+filter,
+		append([]any{id}, args...)...,
+	).Scan(&before.ID, &before.Tenant, &before.Name, &before.Role, &before.Prefix, &before.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return depsmanager.ErrAPIKeyNotFound
+		}
+		return fmt.Errorf("tx.QueryRowContext(api key %d): %w", id, err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE api_keys SET revoked_at = ? WHERE id = ?", revokedAt, id)
+	if err != nil {
+		return fmt.Errorf("tx.ExecContext(revoke api key %d): %w", id, err)
+	}
+
+	after := before
+	after.RevokedAt = &revokedAt
+	err = appendAudit(ctx, tx, s.now().Unix(), depsmanager.AuditEntry{
+		Tenant: before.Tenant,
+		Action: depsmanager.AuditAPIKeyRevoke,
+		Target: strconv.FormatInt(id, 10),
+	}, before, after)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit(): %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"depsmanager"
+)
+
+func TestAPIKeys_CreateGetRevoke(t *testing.T) {
+	st := newInMemoryStorage(t)
+	ctx := context.Background()
+
+	created, err := st.CreateAPIKey(ctx, depsmanager.APIKey{
+		Tenant: "default", Name: "ci", Role: depsmanager.RoleReader, KeyHash: "hash-1", Prefix: "dm_1234", CreatedAt: 10,
+	})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	if created.ID == 0 {
+		t.Fatalf("expected assigned id, got: %+v", created)
+	}
+
+	got, err := st.GetActiveAPIKey(ctx, "hash-1")
+	if err != nil {
+		t.Fatalf("GetActiveAPIKey: %v", err)
+	}
+	if got.Name != "ci" || got.Role != depsmanager.RoleReader || got.Tenant != "default" || got.ID != created.ID {
+		t.Fatalf("unexpected key: %+v", got)
+	}
+
+	if _, err := st.CreateAPIKey(ctx, depsmanager.APIKey{Tenant: "default", Name: "dup", Role: depsmanager.RoleAdmin, KeyHash: "hash-1"}); err == nil {
+		t.Fatalf("expected error on duplicated hash")
+	}
+
+	if err := st.RevokeAPIKey(ctx, created.ID, 20); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+	if _, err := st.GetActiveAPIKey(ctx, "hash-1"); !errors.Is(err, depsmanager.ErrAPIKeyNotFound) {
+		t.Fatalf("expected ErrAPIKeyNotFound after revoke, got: %v", err)
+	}
+	if err := st.RevokeAPIKey(ctx, created.ID, 30); !errors.Is(err, depsmanager.ErrAPIKeyNotFound) {
+		t.Fatalf("expected ErrAPIKeyNotFound on second revoke, got: %v", err)
+	}
+
+	keys, err := st.ListAPIKeys(ctx)
+	if err != nil {
+		t.Fatalf("ListAPIKeys: %v", err)
+	}
+	if len(keys) != 1 || keys[0].RevokedAt == nil || *keys[0].RevokedAt != 20 {
+		t.Fatalf("expected revoked key in list, got: %+v", keys)
+	}
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"depsmanager"
+	"depsmanager/pkg/auth"
+	"depsmanager/pkg/requestid"
+	"depsmanager/pkg/tenant"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// anonymousActor is recorded when the change was made without an identity, i.e. with auth disabled.
+const anonymousActor = "anonymous"
+
+// appendAudit records e in the same transaction as the change it describes, so both are
+// committed or rolled back together. createdAt is the unix time of the storage clock. Actor,
+// request ID and, when e.Tenant is empty, tenant are taken from ctx. before and after are stored as JSON.
+func appendAudit(ctx context.Context, tx *sql.Tx, createdAt int64, e depsmanager.AuditEntry, before, after any) error {
+	if e.Tenant == "" {
+		e.Tenant = tenant.FromContext(ctx)
+	}
+	e.Actor = anonymousActor
+	if id, ok := auth.FromContext(ctx); ok && id.Name != "" {
+		e.Actor = id.Name
+	}
+
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return fmt.Errorf("json.Marshal(before): %w", err)
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return fmt.Errorf("json.Marshal(after): %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_log(tenant, actor, action, project_name, version, dependency_name, target, before, after, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Tenant, e.Actor, e.Action, e.ProjectName, e.Version, e.DependencyName, e.Target,
+		string(beforeJSON), string(afterJSON), requestid.FromContext(ctx), createdAt,
+	)
+	if err != nil {
+		return fmt.Errorf("tx.ExecContext(insert audit %s): %w", e.Action, err)
+	}
+	return nil
+}
+
+// ListAudit returns entries of tenant matching filter, newest first.
+func (s *Storage) ListAudit(ctx context.Context, filter depsmanager.AuditFilter) ([]depsmanager.AuditEntry, error) {
+	cond, args := tenantFilter(ctx, "tenant")
+	conds := []string{cond}
+	eq := func(column, value string) {
+		if value != "" {
+			conds = append(conds, column+" = ?")
+			args = append(args, value)
+		}
+	}
+	eq("actor", filter.Actor)
+	eq("action", filter.Action)
+	eq("project_name", filter.ProjectName)
+	eq("version", filter.Version)
+	eq("dependency_name", filter.DependencyName)
+	if filter.From > 0 {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, filter.From)
+	}
+	if filter.To > 0 {
+		conds = append(conds, "created_at < ?")
+		args = append(args, filter.To)
+	}
+	if filter.BeforeID > 0 {
+		conds = append(conds, "id < ?")
+		args = append(args, filter.BeforeID)
+	}
+
+	query := `
+		SELECT id, tenant, actor, action, project_name, version, dependency_name, target, before, after, request_id, created_at
+		FROM audit_log
+		WHERE ` + strings.Join(conds, " AND ") + `
+		ORDER BY id DESC`
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.QueryContext(ListAudit): %w", err)
+	}
+	defer rows.Close()
+
+	entries := []depsmanager.AuditEntry{}
+	for rows.Next() {
+		var (
+			e             depsmanager.AuditEntry
+			before, after string
+		)
+		if err := rows.Scan(&e.ID, &e.Tenant, &e.Actor, &e.Action, &e.ProjectName, &e.Version, &e.DependencyName,
+			&e.Target, &before, &after, &e.RequestID, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("rows.Scan(audit): %w", err)
+		}
+		e.Before, e.After = json.RawMessage(before), json.RawMessage(after)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return entries, nil
+}
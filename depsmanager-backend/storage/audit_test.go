@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"depsmanager"
+	"depsmanager/pkg/auth"
+	"depsmanager/pkg/requestid"
+	"depsmanager/pkg/tenant"
+)
+
+func TestAudit_RecordsMutations(t *testing.T) {
+	st := newInMemoryStorage(t)
+	now := time.Unix(1_700_000_000, 0)
+	st.now = func() time.Time { return now }
+	ctx := auth.NewContext(context.Background(), depsmanager.Identity{Name: "ci", Role: depsmanager.RoleEditor, Tenant: tenant.Default})
+	ctx = requestid.NewContext(ctx, "req-1")
+
+	if err := st.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+		Project:      depsmanager.Project{Name: "react", Version: "18.3.1", UpdatedAt: 1},
+		Dependencies: makeDeps("scheduler"),
+	}); err != nil {
+		t.Fatalf("StoreDependencies: %v", err)
+	}
+	if err := st.UpdateDependency(ctx, "react", "18.3.1", depsmanager.Dependency{Name: "scheduler", Score: 2, UpdatedAt: 5}); err != nil {
+		t.Fatalf("UpdateDependency: %v", err)
+	}
+	if err := st.DeleteProject(ctx, "react", "18.3.1"); err != nil {
+		t.Fatalf("DeleteProject: %v", err)
+	}
+	// failed change leaves no entry
+	if err := st.DeleteDependency(ctx, "react", "18.3.1", "scheduler"); err == nil {
+		t.Fatalf("expected DeleteDependency to fail on deleted project")
+	}
+
+	entries, err := st.ListAudit(ctx, depsmanager.AuditFilter{})
+	if err != nil {
+		t.Fatalf("ListAudit: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d: %+v", len(entries), entries)
+	}
+	wantActions := []string{depsmanager.AuditProjectDelete, depsmanager.AuditDependencyUpdate, depsmanager.AuditProjectFetch}
+	for i, e := range entries {
+		if e.Action != wantActions[i] || e.Actor != "ci" || e.RequestID != "req-1" || e.Tenant != tenant.Default ||
+			e.ProjectName != "react" || e.Version != "18.3.1" || e.CreatedAt != now.Unix() {
+			t.Fatalf("unexpected entry %d: %+v", i, e)
+		}
+	}
+
+	update := entries[1]
+	var before, after depsmanager.Dependency
+	if err := json.Unmarshal(update.Before, &before); err != nil {
+		t.Fatalf("json.Unmarshal(before): %v", err)
+	}
+	if err := json.Unmarshal(update.After, &after); err != nil {
+		t.Fatalf("json.Unmarshal(after): %v", err)
+	}
+	if update.DependencyName != "scheduler" || before.Score != 80.5 || after.Score != 2 || after.Override == nil || after.Override.SetAt != 5 {
+		t.Fatalf("unexpected update entry: %+v before=%+v after=%+v", update, before, after)
+	}
+	if string(entries[0].After) != "null" || string(entries[2].Before) != "null" {
+		t.Fatalf("expected null after delete and before first fetch, got %s, %s", entries[0].After, entries[2].Before)
+	}
+}
+
+func TestAudit_RefreshRecordsOnlyChanges(t *testing.T) {
+	st := newInMemoryStorage(t)
+	ctx := context.Background()
+	fetch := func(score float64) {
+		t.Helper()
+		if err := st.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+			Project:      depsmanager.Project{Name: "react", Version: "18.3.1", UpdatedAt: 1},
+			Dependencies: []depsmanager.Dependency{{Name: "scheduler", Score: score, UpdatedAt: 1}, {Name: "loose-envify", Score: 6, UpdatedAt: 1}},
+		}); err != nil {
+			t.Fatalf("StoreDependencies: %v", err)
+		}
+	}
+
+	fetch(5)
+	if err := st.AddDependency(ctx, "react", "18.3.1", depsmanager.Dependency{Name: "internal-lib", Score: 9, UpdatedAt: 2}); err != nil {
+		t.Fatalf("AddDependency: %v", err)
+	}
+	if err := st.UpdateDependency(ctx, "react", "18.3.1", depsmanager.Dependency{Name: "scheduler", Score: 8, UpdatedAt: 3}); err != nil {
+		t.Fatalf("UpdateDependency: %v", err)
+	}
+
+	// refreshes without upstream changes leave no entry despite the manual dependency and the override
+	fetch(5)
+	fetch(5)
+	entries, err := st.ListAudit(ctx, depsmanager.AuditFilter{Action: depsmanager.AuditProjectFetch})
+	if err != nil {
+		t.Fatalf("ListAudit: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the first fetch, got %d: %+v", len(entries), entries)
+	}
+
+	fetch(4)
+	entries, err = st.ListAudit(ctx, depsmanager.AuditFilter{Action: depsmanager.AuditProjectFetch})
+	if err != nil {
+		t.Fatalf("ListAudit: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected an entry of the changed score, got %d: %+v", len(entries), entries)
+	}
+	var before, after []depsmanager.Dependency
+	if err := json.Unmarshal(entries[0].Before, &before); err != nil {
+		t.Fatalf("json.Unmarshal(before): %v", err)
+	}
+	if err := json.Unmarshal(entries[0].After, &after); err != nil {
+		t.Fatalf("json.Unmarshal(after): %v", err)
+	}
+	if len(before) != 1 || before[0].Name != "scheduler" || before[0].Score != 5 ||
+		len(after) != 1 || after[0].Name != "scheduler" || after[0].Score != 4 {
+		t.Fatalf("unexpected refresh entry: before=%+v after=%+v", before, after)
+	}
+}
+
+func TestAudit_Filter(t *testing.T) {
+	st := newInMemoryStorage(t)
+	bg := context.Background()
+	if err := st.CreateTenant(bg, depsmanager.Tenant{Name: "team-a", CreatedAt: 1}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	teamA := tenant.NewContext(bg, "team-a")
+
+	for _, name := range []string{"global", "strict"} {
+		if err := st.SavePolicy(bg, depsmanager.Policy{Name: name, Document: "rules: []", UpdatedAt: 1}); err != nil {
+			t.Fatalf("SavePolicy(%s): %v", name, err)
+		}
+	}
+	if err := st.SavePolicy(teamA, depsmanager.Policy{Name: "other", Document: "rules: []", UpdatedAt: 1}); err != nil {
+		t.Fatalf("SavePolicy(team-a): %v", err)
+	}
+
+	entries, err := st.ListAudit(bg, depsmanager.AuditFilter{Action: depsmanager.AuditPolicySave, Actor: anonymousActor})
+	if err != nil {
+		t.Fatalf("ListAudit: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Target != "strict" || entries[1].Target != "global" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+
+	page, err := st.ListAudit(bg, depsmanager.AuditFilter{Limit: 1, BeforeID: entries[0].ID})
+	if err != nil {
+		t.Fatalf("ListAudit(page): %v", err)
+	}
+	if len(page) != 1 || page[0].ID != entries[1].ID {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+
+	// team-a sees its policy and its own creation, every tenant is visible with tenant.All
+	entries, err = st.ListAudit(teamA, depsmanager.AuditFilter{})
+	if err != nil {
+		t.Fatalf("ListAudit(team-a): %v", err)
+	}
+	if len(entries) != 2 || entries[0].Action != depsmanager.AuditPolicySave || entries[1].Action != depsmanager.AuditTenantCreate {
+		t.Fatalf("unexpected team-a entries: %+v", entries)
+	}
+	entries, err = st.ListAudit(tenant.NewContext(bg, tenant.All), depsmanager.AuditFilter{})
+	if err != nil {
+		t.Fatalf("ListAudit(all): %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries across tenants, got %d", len(entries))
+	}
+}
+
+func TestAudit_AppendOnly(t *testing.T) {
+	st := newInMemoryStorage(t)
+	if err := st.CreateTenant(context.Background(), depsmanager.Tenant{Name: "team-a", CreatedAt: 1}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+
+	if _, err := st.db.Exec("UPDATE audit_log SET actor = 'someone-else'"); err == nil {
+		t.Fatalf("expected UPDATE of audit_log to fail")
+	}
+	if _, err := st.db.Exec("DELETE FROM audit_log"); err == nil {
+		t.Fatalf("expected DELETE from audit_log to fail")
+	}
+}
@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"depsmanager"
+	"errors"
+	"fmt"
+)
+
+// GetProjectMetadata returns metadata of project in tenant of ctx, empty one when it was never set.
+func (s *Storage) GetProjectMetadata(ctx context.Context, projectName string) (depsmanager.ProjectMetadata, error) {
+	t, err := singleTenant(ctx)
+	if err != nil {
+		return depsmanager.ProjectMetadata{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return depsmanager.ProjectMetadata{}, fmt.Errorf("s.db.BeginTx(): %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := projectExistsTX(ctx, tx, t, projectName); err != nil {
+		return depsmanager.ProjectMetadata{}, err
+	}
+	m, _, err := getProjectMetadataTX(ctx, tx, t, projectName)
+	return m, err
+}
+
+// SaveProjectMetadata replaces metadata and labels of project, the project must have a stored version.
+func (s *Storage) SaveProjectMetadata(ctx context.Context, m depsmanager.ProjectMetadata) error {
+	t, err := singleTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("s.db.BeginTx(): %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := projectExistsTX(ctx, tx, t, m.ProjectName); err != nil {
+		return err
+	}
+	before, found, err := getProjectMetadataTX(ctx, tx, t, m.ProjectName)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO project_metadata(tenant, project_name, owner, description, repo_url, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant, project_name) DO UPDATE SET
+			owner = excluded.owner, description = excluded.description,
+			repo_url = excluded.repo_url, updated_at = excluded.updated_at`,
+		t, m.ProjectName, m.Owner, m.Description, m.RepoURL, m.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("tx.ExecContext(upsert project_metadata %s): %w", m.ProjectName, err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM project_labels WHERE tenant = ? AND project_name = ?", t, m.ProjectName); err != nil {
+		return fmt.Errorf("tx.ExecContext(delete project_labels %s): %w", m.ProjectName, err)
+	}
+	for k, v := range m.Labels {
+		_, err := tx.ExecContext(ctx, "INSERT INTO project_labels(tenant, project_name, key, value) VALUES (?, ?, ?, ?)",
+			t, m.ProjectName, k, v)
+		if err != nil {
+			return fmt.Errorf("tx.ExecContext(insert project_labels %s=%s): %w", k, v, err)
+		}
+	}
+
+	var beforeValue any
+	if found {
+		beforeValue = before
+	}
+	err = appendAudit(ctx, tx, s.now().Unix(), depsmanager.AuditEntry{
+		Action:      depsmanager.AuditMetadataSave,
+		ProjectName: m.ProjectName,
+	}, beforeValue, m)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit(): %w", err)
+	}
+	return nil
+}
+
+// DeleteProjectMetadata removes metadata and labels of project, it is a no-op when there are none.
+func (s *Storage) DeleteProjectMetadata(ctx context.Context, projectName string) error {
+	t, err := singleTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("s.db.BeginTx(): %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := projectExistsTX(ctx, tx, t, projectName); err != nil {
+		return err
+	}
+	before, found, err := getProjectMetadataTX(ctx, tx, t, projectName)
+	if err != nil || !found {
+		return err
+	}
+
+	// labels are removed by ON DELETE CASCADE
+	if _, err := tx.ExecContext(ctx, "DELETE FROM project_metadata WHERE tenant = ? AND project_name = ?", t, projectName); err != nil {
+		return fmt.Errorf("tx.ExecContext(delete project_metadata %s): %w", projectName, err)
+	}
+
+	err = appendAudit(ctx, tx, s.now().Unix(), depsmanager.AuditEntry{
+		Action:      depsmanager.AuditMetadataDelete,
+		ProjectName: projectName,
+	}, before, nil)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit(): %w", err)
+	}
+	return nil
+}
+
+func projectExistsTX(ctx context.Context, tx *sql.Tx, tenant, projectName string) error {
+	var one int
+	err := tx.QueryRowContext(ctx, "SELECT 1 FROM projects WHERE tenant = ? AND name = ? LIMIT 1", tenant, projectName).Scan(&one)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return depsmanager.ErrProjectNotFound
+		}
+		return fmt.Errorf("tx.QueryRowContext(project %s): %w", projectName, err)
+	}
+	return nil
+}
+
+// getProjectMetadataTX returns metadata of project and whether it was set.
+func getProjectMetadataTX(ctx context.Context, tx *sql.Tx, tenant, projectName string) (depsmanager.ProjectMetadata, bool, error) {
+	m := depsmanager.ProjectMetadata{ProjectName: projectName, Labels: map[string]string{}}
+	err := tx.QueryRowContext(ctx,
+		"SELECT owner, description, repo_url, updated_at FROM project_metadata WHERE tenant = ? AND project_name = ?",
+		tenant, projectName,
+	).Scan(&m.Owner, &m.Description, &m.RepoURL, &m.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return m, false, nil
+		}
+		return depsmanager.ProjectMetadata{}, false, fmt.Errorf("tx.QueryRowContext(project_metadata %s): %w", projectName, err)
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT key, value FROM project_labels WHERE tenant = ? AND project_name = ?", tenant, projectName)
+	if err != nil {
+		return depsmanager.ProjectMetadata{}, false, fmt.Errorf("tx.QueryContext(project_labels %s): %w", projectName, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return depsmanager.ProjectMetadata{}, false, fmt.Errorf("rows.Scan(label): %w", err)
+		}
+		m.Labels[k] = v
+	}
+	if err := rows.Err(); err != nil {
+		return depsmanager.ProjectMetadata{}, false, fmt.Errorf("rows.Err(): %w", err)
+	}
+	return m, true, nil
+}
+
+// attachMetadata fills metadata fields of projects from metadata stored in tenant of ctx.
+func (s *Storage) attachMetadata(ctx context.Context, projects []depsmanager.Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+	type key struct{ tenant, name string }
+	metadata := make(map[key]*depsmanager.Project)
+
+	filter, args := tenantFilter(ctx, "tenant")
+	rows, err := s.db.QueryContext(ctx, "SELECT tenant, project_name, owner, description, repo_url FROM project_metadata WHERE "+filter, args...)
+	if err != nil {
+		return fmt.Errorf("s.db.QueryContext(project_metadata): %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k key
+		var m depsmanager.Project
+		if err := rows.Scan(&k.tenant, &k.name, &m.Owner, &m.Description, &m.RepoURL); err != nil {
+			return fmt.Errorf("rows.Scan(project_metadata): %w", err)
+		}
+		metadata[k] = &m
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	labelRows, err := s.db.QueryContext(ctx, "SELECT tenant, project_name, key, value FROM project_labels WHERE "+filter, args...)
+	if err != nil {
+		return fmt.Errorf("s.db.QueryContext(project_labels): %w", err)
+	}
+	defer labelRows.Close()
+	for labelRows.Next() {
+		var k key
+		var name, value string
+		if err := labelRows.Scan(&k.tenant, &k.name, &name, &value); err != nil {
+			return fmt.Errorf("rows.Scan(project_labels): %w", err)
+		}
+		if m, ok := metadata[k]; ok {
+			if m.Labels == nil {
+				m.Labels = map[string]string{}
+			}
+			m.Labels[name] = value
+		}
+	}
+	if err := labelRows.Err(); err != nil {
+		return fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	for i, p := range projects {
+		if m, ok := metadata[key{p.Tenant, p.Name}]; ok {
+			projects[i].Owner, projects[i].Description, projects[i].RepoURL = m.Owner, m.Description, m.RepoURL
+			// versions of a project share the labels, each gets its own copy
+			if len(m.Labels) > 0 {
+				projects[i].Labels = make(map[string]string, len(m.Labels))
+				for k, v := range m.Labels {
+					projects[i].Labels[k] = v
+				}
+			}
+		}
+	}
+	return nil
+}
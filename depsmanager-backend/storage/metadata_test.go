@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"depsmanager"
+	"depsmanager/pkg/tenant"
+)
+
+func TestProjectMetadata_SaveGetDelete(t *testing.T) {
+	st := newInMemoryStorage(t)
+	ctx := context.Background()
+	for _, version := range []string{"18.2.0", "18.3.1"} {
+		if err := st.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+			Project: depsmanager.Project{Name: "react", Version: version, UpdatedAt: 1},
+		}); err != nil {
+			t.Fatalf("StoreDependencies: %v", err)
+		}
+	}
+
+	if _, err := st.GetProjectMetadata(ctx, "vue"); !errors.Is(err, depsmanager.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound, got: %v", err)
+	}
+	empty, err := st.GetProjectMetadata(ctx, "react")
+	if err != nil || empty.Owner != "" || len(empty.Labels) != 0 {
+		t.Fatalf("expected empty metadata, got %+v, %v", empty, err)
+	}
+
+	m := depsmanager.ProjectMetadata{
+		ProjectName: "react", Owner: "web-platform", Description: "UI library", RepoURL: "https://github.com/facebook/react",
+		Labels: map[string]string{"team": "payments", "env": "prod"}, UpdatedAt: 10,
+	}
+	if err := st.SaveProjectMetadata(ctx, m); err != nil {
+		t.Fatalf("SaveProjectMetadata: %v", err)
+	}
+	m.Labels = map[string]string{"team": "payments"}
+	if err := st.SaveProjectMetadata(ctx, m); err != nil {
+		t.Fatalf("SaveProjectMetadata(replace): %v", err)
+	}
+	if err := st.SaveProjectMetadata(ctx, depsmanager.ProjectMetadata{ProjectName: "vue"}); !errors.Is(err, depsmanager.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound for unknown project, got: %v", err)
+	}
+
+	got, err := st.GetProjectMetadata(ctx, "react")
+	if err != nil || !reflect.DeepEqual(got, m) {
+		t.Fatalf("GetProjectMetadata = %+v, %v, want %+v", got, err, m)
+	}
+
+	projects, err := st.ListProjects(ctx)
+	if err != nil {
+		t.Fatalf("ListProjects: %v", err)
+	}
+	for _, p := range projects {
+		if p.Owner != "web-platform" || p.RepoURL != m.RepoURL || !reflect.DeepEqual(p.Labels, m.Labels) {
+			t.Fatalf("expected metadata on every version, got: %+v", p)
+		}
+	}
+	if other, _ := st.ListProjects(tenant.NewContext(ctx, tenant.All)); len(other) != 2 || other[0].Owner != "web-platform" {
+		t.Fatalf("expected metadata across tenants, got: %+v", other)
+	}
+
+	if err := st.DeleteProjectMetadata(ctx, "react"); err != nil {
+		t.Fatalf("DeleteProjectMetadata: %v", err)
+	}
+	if err := st.DeleteProjectMetadata(ctx, "react"); err != nil {
+		t.Fatalf("DeleteProjectMetadata(again): %v", err)
+	}
+	projects, _ = st.ListProjects(ctx)
+	if projects[0].Owner != "" || projects[0].Labels != nil {
+		t.Fatalf("expected no metadata after delete, got: %+v", projects[0])
+	}
+
+	entries, err := st.ListAudit(ctx, depsmanager.AuditFilter{ProjectName: "react"})
+	if err != nil {
+		t.Fatalf("ListAudit: %v", err)
+	}
+	if len(entries) != 5 || entries[0].Action != depsmanager.AuditMetadataDelete || string(entries[2].Before) != "null" {
+		t.Fatalf("unexpected audit entries: %+v", entries)
+	}
+}
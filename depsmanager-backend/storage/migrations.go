@@ -0,0 +1,289 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// migration is a single, ordered schema change.
+// Applied versions are recorded in schema_migrations, so every migration runs once per database.
+// Migrations rebuilding referenced tables set rebuild, they run with foreign keys off
+// and are checked with foreign_key_check before commit.
+type migration struct {
+	version int
+	name    string
+	apply   func(tx execer) error
+	rebuild bool
+}
+
+var migrations = []migration{
+	{version: 1, name: "create projects and dependency tables", apply: createTable},
+	{version: 2, name: "add dependency relation", apply: execStatements(
+		`ALTER TABLE dependency ADD COLUMN relation TEXT NOT NULL DEFAULT ''`,
+	)},
+	{version: 3, name: "create policies and policy evaluations", apply: execStatements(
+		`CREATE TABLE IF NOT EXISTS policies (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			project_name TEXT NOT NULL DEFAULT '',
+			document TEXT NOT NULL,
+			updated_at INTEGER NOT NULL,
+			UNIQUE(project_name, name)
+		)`,
+		`CREATE TABLE IF NOT EXISTS policy_evaluations (
+			project_id INTEGER PRIMARY KEY,
+			verdict TEXT NOT NULL,
+			violations TEXT NOT NULL,
+			evaluated_at INTEGER NOT NULL,
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+		)`,
+	)},
+	{version: 4, name: "create api keys", apply: execStatements(
+		`CREATE TABLE IF NOT EXISTS api_keys (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			role TEXT NOT NULL,
+			key_hash TEXT NOT NULL UNIQUE,
+			prefix TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			revoked_at INTEGER
+		)`,
+	)},
+	{version: 5, name: "add tenants", rebuild: true, apply: execStatements(
+		`CREATE TABLE IF NOT EXISTS tenants (
+			name TEXT PRIMARY KEY,
+			created_at INTEGER NOT NULL
+		)`,
+		`INSERT INTO tenants(name, created_at) VALUES ('default', CAST(strftime('%s', 'now') AS INTEGER))`,
+
+		// projects and policies are unique within tenant, SQLite cannot alter constraints
+		`CREATE TABLE projects_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			tenant TEXT NOT NULL REFERENCES tenants(name),
+			name TEXT NOT NULL,
+			version TEXT NOT NULL,
+			updated_at INTEGER NOT NULL,
+			UNIQUE(tenant, name, version)
+		)`,
+		`INSERT INTO projects_new(id, tenant, name, version, updated_at)
+			SELECT id, 'default', name, version, updated_at FROM projects`,
+		`DROP TABLE projects`,
+		`ALTER TABLE projects_new RENAME TO projects`,
+
+		`CREATE TABLE policies_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			tenant TEXT NOT NULL REFERENCES tenants(name),
+			name TEXT NOT NULL,
+			project_name TEXT NOT NULL DEFAULT '',
+			document TEXT NOT NULL,
+			updated_at INTEGER NOT NULL,
+			UNIQUE(tenant, project_name, name)
+		)`,
+		`INSERT INTO policies_new(id, tenant, name, project_name, document, updated_at)
+			SELECT id, 'default', name, project_name, document, updated_at FROM policies`,
+		`DROP TABLE policies`,
+		`ALTER TABLE policies_new RENAME TO policies`,
+
+		`ALTER TABLE api_keys ADD COLUMN tenant TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(name)`,
+	)},
+	{version: 6, name: "create audit log", apply: execStatements(
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			tenant TEXT NOT NULL,
+			actor TEXT NOT NULL,
+			action TEXT NOT NULL,
+			project_name TEXT NOT NULL DEFAULT '',
+			version TEXT NOT NULL DEFAULT '',
+			dependency_name TEXT NOT NULL DEFAULT '',
+			target TEXT NOT NULL DEFAULT '',
+			before TEXT NOT NULL,
+			after TEXT NOT NULL,
+			request_id TEXT NOT NULL DEFAULT '',
+			created_at INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_created ON audit_log(tenant, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_project ON audit_log(tenant, project_name, version)`,
+		// append-only, entries are never changed nor removed
+		`CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+			BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END`,
+		`CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+			BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END`,
+	)},
+	// rows stored before are treated as fetched, manual ones cannot be told apart
+	{version: 7, name: "add dependency source and score override", apply: execStatements(
+		`ALTER TABLE dependency ADD COLUMN source TEXT NOT NULL DEFAULT 'fetched'`,
+		`ALTER TABLE dependency ADD COLUMN override_score REAL`,
+		`ALTER TABLE dependency ADD COLUMN override_set_at INTEGER`,
+		`ALTER TABLE dependency ADD COLUMN override_expires_at INTEGER`,
+	)},
+	// version of rows stored before is unknown, they are not matched by suppressions with version range
+	{version: 8, name: "add dependency version and suppressions", apply: execStatements(
+		`ALTER TABLE dependency ADD COLUMN version TEXT NOT NULL DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS suppressions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			tenant TEXT NOT NULL REFERENCES tenants(name),
+			dependency_name TEXT NOT NULL,
+			project_name TEXT NOT NULL DEFAULT '',
+			project_version TEXT NOT NULL DEFAULT '',
+			version_range TEXT NOT NULL DEFAULT '',
+			justification TEXT NOT NULL,
+			approver TEXT NOT NULL,
+			expires_at INTEGER NOT NULL,
+			created_at INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_suppressions_tenant_expires ON suppressions(tenant, expires_at)`,
+	)},
+	// metadata belongs to project name, it is shared by every version of the project
+	{version: 9, name: "create project metadata and labels", apply: execStatements(
+		`CREATE TABLE IF NOT EXISTS project_metadata (
+			tenant TEXT NOT NULL REFERENCES tenants(name),
+			project_name TEXT NOT NULL,
+			owner TEXT NOT NULL DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			repo_url TEXT NOT NULL DEFAULT '',
+			updated_at INTEGER NOT NULL,
+			PRIMARY KEY (tenant, project_name)
+		)`,
+		`CREATE TABLE IF NOT EXISTS project_labels (
+			tenant TEXT NOT NULL,
+			project_name TEXT NOT NULL,
+			key TEXT NOT NULL,
+			value TEXT NOT NULL,
+			PRIMARY KEY (tenant, project_name, key),
+			FOREIGN KEY (tenant, project_name) REFERENCES project_metadata(tenant, project_name) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_project_labels_key_value ON project_labels(tenant, key, value)`,
+	)},
+}
+
+func migrate(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at INTEGER NOT NULL
+		)`)
+	if err != nil {
+		return fmt.Errorf("db.Exec(create schema_migrations): %w", err)
+	}
+
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+func appliedMigrations(db *sqlx.DB) (map[int]bool, error) {
+	rows, err := db.Query("SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("db.Query(schema_migrations): %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int]bool{}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("rows.Scan(): %w", err)
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return applied, nil
+}
+
+func applyMigration(db *sqlx.DB, m migration) error {
+	ctx := context.Background()
+	// PRAGMA foreign_keys is per connection and ignored inside a transaction
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("db.Conn(): %w", err)
+	}
+	defer conn.Close()
+
+	if m.rebuild {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return fmt.Errorf("conn.ExecContext(foreign_keys off): %w", err)
+		}
+		defer func() { _, _ = conn.ExecContext(ctx, "PRAGMA foreign_keys = ON") }()
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("conn.BeginTx(): %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := m.apply(tx); err != nil {
+		return err
+	}
+
+	if m.rebuild {
+		if err := checkForeignKeys(tx); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("INSERT INTO schema_migrations(version, name, applied_at) VALUES (?, ?, ?)",
+		m.version, m.name, time.Now().Unix()); err != nil {
+		return fmt.Errorf("tx.Exec(schema_migrations): %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit(): %w", err)
+	}
+	return nil
+}
+
+func checkForeignKeys(tx *sql.Tx) error {
+	rows, err := tx.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return fmt.Errorf("tx.Query(foreign_key_check): %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table string
+		var rowID sql.NullInt64
+		var parent string
+		var fkID int
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return fmt.Errorf("rows.Scan(foreign_key_check): %w", err)
+		}
+		return fmt.Errorf("foreign key violation in %s row %d referencing %s", table, rowID.Int64, parent)
+	}
+	return rows.Err()
+}
+
+func execStatements(statements ...string) func(tx execer) error {
+	return func(tx execer) error {
+		for _, stmt := range statements {
+			if _, err := tx.Exec(stmt); err != nil {
+				return fmt.Errorf("tx.Exec(): %w", err)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"depsmanager"
+)
+
+func TestMigrate_ReopenIsIdempotent(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "deps.db")
+	ctx := context.Background()
+
+	st, err := NewStorage(depsmanager.SQLLiteConfig{DBPath: dbPath})
+	if err != nil {
+		t.Fatalf("NewStorage(first): %v", err)
+	}
+	if err := st.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+		Project: depsmanager.Project{Name: "react", Version: "18.3.1", UpdatedAt: time.Now().Unix()},
+		Dependencies: []depsmanager.Dependency{
+			{Name: "scheduler", Score: 5, UpdatedAt: time.Now().Unix(), Relation: depsmanager.RelationDirect},
+		},
+	}); err != nil {
+		t.Fatalf("StoreDependencies: %v", err)
+	}
+	if err := st.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	st, err = NewStorage(depsmanager.SQLLiteConfig{DBPath: dbPath})
+	if err != nil {
+		t.Fatalf("NewStorage(reopen): %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+
+	var applied int
+	if err := st.db.Get(&applied, "SELECT COUNT(*) FROM schema_migrations"); err != nil {
+		t.Fatalf("count schema_migrations: %v", err)
+	}
+	if applied != len(migrations) {
+		t.Fatalf("expected %d applied migrations, got %d", len(migrations), applied)
+	}
+
+	deps, err := st.ListProjectDependencies(ctx, "react", "18.3.1")
+	if err != nil {
+		t.Fatalf("ListProjectDependencies: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Relation != depsmanager.RelationDirect {
+		t.Fatalf("unexpected deps after reopen: %+v", deps)
+	}
+}
+
+func TestPing(t *testing.T) {
+	ctx := context.Background()
+	st, err := NewStorage(depsmanager.SQLLiteConfig{DBPath: filepath.Join(t.TempDir(), "deps.db")})
+	if err != nil {
+		t.Fatalf("NewStorage: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+
+	if err := st.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if _, err := st.db.Exec("DELETE FROM schema_migrations WHERE version = ?", migrations[len(migrations)-1].version); err != nil {
+		t.Fatalf("delete migration: %v", err)
+	}
+	if err := st.Ping(ctx); err == nil {
+		t.Fatal("Ping: expected error with a pending migration")
+	}
+}
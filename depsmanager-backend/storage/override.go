@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"depsmanager"
+	"errors"
+	"fmt"
+)
+
+// dependencyColumns are read by scanDependency, d is alias of dependency table.
+const dependencyColumns = "d.dependency_name, d.version, d.score, d.updated_at, d.relation, d.source, d.override_score, d.override_set_at, d.override_expires_at"
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanDependency scans dest followed by dependencyColumns. Score of fetched dependency
+// is its upstream score unless there is an override active at now.
+func scanDependency(sc scanner, now int64, dest ...any) (depsmanager.Dependency, error) {
+	var (
+		dep                              depsmanager.Dependency
+		overrideScore                    sql.NullFloat64
+		overrideSetAt, overrideExpiresAt sql.NullInt64
+	)
+	dest = append(dest, &dep.Name, &dep.Version, &dep.Score, &dep.UpdatedAt, &dep.Relation, &dep.Source, &overrideScore, &overrideSetAt, &overrideExpiresAt)
+	if err := sc.Scan(dest...); err != nil {
+		return depsmanager.Dependency{}, err
+	}
+	if dep.Source == depsmanager.SourceManual {
+		return dep, nil
+	}
+
+	upstream := dep.Score
+	dep.UpstreamScore = &upstream
+	if overrideScore.Valid && (!overrideExpiresAt.Valid || overrideExpiresAt.Int64 > now) {
+		dep.Score = overrideScore.Float64
+		dep.Override = &depsmanager.ScoreOverride{Score: overrideScore.Float64, SetAt: overrideSetAt.Int64}
+		if overrideExpiresAt.Valid {
+			expiresAt := overrideExpiresAt.Int64
+			dep.Override.ExpiresAt = &expiresAt
+		}
+	}
+	return dep, nil
+}
+
+// effectiveScore is SQL expression of the score returned by scanDependency.
+func effectiveScore(now int64) string {
+	return fmt.Sprintf("(CASE WHEN d.override_score IS NOT NULL AND (d.override_expires_at IS NULL OR d.override_expires_at > %d) THEN d.override_score ELSE d.score END)", now)
+}
+
+// ClearScoreOverride removes override of fetched dependency, its score is the upstream one again.
+func (s *Storage) ClearScoreOverride(ctx context.Context, projectName, version, depName string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("db.BeginTx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	projectID, err := s.getProjectIDTX(ctx, tx, projectName, version)
+	if err != nil {
+		return fmt.Errorf("getProjectIDTX(%s,%s): %w", projectName, version, err)
+	}
+
+	now := s.now().Unix()
+	before, err := getDependencyTX(ctx, tx, projectID, depName, now)
+	if err != nil {
+		return fmt.Errorf("getDependencyTX(%s): %w", depName, err)
+	}
+	if before.Override == nil {
+		return depsmanager.ErrOverrideNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE dependency
+		   SET override_score = NULL, override_set_at = NULL, override_expires_at = NULL
+		 WHERE project_id = ? AND dependency_name = ?`,
+		projectID, depName,
+	)
+	if err != nil {
+		return fmt.Errorf("UPDATE dependency(%s) override: %w", depName, err)
+	}
+
+	after := before
+	after.Score, after.Override = *before.UpstreamScore, nil
+	err = appendAudit(ctx, tx, now, depsmanager.AuditEntry{
+		Action:         depsmanager.AuditOverrideClear,
+		ProjectName:    projectName,
+		Version:        version,
+		DependencyName: depName,
+	}, before, after)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+	return nil
+}
+
+func getDependencyTX(ctx context.Context, tx *sql.Tx, projectID int64, depName string, now int64) (depsmanager.Dependency, error) {
+	row := tx.QueryRowContext(ctx,
+		"SELECT "+dependencyColumns+" FROM dependency d WHERE d.project_id = ? AND d.dependency_name = ?",
+		projectID, depName,
+	)
+	dep, err := scanDependency(row, now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return depsmanager.Dependency{}, depsmanager.ErrDependencyNotFound
+		}
+		return depsmanager.Dependency{}, fmt.Errorf("tx.QueryRowContext(): %w", err)
+	}
+	return dep, nil
+}
+
+func listDependenciesTX(ctx context.Context, tx *sql.Tx, projectID int64, now int64) ([]depsmanager.Dependency, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT "+dependencyColumns+" FROM dependency d WHERE d.project_id = ?", projectID)
+	if err != nil {
+		return nil, fmt.Errorf("tx.QueryContext(): %w", err)
+	}
+	defer rows.Close()
+
+	var deps []depsmanager.Dependency
+	for rows.Next() {
+		dep, err := scanDependency(rows, now)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan(): %w", err)
+		}
+		deps = append(deps, dep)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return deps, nil
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"depsmanager"
+)
+
+func depsByName(t *testing.T, st *Storage, project, version string) map[string]depsmanager.Dependency {
+	t.Helper()
+	deps, err := st.ListProjectDependencies(context.Background(), project, version)
+	if err != nil {
+		t.Fatalf("ListProjectDependencies: %v", err)
+	}
+	out := make(map[string]depsmanager.Dependency, len(deps))
+	for _, d := range deps {
+		out[d.Name] = d
+	}
+	return out
+}
+
+func TestRefresh_KeepsManualDependenciesAndOverrides(t *testing.T) {
+	st := newInMemoryStorage(t)
+	ctx := context.Background()
+	fetch := func(deps ...depsmanager.Dependency) {
+		t.Helper()
+		if err := st.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+			Project:      depsmanager.Project{Name: "react", Version: "18.3.1", UpdatedAt: 1},
+			Dependencies: deps,
+		}); err != nil {
+			t.Fatalf("StoreDependencies: %v", err)
+		}
+	}
+
+	fetch(depsmanager.Dependency{Name: "scheduler", Score: 5, UpdatedAt: 1}, depsmanager.Dependency{Name: "loose-envify", Score: 6, UpdatedAt: 1})
+	if err := st.AddDependency(ctx, "react", "18.3.1", depsmanager.Dependency{Name: "internal-lib", Score: 9, UpdatedAt: 2}); err != nil {
+		t.Fatalf("AddDependency: %v", err)
+	}
+	if err := st.UpdateDependency(ctx, "react", "18.3.1", depsmanager.Dependency{Name: "scheduler", Score: 8, UpdatedAt: 3}); err != nil {
+		t.Fatalf("UpdateDependency: %v", err)
+	}
+
+	// upstream changes scheduler, drops loose-envify and does not know internal-lib
+	fetch(depsmanager.Dependency{Name: "scheduler", Score: 4, UpdatedAt: 10})
+
+	got := depsByName(t, st, "react", "18.3.1")
+	if len(got) != 2 {
+		t.Fatalf("expected scheduler and internal-lib, got %+v", got)
+	}
+	scheduler := got["scheduler"]
+	if scheduler.Source != depsmanager.SourceFetched || scheduler.Score != 8 || *scheduler.UpstreamScore != 4 ||
+		scheduler.UpdatedAt != 10 || scheduler.Override == nil || scheduler.Override.SetAt != 3 {
+		t.Fatalf("unexpected scheduler: %+v", scheduler)
+	}
+	manual := got["internal-lib"]
+	if manual.Source != depsmanager.SourceManual || manual.Score != 9 || manual.UpstreamScore != nil || manual.Override != nil {
+		t.Fatalf("unexpected internal-lib: %+v", manual)
+	}
+
+	// a manual row reported upstream stays manual
+	fetch(depsmanager.Dependency{Name: "scheduler", Score: 4, UpdatedAt: 10}, depsmanager.Dependency{Name: "internal-lib", Score: 1, UpdatedAt: 10})
+	if manual := depsByName(t, st, "react", "18.3.1")["internal-lib"]; manual.Source != depsmanager.SourceManual || manual.Score != 9 {
+		t.Fatalf("unexpected internal-lib after refresh: %+v", manual)
+	}
+
+	// updating manual dependency changes its score, there is nothing upstream to override
+	if err := st.UpdateDependency(ctx, "react", "18.3.1", depsmanager.Dependency{Name: "internal-lib", Score: 7, UpdatedAt: 11}); err != nil {
+		t.Fatalf("UpdateDependency(manual): %v", err)
+	}
+	if manual := depsByName(t, st, "react", "18.3.1")["internal-lib"]; manual.Score != 7 || manual.UpdatedAt != 11 || manual.Override != nil {
+		t.Fatalf("unexpected internal-lib after update: %+v", manual)
+	}
+}
+
+func TestScoreOverride_ExpiresAndClears(t *testing.T) {
+	st := newInMemoryStorage(t)
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	st.now = func() time.Time { return now }
+
+	if err := st.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+		Project:      depsmanager.Project{Name: "react", Version: "18.3.1", UpdatedAt: 1},
+		Dependencies: []depsmanager.Dependency{{Name: "scheduler", Score: 5, UpdatedAt: 1}},
+	}); err != nil {
+		t.Fatalf("StoreDependencies: %v", err)
+	}
+	expiresAt := int64(2000)
+	override := depsmanager.Dependency{Name: "scheduler", Score: 8, UpdatedAt: 1000, Override: &depsmanager.ScoreOverride{ExpiresAt: &expiresAt}}
+	if err := st.UpdateDependency(ctx, "react", "18.3.1", override); err != nil {
+		t.Fatalf("UpdateDependency: %v", err)
+	}
+
+	if dep := depsByName(t, st, "react", "18.3.1")["scheduler"]; dep.Score != 8 || *dep.Override.ExpiresAt != expiresAt {
+		t.Fatalf("unexpected scheduler with override: %+v", dep)
+	}
+	names, err := st.GetDependenciesByExactScore(ctx, 8)
+	if err != nil || len(names) != 1 {
+		t.Fatalf("GetDependenciesByExactScore(8) = %v, %v", names, err)
+	}
+
+	now = time.Unix(expiresAt, 0)
+	if dep := depsByName(t, st, "react", "18.3.1")["scheduler"]; dep.Score != 5 || dep.Override != nil {
+		t.Fatalf("unexpected scheduler after expiry: %+v", dep)
+	}
+	if names, _ := st.GetDependenciesByExactScore(ctx, 8); len(names) != 0 {
+		t.Fatalf("expected no dependency with expired override score, got %v", names)
+	}
+	if err := st.ClearScoreOverride(ctx, "react", "18.3.1", "scheduler"); !errors.Is(err, depsmanager.ErrOverrideNotFound) {
+		t.Fatalf("expected ErrOverrideNotFound for expired override, got: %v", err)
+	}
+
+	override.Override = nil
+	if err := st.UpdateDependency(ctx, "react", "18.3.1", override); err != nil {
+		t.Fatalf("UpdateDependency: %v", err)
+	}
+	if err := st.ClearScoreOverride(ctx, "react", "18.3.1", "scheduler"); err != nil {
+		t.Fatalf("ClearScoreOverride: %v", err)
+	}
+	if dep := depsByName(t, st, "react", "18.3.1")["scheduler"]; dep.Score != 5 || dep.Override != nil {
+		t.Fatalf("unexpected scheduler after clear: %+v", dep)
+	}
+	if err := st.ClearScoreOverride(ctx, "react", "18.3.1", "missing"); !errors.Is(err, depsmanager.ErrDependencyNotFound) {
+		t.Fatalf("expected ErrDependencyNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"depsmanager"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+func (s *Storage) SavePolicy(ctx context.Context, p depsmanager.Policy) error {
+	t, err := singleTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("s.db.BeginTx(): %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before, err := getPolicyDocumentTX(ctx, tx, t, p.ProjectName, p.Name)
+	if err != nil {
+		return fmt.Errorf("getPolicyDocumentTX(%s): %w", p.Name, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO policies(tenant, name, project_name, document, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(tenant, project_name, name) DO UPDATE
+		   SET document = excluded.document, updated_at = excluded.updated_at`,
+		t, p.Name, p.ProjectName, p.Document, p.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("tx.ExecContext(upsert policy %s): %w", p.Name, err)
+	}
+
+	err = appendAudit(ctx, tx, s.now().Unix(), depsmanager.AuditEntry{
+		Action:      depsmanager.AuditPolicySave,
+		ProjectName: p.ProjectName,
+		Target:      p.Name,
+	}, before, p.Document)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit(): %w", err)
+	}
+	return nil
+}
+
+// getPolicyDocumentTX returns document of the policy or nil when it does not exist.
+func getPolicyDocumentTX(ctx context.Context, tx *sql.Tx, tenant, projectName, name string) (*string, error) {
+	var document string
+	err := tx.QueryRowContext(ctx,
+		"SELECT document FROM policies WHERE tenant = ? AND project_name = ? AND name = ?", tenant, projectName, name,
+	).Scan(&document)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("tx.QueryRowContext(policy document): %w", err)
+	}
+	return &document, nil
+}
+
+// ListPolicies returns global policies together with policies of projectName.
+// Global policies are global within tenant. Rules are not decoded, callers should parse Document.
+func (s *Storage) ListPolicies(ctx context.Context, projectName string) ([]depsmanager.Policy, error) {
+	filter, args := tenantFilter(ctx, "tenant")
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT tenant, name, project_name, document, updated_at
+		FROM policies
+		WHERE (project_name = '' OR project_name = ?) AND `+filter+`
+		ORDER BY tenant, project_name, name
+	`, append([]any{projectName}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.QueryContext(ListPolicies): %w", err)
+	}
+	defer rows.Close()
+
+	policies := []depsmanager.Policy{}
+	for rows.Next() {
+		var p depsmanager.Policy
+		if err := rows.Scan(&p.Tenant, &p.Name, &p.ProjectName, &p.Document, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("rows.Scan(policy): %w", err)
+		}
+		policies = append(policies, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return policies, nil
+}
+
+func (s *Storage) DeletePolicy(ctx context.Context, projectName, name string) error {
+	t, err := singleTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("s.db.BeginTx(): %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before, err := getPolicyDocumentTX(ctx, tx, t, projectName, name)
+	if err != nil {
+		return fmt.Errorf("getPolicyDocumentTX(%s): %w", name, err)
+	}
+	if before == nil {
+		return depsmanager.ErrPolicyNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM policies WHERE tenant = ? AND project_name = ? AND name = ?", t, projectName, name)
+	if err != nil {
+		return fmt.Errorf("tx.ExecContext(delete policy %s): %w", name, err)
+	}
+
+	err = appendAudit(ctx, tx, s.now().Unix(), depsmanager.AuditEntry{
+		Action:      depsmanager.AuditPolicyDelete,
+		ProjectName: projectName,
+		Target:      name,
+	}, before, nil)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit(): %w", err)
+	}
+	return nil
+}
+
+// StorePolicyEvaluation keeps the latest evaluation of a project version, replacing the previous one.
+func (s *Storage) StorePolicyEvaluation(ctx context.Context, eval depsmanager.PolicyEvaluation) error {
+	projectID, err := s.getProjectID(ctx, eval.ProjectName, eval.Version)
+	if err != nil {
+		return fmt.Errorf("s.getProjectID(): %w", err)
+	}
+
+	violations, err := json.Marshal(eval.Violations)
+	if err != nil {
+		return fmt.Errorf("json.Marshal(violations): %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO policy_evaluations(project_id, verdict, violations, evaluated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(project_id) DO UPDATE
+		   SET verdict = excluded.verdict, violations = excluded.violations, evaluated_at = excluded.evaluated_at`,
+		projectID, eval.Verdict, string(violations), eval.EvaluatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("s.db.ExecContext(upsert policy evaluation): %w", err)
+	}
+	return nil
+}
+
+func (s *Storage) GetPolicyEvaluation(ctx context.Context, projectName, version string) (depsmanager.PolicyEvaluation, error) {
+	projectID, err := s.getProjectID(ctx, projectName, version)
+	if err != nil {
+		return depsmanager.PolicyEvaluation{}, fmt.Errorf("s.getProjectID(): %w", err)
+	}
+
+	eval := depsmanager.PolicyEvaluation{ProjectName: projectName, Version: version}
+	var violations string
+	err = s.db.QueryRowContext(ctx,
+		"SELECT verdict, violations, evaluated_at FROM policy_evaluations WHERE project_id = ?", projectID,
+	).Scan(&eval.Verdict, &violations, &eval.EvaluatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return depsmanager.PolicyEvaluation{}, depsmanager.ErrPolicyEvaluationNotFound
+		}
+		return depsmanager.PolicyEvaluation{}, fmt.Errorf("s.db.QueryRowContext(policy evaluation): %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(violations), &eval.Violations); err != nil {
+		return depsmanager.PolicyEvaluation{}, fmt.Errorf("json.Unmarshal(violations): %w", err)
+	}
+
+	return eval, nil
+}
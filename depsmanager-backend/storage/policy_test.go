@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"depsmanager"
+)
+
+func TestPolicies_SaveListDelete(t *testing.T) {
+	st := newInMemoryStorage(t)
+	ctx := context.Background()
+
+	for _, p := range []depsmanager.Policy{
+		{Name: "global", Document: "name: global", UpdatedAt: 1},
+		{Name: "strict", ProjectName: "react", Document: "name: strict", UpdatedAt: 2},
+		{Name: "strict", ProjectName: "vue", Document: "name: strict", UpdatedAt: 3},
+	} {
+		if err := st.SavePolicy(ctx, p); err != nil {
+			t.Fatalf("SavePolicy(%s/%s): %v", p.ProjectName, p.Name, err)
+		}
+	}
+
+	// upsert keeps a single row per (project, name)
+	if err := st.SavePolicy(ctx, depsmanager.Policy{Name: "global", Document: "name: global # v2", UpdatedAt: 4}); err != nil {
+		t.Fatalf("SavePolicy(update): %v", err)
+	}
+
+	got, err := st.ListPolicies(ctx, "react")
+	if err != nil {
+		t.Fatalf("ListPolicies(react): %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected global and react policies, got: %+v", got)
+	}
+	if got[0].Name != "global" || got[0].Document != "name: global # v2" || got[0].UpdatedAt != 4 {
+		t.Fatalf("unexpected global policy: %+v", got[0])
+	}
+	if got[1].Name != "strict" || got[1].ProjectName != "react" {
+		t.Fatalf("unexpected project policy: %+v", got[1])
+	}
+
+	if err := st.DeletePolicy(ctx, "react", "strict"); err != nil {
+		t.Fatalf("DeletePolicy: %v", err)
+	}
+	if err := st.DeletePolicy(ctx, "react", "strict"); !errors.Is(err, depsmanager.ErrPolicyNotFound) {
+		t.Fatalf("expected ErrPolicyNotFound, got: %v", err)
+	}
+}
+
+func TestPolicyEvaluation_StoreAndGet(t *testing.T) {
+	st := newInMemoryStorage(t)
+	ctx := context.Background()
+
+	if err := st.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+		Project:      depsmanager.Project{Name: "react", Version: "18.3.1", UpdatedAt: time.Now().Unix()},
+		Dependencies: makeDeps("scheduler"),
+	}); err != nil {
+		t.Fatalf("StoreDependencies: %v", err)
+	}
+
+	if _, err := st.GetPolicyEvaluation(ctx, "react", "18.3.1"); !errors.Is(err, depsmanager.ErrPolicyEvaluationNotFound) {
+		t.Fatalf("expected ErrPolicyEvaluationNotFound, got: %v", err)
+	}
+
+	eval := depsmanager.PolicyEvaluation{
+		ProjectName: "react",
+		Version:     "18.3.1",
+		Verdict:     depsmanager.VerdictFail,
+		Violations:  []depsmanager.PolicyViolation{{Policy: "p", Rule: depsmanager.PolicyRuleMinScore, DependencyName: "scheduler", Message: "low"}},
+		EvaluatedAt: 10,
+	}
+	if err := st.StorePolicyEvaluation(ctx, eval); err != nil {
+		t.Fatalf("StorePolicyEvaluation: %v", err)
+	}
+	eval.Verdict = depsmanager.VerdictPass
+	eval.Violations = []depsmanager.PolicyViolation{}
+	eval.EvaluatedAt = 20
+	if err := st.StorePolicyEvaluation(ctx, eval); err != nil {
+		t.Fatalf("StorePolicyEvaluation(replace): %v", err)
+	}
+
+	got, err := st.GetPolicyEvaluation(ctx, "react", "18.3.1")
+	if err != nil {
+		t.Fatalf("GetPolicyEvaluation: %v", err)
+	}
+	if got.Verdict != depsmanager.VerdictPass || got.EvaluatedAt != 20 || len(got.Violations) != 0 {
+		t.Fatalf("unexpected evaluation: %+v", got)
+	}
+
+	// evaluation is removed together with project
+	if err := st.DeleteProject(ctx, "react", "18.3.1"); err != nil {
+		t.Fatalf("DeleteProject: %v", err)
+	}
+	if _, err := st.GetPolicyEvaluation(ctx, "react", "18.3.1"); !errors.Is(err, depsmanager.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound, got: %v", err)
+	}
+}
+
+func TestPolicyEvaluation_ProjectNotFound(t *testing.T) {
+	st := newInMemoryStorage(t)
+
+	err := st.StorePolicyEvaluation(context.Background(), depsmanager.PolicyEvaluation{ProjectName: "ghost", Version: "1.0.0"})
+	if !errors.Is(err, depsmanager.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound, got: %v", err)
+	}
+}
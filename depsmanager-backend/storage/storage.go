@@ -7,6 +7,7 @@ import (
 	"depsmanager/pkg/diff"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
@@ -14,6 +15,8 @@ import (
 
 type Storage struct {
 	db *sqlx.DB
+	// now decides whether score overrides are still active
+	now func() time.Time
 }
 
 func NewStorage(conf depsmanager.SQLLiteConfig) (*Storage, error) {
@@ -26,14 +29,19 @@ func NewStorage(conf depsmanager.SQLLiteConfig) (*Storage, error) {
 		return nil, fmt.Errorf("db.Ping(): %w", err)
 	}
 
-	if err := createTable(db); err != nil {
-		return nil, err
+	if err := migrate(db); err != nil {
+		return nil, fmt.Errorf("migrate(): %w", err)
 	}
 
-	return &Storage{db: db}, nil
+	return &Storage{db: db, now: time.Now}, nil
 }
 
 func (s *Storage) StoreDependencies(ctx context.Context, deps depsmanager.ProjectDependencyRecord) error {
+	t, err := singleTenant(ctx)
+	if err != nil {
+		return err
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("s.db.BeginTx(): %w", err)
@@ -42,8 +50,8 @@ func (s *Storage) StoreDependencies(ctx context.Context, deps depsmanager.Projec
 		_ = tx.Rollback()
 	}()
 
-	exec, err := tx.Exec("INSERT OR IGNORE INTO projects(name, updated_at, version) VALUES (?, ?, ?);",
-		deps.Project.Name, deps.Project.UpdatedAt, deps.Project.Version)
+	exec, err := tx.Exec("INSERT OR IGNORE INTO projects(tenant, name, updated_at, version) VALUES (?, ?, ?, ?);",
+		t, deps.Project.Name, deps.Project.UpdatedAt, deps.Project.Version)
 	if err != nil {
 		return fmt.Errorf("tx.Exec(project): %w", err)
 	}
@@ -69,19 +77,28 @@ func (s *Storage) StoreDependencies(ctx context.Context, deps depsmanager.Projec
 		return fmt.Errorf("exec.LastInsertId(): %w", err)
 	}
 
-	preparedDependency, err := tx.PrepareContext(ctx, "INSERT INTO dependency(project_id, dependency_name, score, updated_at) VALUES (?, ?, ?, ?)")
+	preparedDependency, err := tx.PrepareContext(ctx, "INSERT INTO dependency(project_id, dependency_name, version, score, updated_at, relation) VALUES (?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return fmt.Errorf("tx.PrepareContext(): %w", err)
 	}
 	defer preparedDependency.Close()
 
 	for _, dependency := range deps.Dependencies {
-		_, err = preparedDependency.Exec(id, dependency.Name, dependency.Score, dependency.UpdatedAt)
+		_, err = preparedDependency.Exec(id, dependency.Name, dependency.Version, dependency.Score, dependency.UpdatedAt, dependency.Relation)
 		if err != nil {
 			return fmt.Errorf("preparedDependency.Exec(): %w, dependencyName: %v", err, dependency.Name)
 		}
 	}
 
+	err = appendAudit(ctx, tx, s.now().Unix(), depsmanager.AuditEntry{
+		Action:      depsmanager.AuditProjectFetch,
+		ProjectName: deps.Project.Name,
+		Version:     deps.Project.Version,
+	}, nil, deps.Dependencies)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
+	}
+
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("tx.Commit(): %w", err)
 	}
@@ -104,24 +121,34 @@ func (s *Storage) UpdateProject(ctx context.Context, deps depsmanager.ProjectDep
 		return fmt.Errorf("s.getProjectIDTX(): %w", err)
 	}
 
-	// Fetch current dependencies from DB
-	rows, err := tx.QueryContext(ctx, "SELECT dependency_name, score, updated_at FROM dependency WHERE project_id = ?", projectId)
+	// get current dependencies for comparison
+	now := s.now().Unix()
+	currentDeps, err := listDependenciesTX(ctx, tx, projectId, now)
 	if err != nil {
-		return fmt.Errorf("tx.QueryContext(): %w", err)
+		return fmt.Errorf("listDependenciesTX(): %w", err)
 	}
-	defer rows.Close()
 
-	// get current dependencies for comparison
-	var currentDeps []depsmanager.Dependency
-	for rows.Next() {
-		var dep depsmanager.Dependency
-		if err := rows.Scan(&dep.Name, &dep.Score, &dep.UpdatedAt); err != nil {
-			return fmt.Errorf("rows.Scan(&name, &score): %w", err)
+	// manual dependencies are kept as they are, fetched ones are compared by their upstream values
+	manual := make(map[string]struct{})
+	fetched := make(map[string]struct{})
+	var currentFetched []depsmanager.Dependency
+	for _, dep := range currentDeps {
+		if dep.Source == depsmanager.SourceManual {
+			manual[dep.Name] = struct{}{}
+			continue
 		}
-		currentDeps = append(currentDeps, dep)
-	}
-	if err = rows.Err(); err != nil {
-		return fmt.Errorf("rows.Err(): %w", err)
+		fetched[dep.Name] = struct{}{}
+		dep.Score, dep.Override = *dep.UpstreamScore, nil
+		currentFetched = append(currentFetched, dep)
+	}
+	var newDeps []depsmanager.Dependency
+	newNames := make(map[string]struct{})
+	for _, dep := range deps.Dependencies {
+		if _, ok := manual[dep.Name]; ok {
+			continue
+		}
+		newNames[dep.Name] = struct{}{}
+		newDeps = append(newDeps, dep)
 	}
 
 	// update timestamp
@@ -132,9 +159,9 @@ func (s *Storage) UpdateProject(ctx context.Context, deps depsmanager.ProjectDep
 	}
 
 	// Compare with new dependencies
-	toDel, toAdd := diff.DiffDependencies(currentDeps, deps.Dependencies)
+	toDel, toAdd := diff.DiffDependencies(currentFetched, newDeps)
 
-	// nothing changed
+	// nothing changed upstream, manual dependencies and overrides are kept as they are
 	if len(toAdd) == 0 && len(toDel) == 0 {
 		if err = tx.Commit(); err != nil {
 			return fmt.Errorf("tx.Commit(): %w", err)
@@ -142,20 +169,44 @@ func (s *Storage) UpdateProject(ctx context.Context, deps depsmanager.ProjectDep
 		return nil
 	}
 
-	// Delete old dependencies
-	if err := s.deleteDependenciesFromProject(ctx, tx, projectId, toDel); err != nil {
+	// record only what changed
+	err = appendAudit(ctx, tx, now, depsmanager.AuditEntry{
+		Action:      depsmanager.AuditProjectFetch,
+		ProjectName: deps.Project.Name,
+		Version:     deps.Project.Version,
+	}, toDel, toAdd)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
+	}
+
+	// Delete dependencies gone upstream
+	var removed []depsmanager.Dependency
+	for _, dep := range toDel {
+		if _, ok := newNames[dep.Name]; !ok {
+			removed = append(removed, dep)
+		}
+	}
+	if err := s.deleteDependenciesFromProject(ctx, tx, projectId, removed); err != nil {
 		return fmt.Errorf("s.deleteDependenciesFromProject(): %w", err)
 	}
 
-	// Insert new dependencies
-	stmt, err := tx.PrepareContext(ctx, "INSERT INTO dependency(project_id, dependency_name, score, updated_at) VALUES (?, ?, ?, ?)")
+	// Insert new dependencies, update changed ones in place to keep their overrides
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO dependency(project_id, dependency_name, version, score, updated_at, relation, source) VALUES (?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return fmt.Errorf("tx.PrepareContext(): %w", err)
 	}
 	defer stmt.Close()
 
 	for _, dep := range toAdd {
-		_, err = stmt.Exec(projectId, dep.Name, dep.Score, dep.UpdatedAt)
+		if _, ok := fetched[dep.Name]; ok {
+			_, err = tx.ExecContext(ctx, "UPDATE dependency SET version = ?, score = ?, updated_at = ?, relation = ? WHERE project_id = ? AND dependency_name = ?",
+				dep.Version, dep.Score, dep.UpdatedAt, dep.Relation, projectId, dep.Name)
+			if err != nil {
+				return fmt.Errorf("tx.ExecContext(update dependency %s): %w", dep.Name, err)
+			}
+			continue
+		}
+		_, err = stmt.Exec(projectId, dep.Name, dep.Version, dep.Score, dep.UpdatedAt, dep.Relation, depsmanager.SourceFetched)
 		if err != nil {
 			return fmt.Errorf("stmt.Exec(projectId, dep.Name, dep.Score): %w", err)
 		}
@@ -183,6 +234,12 @@ func (s *Storage) DeleteProject(ctx context.Context, projectName, version string
 		return fmt.Errorf("s.getProjectIDTX(): %w", err)
 	}
 
+	now := s.now().Unix()
+	currentDeps, err := listDependenciesTX(ctx, tx, projectId, now)
+	if err != nil {
+		return fmt.Errorf("listDependenciesTX(): %w", err)
+	}
+
 	execContext, err := tx.ExecContext(ctx, "DELETE FROM projects WHERE id = ?", projectId)
 	if err != nil {
 		return fmt.Errorf("tx.ExecContext(delete project): %w", err)
@@ -193,6 +250,15 @@ func (s *Storage) DeleteProject(ctx context.Context, projectName, version string
 		return fmt.Errorf("execContext.RowsAffected(): %w", err)
 	}
 
+	err = appendAudit(ctx, tx, now, depsmanager.AuditEntry{
+		Action:      depsmanager.AuditProjectDelete,
+		ProjectName: projectName,
+		Version:     version,
+	}, currentDeps, nil)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("tx.Commit(): %w", err)
 	}
@@ -206,18 +272,20 @@ func (s *Storage) ListProjectDependencies(ctx context.Context, projectName, vers
 		return nil, fmt.Errorf("s.getProjectID(): %w", err)
 	}
 
-	dependenciesRows, err := s.db.QueryContext(ctx, "SELECT dependency_name, score, updated_at FROM dependency WHERE project_id = ?", projectId)
+	dependenciesRows, err := s.db.QueryContext(ctx, "SELECT "+dependencyColumns+" FROM dependency d WHERE d.project_id = ?", projectId)
 	if err != nil {
 		return nil, fmt.Errorf("s.db.QueryContext(): %w", err)
 	}
 	defer dependenciesRows.Close()
 
+	now := s.now().Unix()
 	result := []depsmanager.Dependency{}
 	for dependenciesRows.Next() {
-		var dep depsmanager.Dependency
-		if err = dependenciesRows.Scan(&dep.Name, &dep.Score, &dep.UpdatedAt); err != nil {
+		dep, err := scanDependency(dependenciesRows, now)
+		if err != nil {
 			return nil, fmt.Errorf("rows.Scan(): %w", err)
 		}
+		dep.ProjectName, dep.ProjectVersion = projectName, version
 		result = append(result, dep)
 	}
 	if err = dependenciesRows.Err(); err != nil {
@@ -227,8 +295,40 @@ func (s *Storage) ListProjectDependencies(ctx context.Context, projectName, vers
 	return result, nil
 }
 
+func (s *Storage) ListAllDependencies(ctx context.Context) ([]depsmanager.Dependency, error) {
+	filter, args := tenantFilter(ctx, "p.tenant")
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT d.project_id, p.tenant, p.name, p.version, `+dependencyColumns+`
+		FROM dependency d
+		JOIN projects p ON p.id = d.project_id
+		WHERE `+filter, args...)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.QueryContext(): %w", err)
+	}
+	defer rows.Close()
+
+	now := s.now().Unix()
+	result := []depsmanager.Dependency{}
+	for rows.Next() {
+		var projectID int64
+		var projectTenant, projectName, projectVersion string
+		dep, err := scanDependency(rows, now, &projectID, &projectTenant, &projectName, &projectVersion)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan(): %w", err)
+		}
+		dep.ProjectID, dep.ProjectTenant, dep.ProjectName, dep.ProjectVersion = projectID, projectTenant, projectName, projectVersion
+		result = append(result, dep)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return result, nil
+}
+
 func (s *Storage) ListProjects(ctx context.Context) ([]depsmanager.Project, error) {
-	rows, err := s.db.QueryContext(ctx, "SELECT name, updated_at, version FROM projects")
+	filter, args := tenantFilter(ctx, "tenant")
+	rows, err := s.db.QueryContext(ctx, "SELECT tenant, name, updated_at, version FROM projects WHERE "+filter, args...)
 	if err != nil {
 		return nil, fmt.Errorf("db.QueryContext(): %w", err)
 	}
@@ -237,7 +337,7 @@ func (s *Storage) ListProjects(ctx context.Context) ([]depsmanager.Project, erro
 	projects := []depsmanager.Project{}
 	for rows.Next() {
 		var p depsmanager.Project
-		if err := rows.Scan(&p.Name, &p.UpdatedAt, &p.Version); err != nil {
+		if err := rows.Scan(&p.Tenant, &p.Name, &p.UpdatedAt, &p.Version); err != nil {
 			return nil, fmt.Errorf("rows.Scan(): %w", err)
 		}
 		projects = append(projects, p)
@@ -245,17 +345,21 @@ func (s *Storage) ListProjects(ctx context.Context) ([]depsmanager.Project, erro
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows.Err(): %w", err)
 	}
+	if err := s.attachMetadata(ctx, projects); err != nil {
+		return nil, fmt.Errorf("s.attachMetadata(): %w", err)
+	}
 
 	return projects, nil
 }
 func (s *Storage) GetProjectsByDependency(ctx context.Context, depName string) ([]depsmanager.Project, error) {
+	filter, args := tenantFilter(ctx, "p.tenant")
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT DISTINCT p.name, p.updated_at, p.version
+		SELECT DISTINCT p.tenant, p.name, p.updated_at, p.version
 		FROM projects p
 		JOIN dependency d ON d.project_id = p.id
-		WHERE d.dependency_name = ?
-		ORDER BY p.name, p.version
-	`, depName)
+		WHERE d.dependency_name = ? AND `+filter+`
+		ORDER BY p.tenant, p.name, p.version
+	`, append([]any{depName}, args...)...)
 	if err != nil {
 		return nil, fmt.Errorf("db.QueryContext(GetProjectsByDependency): %w", err)
 	}
@@ -264,7 +368,7 @@ func (s *Storage) GetProjectsByDependency(ctx context.Context, depName string) (
 	var projects []depsmanager.Project
 	for rows.Next() {
 		var p depsmanager.Project
-		if err := rows.Scan(&p.Name, &p.UpdatedAt, &p.Version); err != nil {
+		if err := rows.Scan(&p.Tenant, &p.Name, &p.UpdatedAt, &p.Version); err != nil {
 			return nil, fmt.Errorf("rows.Scan(project): %w", err)
 		}
 		projects = append(projects, p)
@@ -275,6 +379,9 @@ func (s *Storage) GetProjectsByDependency(ctx context.Context, depName string) (
 	if len(projects) == 0 {
 		return nil, depsmanager.ErrProjectNotFound
 	}
+	if err := s.attachMetadata(ctx, projects); err != nil {
+		return nil, fmt.Errorf("s.attachMetadata(): %w", err)
+	}
 
 	return projects, nil
 }
@@ -283,12 +390,14 @@ func (s *Storage) GetDependenciesByExactScore(ctx context.Context, score float64
 	const eps = 1e-9
 	low, high := score-eps, score+eps
 
+	filter, args := tenantFilter(ctx, "p.tenant")
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT DISTINCT dependency_name
-		FROM dependency
-		WHERE score BETWEEN ? AND ?
-		ORDER BY dependency_name
-	`, low, high)
+		SELECT DISTINCT d.dependency_name
+		FROM dependency d
+		JOIN projects p ON p.id = d.project_id
+		WHERE `+effectiveScore(s.now().Unix())+` BETWEEN ? AND ? AND `+filter+`
+		ORDER BY d.dependency_name
+	`, append([]any{low, high}, args...)...)
 	if err != nil {
 		return nil, fmt.Errorf("db.QueryContext(GetDependenciesByExactScore): %w", err)
 	}
@@ -320,11 +429,12 @@ func (s *Storage) AddDependency(ctx context.Context, projectName, version string
 		return fmt.Errorf("getProjectIDTX(%s,%s): %w", projectName, version, err)
 	}
 
-	// Insert a single dependency row
+	// Insert a single dependency row, an existing one of the name is left as is
 	res, err := tx.ExecContext(ctx,
-		`INSERT INTO dependency(project_id, dependency_name, score, updated_at) 
-         VALUES (?, ?, ?, ?)`,
-		projectID, dep.Name, dep.Score, dep.UpdatedAt,
+		`INSERT INTO dependency(project_id, dependency_name, version, score, updated_at, relation, source) 
+         VALUES (?, ?, ?, ?, ?, ?, ?)
+         ON CONFLICT(project_id, dependency_name) DO NOTHING`,
+		projectID, dep.Name, dep.Version, dep.Score, dep.UpdatedAt, dep.Relation, depsmanager.SourceManual,
 	)
 	if err != nil {
 		return fmt.Errorf("INSERT dependency(%s): %w", dep.Name, err)
@@ -336,6 +446,17 @@ func (s *Storage) AddDependency(ctx context.Context, projectName, version string
 	if aff == 0 {
 		return depsmanager.ErrDependencyAlreadyExists
 	}
+	dep.Source = depsmanager.SourceManual
+
+	err = appendAudit(ctx, tx, s.now().Unix(), depsmanager.AuditEntry{
+		Action:         depsmanager.AuditDependencyAdd,
+		ProjectName:    projectName,
+		Version:        version,
+		DependencyName: dep.Name,
+	}, nil, dep)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
+	}
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("tx.Commit: %w", err)
@@ -355,6 +476,12 @@ func (s *Storage) DeleteDependency(ctx context.Context, projectName, version, de
 		return fmt.Errorf("getProjectIDTX(%s,%s): %w", projectName, version, err)
 	}
 
+	now := s.now().Unix()
+	before, err := getDependencyTX(ctx, tx, projectID, depName, now)
+	if err != nil {
+		return fmt.Errorf("getDependencyTX(%s): %w", depName, err)
+	}
+
 	res, err := tx.ExecContext(ctx,
 		`DELETE FROM dependency WHERE project_id = ? AND dependency_name = ?`,
 		projectID, depName,
@@ -370,12 +497,24 @@ func (s *Storage) DeleteDependency(ctx context.Context, projectName, version, de
 		return depsmanager.ErrDependencyNotFound
 	}
 
+	err = appendAudit(ctx, tx, now, depsmanager.AuditEntry{
+		Action:         depsmanager.AuditDependencyDelete,
+		ProjectName:    projectName,
+		Version:        version,
+		DependencyName: depName,
+	}, before, nil)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("tx.Commit: %w", err)
 	}
 	return nil
 }
 
+// UpdateDependency changes score of manual dependency. Score of fetched dependency is pinned
+// by an override instead, dep.Override.ExpiresAt ends it, the upstream score is kept for refreshes.
 func (s *Storage) UpdateDependency(ctx context.Context, projectName, version string, dep depsmanager.Dependency) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -388,21 +527,47 @@ func (s *Storage) UpdateDependency(ctx context.Context, projectName, version str
 		return fmt.Errorf("getProjectIDTX(%s,%s): %w", projectName, version, err)
 	}
 
-	res, err := tx.ExecContext(ctx,
-		`UPDATE dependency 
+	now := s.now().Unix()
+	before, err := getDependencyTX(ctx, tx, projectID, dep.Name, now)
+	if err != nil {
+		return fmt.Errorf("getDependencyTX(%s): %w", dep.Name, err)
+	}
+
+	if before.Source == depsmanager.SourceManual {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE dependency 
            SET score = ?, updated_at = ?
          WHERE project_id = ? AND dependency_name = ?`,
-		dep.Score, dep.UpdatedAt, projectID, dep.Name,
-	)
+			dep.Score, dep.UpdatedAt, projectID, dep.Name,
+		)
+	} else {
+		var expiresAt *int64
+		if dep.Override != nil {
+			expiresAt = dep.Override.ExpiresAt
+		}
+		_, err = tx.ExecContext(ctx,
+			`UPDATE dependency
+           SET override_score = ?, override_set_at = ?, override_expires_at = ?
+         WHERE project_id = ? AND dependency_name = ?`,
+			dep.Score, dep.UpdatedAt, expiresAt, projectID, dep.Name,
+		)
+	}
 	if err != nil {
 		return fmt.Errorf("UPDATE dependency(%s): %w", dep.Name, err)
 	}
-	aff, err := res.RowsAffected()
+
+	after, err := getDependencyTX(ctx, tx, projectID, dep.Name, now)
 	if err != nil {
-		return fmt.Errorf("RowsAffected: %w", err)
+		return fmt.Errorf("getDependencyTX(%s): %w", dep.Name, err)
 	}
-	if aff == 0 {
-		return depsmanager.ErrDependencyNotFound
+	err = appendAudit(ctx, tx, now, depsmanager.AuditEntry{
+		Action:         depsmanager.AuditDependencyUpdate,
+		ProjectName:    projectName,
+		Version:        version,
+		DependencyName: dep.Name,
+	}, before, after)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -415,6 +580,22 @@ func (s *Storage) Close() error {
 	return s.db.Close()
 }
 
+// Ping checks the database answers and every migration of this build is applied.
+func (s *Storage) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("s.db.PingContext(): %w", err)
+	}
+
+	var applied int
+	if err := s.db.GetContext(ctx, &applied, "SELECT COUNT(*) FROM schema_migrations"); err != nil {
+		return fmt.Errorf("s.db.GetContext(schema_migrations): %w", err)
+	}
+	if pending := len(migrations) - applied; pending > 0 {
+		return fmt.Errorf("%d migrations pending", pending)
+	}
+	return nil
+}
+
 func (s *Storage) deleteDependenciesFromProject(ctx context.Context, tx *sql.Tx, projectID int64, deps []depsmanager.Dependency) error {
 	for _, dep := range deps {
 		_, err := tx.ExecContext(ctx, "DELETE FROM dependency WHERE project_id = ? AND dependency_name = ?", projectID, dep.Name)
@@ -427,9 +608,14 @@ func (s *Storage) deleteDependenciesFromProject(ctx context.Context, tx *sql.Tx,
 }
 
 func (s *Storage) getProjectID(ctx context.Context, name, version string) (int64, error) {
+	t, err := singleTenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
 	var id int64
-	err := s.db.QueryRowContext(ctx,
-		"SELECT id FROM projects WHERE name = ? AND version = ?", name, version,
+	err = s.db.QueryRowContext(ctx,
+		"SELECT id FROM projects WHERE tenant = ? AND name = ? AND version = ?", t, name, version,
 	).Scan(&id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -441,9 +627,14 @@ func (s *Storage) getProjectID(ctx context.Context, name, version string) (int64
 }
 
 func (s *Storage) getProjectIDTX(ctx context.Context, tx *sql.Tx, name, version string) (int64, error) {
+	t, err := singleTenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
 	var id int64
-	err := tx.QueryRowContext(ctx,
-		"SELECT id FROM projects WHERE name = ? AND version = ?", name, version,
+	err = tx.QueryRowContext(ctx,
+		"SELECT id FROM projects WHERE tenant = ? AND name = ? AND version = ?", t, name, version,
 	).Scan(&id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -454,7 +645,7 @@ func (s *Storage) getProjectIDTX(ctx context.Context, tx *sql.Tx, name, version
 	return id, nil
 }
 
-func createTable(db *sqlx.DB) error {
+func createTable(db execer) error {
 	createProjectQuery := `
 	CREATE TABLE IF NOT EXISTS projects (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
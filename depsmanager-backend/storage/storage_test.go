@@ -308,9 +308,18 @@ func TestAddDependency_SuccessAndDuplicate(t *testing.T) {
 		t.Fatalf("unexpected deps after add: %+v", got)
 	}
 
-	// Add the same dependency again -> UNIQUE violation error expected
-	if err := st.AddDependency(ctx, proj.Name, proj.Version, dep); err == nil {
-		t.Fatalf("expected error on duplicate AddDependency, got nil")
+	// Add the same dependency again -> ErrDependencyAlreadyExists, the stored one is kept
+	dup := dep
+	dup.Score = 0.1
+	if err := st.AddDependency(ctx, proj.Name, proj.Version, dup); !errors.Is(err, depsmanager.ErrDependencyAlreadyExists) {
+		t.Fatalf("expected ErrDependencyAlreadyExists on duplicate AddDependency, got: %v", err)
+	}
+	got, err = st.ListProjectDependencies(ctx, proj.Name, proj.Version)
+	if err != nil {
+		t.Fatalf("ListProjectDependencies: %v", err)
+	}
+	if len(got) != 1 || got[0].Score != dep.Score {
+		t.Fatalf("unexpected deps after duplicate add: %+v", got)
 	}
 }
 
@@ -396,12 +405,13 @@ func TestUpdateDependency_Success(t *testing.T) {
 		t.Fatalf("UpdateDependency: %v", err)
 	}
 
-	// Verify update
+	// Verify update, fetched dependency keeps its upstream score under the override
 	got, err := st.ListProjectDependencies(ctx, proj.Name, proj.Version)
 	if err != nil {
 		t.Fatalf("ListProjectDependencies: %v", err)
 	}
-	if len(got) != 1 || got[0].Name != "dep" || got[0].Score != 0.75 || got[0].UpdatedAt != newDep.UpdatedAt {
+	if len(got) != 1 || got[0].Name != "dep" || got[0].Score != 0.75 || *got[0].UpstreamScore != 0.4 ||
+		got[0].Override == nil || got[0].Override.SetAt != newDep.UpdatedAt {
 		t.Fatalf("unexpected dep after update: %+v", got)
 	}
 }
@@ -431,3 +441,36 @@ func TestUpdateDependency_ProjectNotFound(t *testing.T) {
 		t.Fatalf("expected ErrProjectNotFound, got: %v", err)
 	}
 }
+
+func TestListAllDependencies_AcrossProjects(t *testing.T) {
+	st := newInMemoryStorage(t)
+	ctx := context.Background()
+
+	if err := st.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+		Project:      depsmanager.Project{Name: "proj1", Version: "1.0.0", UpdatedAt: time.Now().Unix()},
+		Dependencies: makeDeps("a", "b"),
+	}); err != nil {
+		t.Fatalf("StoreDependencies(proj1): %v", err)
+	}
+	if err := st.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+		Project:      depsmanager.Project{Name: "proj2", Version: "2.0.0", UpdatedAt: time.Now().Unix()},
+		Dependencies: makeDeps("a"),
+	}); err != nil {
+		t.Fatalf("StoreDependencies(proj2): %v", err)
+	}
+
+	deps, err := st.ListAllDependencies(ctx)
+	if err != nil {
+		t.Fatalf("ListAllDependencies(): %v", err)
+	}
+	if len(deps) != 3 {
+		t.Fatalf("expected 3 dependency rows, got %d: %+v", len(deps), deps)
+	}
+	projectIDs := map[int64]bool{}
+	for _, d := range deps {
+		projectIDs[d.ProjectID] = true
+	}
+	if len(projectIDs) != 2 {
+		t.Fatalf("expected rows from 2 projects, got: %+v", deps)
+	}
+}
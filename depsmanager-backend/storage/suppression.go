@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"depsmanager"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const suppressionColumns = "id, tenant, dependency_name, project_name, project_version, version_range, justification, approver, expires_at, created_at"
+
+func scanSuppression(sc scanner) (depsmanager.Suppression, error) {
+	var s depsmanager.Suppression
+	err := sc.Scan(&s.ID, &s.Tenant, &s.DependencyName, &s.ProjectName, &s.ProjectVersion, &s.VersionRange,
+		&s.Justification, &s.Approver, &s.ExpiresAt, &s.CreatedAt)
+	return s, err
+}
+
+// CreateSuppression stores suppression in tenant of ctx and returns it with its ID.
+func (s *Storage) CreateSuppression(ctx context.Context, sup depsmanager.Suppression) (depsmanager.Suppression, error) {
+	t, err := singleTenant(ctx)
+	if err != nil {
+		return depsmanager.Suppression{}, err
+	}
+	sup.Tenant = t
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return depsmanager.Suppression{}, fmt.Errorf("s.db.BeginTx(): %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO suppressions(tenant, dependency_name, project_name, project_version, version_range, justification, approver, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sup.Tenant, sup.DependencyName, sup.ProjectName, sup.ProjectVersion, sup.VersionRange,
+		sup.Justification, sup.Approver, sup.ExpiresAt, sup.CreatedAt,
+	)
+	if err != nil {
+		return depsmanager.Suppression{}, fmt.Errorf("tx.ExecContext(insert suppression %s): %w", sup.DependencyName, err)
+	}
+	sup.ID, err = res.LastInsertId()
+	if err != nil {
+		return depsmanager.Suppression{}, fmt.Errorf("LastInsertId: %w", err)
+	}
+
+	err = appendAudit(ctx, tx, s.now().Unix(), depsmanager.AuditEntry{
+		Action:         depsmanager.AuditSuppressionCreate,
+		ProjectName:    sup.ProjectName,
+		Version:        sup.ProjectVersion,
+		DependencyName: sup.DependencyName,
+		Target:         strconv.FormatInt(sup.ID, 10),
+	}, nil, sup)
+	if err != nil {
+		return depsmanager.Suppression{}, fmt.Errorf("appendAudit(): %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return depsmanager.Suppression{}, fmt.Errorf("tx.Commit(): %w", err)
+	}
+	return sup, nil
+}
+
+// ListSuppressions returns all suppressions of tenant, including expired ones.
+func (s *Storage) ListSuppressions(ctx context.Context) ([]depsmanager.Suppression, error) {
+	filter, args := tenantFilter(ctx, "tenant")
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+suppressionColumns+`
+		FROM suppressions
+		WHERE `+filter+`
+		ORDER BY id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.QueryContext(ListSuppressions): %w", err)
+	}
+	defer rows.Close()
+
+	suppressions := []depsmanager.Suppression{}
+	for rows.Next() {
+		sup, err := scanSuppression(rows)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan(suppression): %w", err)
+		}
+		suppressions = append(suppressions, sup)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return suppressions, nil
+}
+
+// DeleteSuppression removes suppression of tenant, the audit log keeps what it accepted.
+func (s *Storage) DeleteSuppression(ctx context.Context, id int64) error {
+	t, err := singleTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("s.db.BeginTx(): %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before, err := scanSuppression(tx.QueryRowContext(ctx,
+		"SELECT "+suppressionColumns+" FROM suppressions WHERE id = ? AND tenant = ?", id, t))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return depsmanager.ErrSuppressionNotFound
+		}
+		return fmt.Errorf("tx.QueryRowContext(suppression %d): %w", id, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM suppressions WHERE id = ?", id); err != nil {
+		return fmt.Errorf("tx.ExecContext(delete suppression %d): %w", id, err)
+	}
+
+	err = appendAudit(ctx, tx, s.now().Unix(), depsmanager.AuditEntry{
+		Action:         depsmanager.AuditSuppressionDelete,
+		ProjectName:    before.ProjectName,
+		Version:        before.ProjectVersion,
+		DependencyName: before.DependencyName,
+		Target:         strconv.FormatInt(id, 10),
+	}, before, nil)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit(): %w", err)
+	}
+	return nil
+}
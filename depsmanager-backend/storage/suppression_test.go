@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"depsmanager"
+	"depsmanager/pkg/tenant"
+)
+
+func TestSuppressions_CreateListDelete(t *testing.T) {
+	st := newInMemoryStorage(t)
+	bg := context.Background()
+	if err := st.CreateTenant(bg, depsmanager.Tenant{Name: "team-a", CreatedAt: 1}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	teamA := tenant.NewContext(bg, "team-a")
+
+	created, err := st.CreateSuppression(teamA, depsmanager.Suppression{
+		DependencyName: "lodash", ProjectName: "react", VersionRange: "<4.17.21",
+		Justification: "not reachable", Approver: "security", ExpiresAt: 100, CreatedAt: 10,
+	})
+	if err != nil {
+		t.Fatalf("CreateSuppression: %v", err)
+	}
+	if created.ID == 0 || created.Tenant != "team-a" {
+		t.Fatalf("unexpected suppression: %+v", created)
+	}
+	if _, err := st.CreateSuppression(tenant.NewContext(bg, tenant.All), created); !errors.Is(err, depsmanager.ErrTenantRequired) {
+		t.Fatalf("expected ErrTenantRequired, got: %v", err)
+	}
+
+	got, err := st.ListSuppressions(teamA)
+	if err != nil {
+		t.Fatalf("ListSuppressions: %v", err)
+	}
+	if len(got) != 1 || got[0] != created {
+		t.Fatalf("expected created suppression, got: %+v", got)
+	}
+	if other, _ := st.ListSuppressions(bg); len(other) != 0 {
+		t.Fatalf("default tenant sees team-a suppression: %+v", other)
+	}
+
+	if err := st.DeleteSuppression(bg, created.ID); !errors.Is(err, depsmanager.ErrSuppressionNotFound) {
+		t.Fatalf("expected ErrSuppressionNotFound from other tenant, got: %v", err)
+	}
+	if err := st.DeleteSuppression(teamA, created.ID); err != nil {
+		t.Fatalf("DeleteSuppression: %v", err)
+	}
+	if got, _ := st.ListSuppressions(teamA); len(got) != 0 {
+		t.Fatalf("expected no suppressions after delete, got: %+v", got)
+	}
+
+	entries, err := st.ListAudit(teamA, depsmanager.AuditFilter{DependencyName: "lodash"})
+	if err != nil {
+		t.Fatalf("ListAudit: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Action != depsmanager.AuditSuppressionDelete || entries[1].Action != depsmanager.AuditSuppressionCreate {
+		t.Fatalf("unexpected audit entries: %+v", entries)
+	}
+}
+
+func TestListAllDependencies_ProjectAndVersion(t *testing.T) {
+	st := newInMemoryStorage(t)
+	ctx := context.Background()
+	if err := st.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+		Project:      depsmanager.Project{Name: "react", Version: "18.3.1", UpdatedAt: 1},
+		Dependencies: []depsmanager.Dependency{{Name: "scheduler", Version: "0.23.2", Score: 5, UpdatedAt: 1}},
+	}); err != nil {
+		t.Fatalf("StoreDependencies: %v", err)
+	}
+
+	deps, err := st.ListAllDependencies(ctx)
+	if err != nil {
+		t.Fatalf("ListAllDependencies: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Version != "0.23.2" || deps[0].ProjectTenant != "default" ||
+		deps[0].ProjectName != "react" || deps[0].ProjectVersion != "18.3.1" {
+		t.Fatalf("unexpected dependencies: %+v", deps)
+	}
+}
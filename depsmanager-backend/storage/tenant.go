@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"depsmanager"
+	"depsmanager/pkg/tenant"
+	"errors"
+	"fmt"
+)
+
+// singleTenant returns tenant of ctx for writes and lookups of a single project,
+// tenant.All is rejected with depsmanager.ErrTenantRequired.
+func singleTenant(ctx context.Context) (string, error) {
+	t := tenant.FromContext(ctx)
+	if t == tenant.All {
+		return "", depsmanager.ErrTenantRequired
+	}
+	return t, nil
+}
+
+// tenantFilter returns condition matching rows of column in tenant of ctx, every row for tenant.All.
+func tenantFilter(ctx context.Context, column string) (string, []any) {
+	t := tenant.FromContext(ctx)
+	return "(" + column + " = ? OR ? = '" + tenant.All + "')", []any{t, t}
+}
+
+func (s *Storage) CreateTenant(ctx context.Context, t depsmanager.Tenant) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("s.db.BeginTx(): %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO tenants(name, created_at) VALUES (?, ?)", t.Name, t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("tx.ExecContext(insert tenant %s): %w", t.Name, err)
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("RowsAffected: %w", err)
+	}
+	if aff == 0 {
+		return depsmanager.ErrTenantAlreadyExists
+	}
+
+	// recorded in the new tenant so its admins see who created it
+	err = appendAudit(ctx, tx, s.now().Unix(), depsmanager.AuditEntry{
+		Tenant: t.Name,
+		Action: depsmanager.AuditTenantCreate,
+		Target: t.Name,
+	}, nil, t)
+	if err != nil {
+		return fmt.Errorf("appendAudit(): %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit(): %w", err)
+	}
+	return nil
+}
+
+func (s *Storage) ListTenants(ctx context.Context) ([]depsmanager.Tenant, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT name, created_at FROM tenants ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("s.db.QueryContext(ListTenants): %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []depsmanager.Tenant{}
+	for rows.Next() {
+		var t depsmanager.Tenant
+		if err := rows.Scan(&t.Name, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("rows.Scan(tenant): %w", err)
+		}
+		tenants = append(tenants, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", err)
+	}
+
+	return tenants, nil
+}
+
+func (s *Storage) GetTenant(ctx context.Context, name string) (depsmanager.Tenant, error) {
+	var t depsmanager.Tenant
+	err := s.db.QueryRowContext(ctx, "SELECT name, created_at FROM tenants WHERE name = ?", name).Scan(&t.Name, &t.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return depsmanager.Tenant{}, depsmanager.ErrTenantNotFound
+		}
+		return depsmanager.Tenant{}, fmt.Errorf("s.db.QueryRowContext(GetTenant): %w", err)
+	}
+	return t, nil
+}
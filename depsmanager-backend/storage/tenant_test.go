@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"depsmanager"
+	"depsmanager/pkg/tenant"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestTenants_CreateGetList(t *testing.T) {
+	st := newInMemoryStorage(t)
+	ctx := context.Background()
+
+	if err := st.CreateTenant(ctx, depsmanager.Tenant{Name: "team-a", CreatedAt: 1}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	if err := st.CreateTenant(ctx, depsmanager.Tenant{Name: "team-a", CreatedAt: 2}); !errors.Is(err, depsmanager.ErrTenantAlreadyExists) {
+		t.Fatalf("expected ErrTenantAlreadyExists, got: %v", err)
+	}
+	if _, err := st.GetTenant(ctx, "team-b"); !errors.Is(err, depsmanager.ErrTenantNotFound) {
+		t.Fatalf("expected ErrTenantNotFound, got: %v", err)
+	}
+
+	tenants, err := st.ListTenants(ctx)
+	if err != nil {
+		t.Fatalf("ListTenants: %v", err)
+	}
+	if len(tenants) != 2 || tenants[0].Name != tenant.Default || tenants[1].Name != "team-a" {
+		t.Fatalf("unexpected tenants: %+v", tenants)
+	}
+}
+
+func TestTenants_ProjectsAreScoped(t *testing.T) {
+	st := newInMemoryStorage(t)
+	bg := context.Background()
+	if err := st.CreateTenant(bg, depsmanager.Tenant{Name: "team-a", CreatedAt: 1}); err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	teamA := tenant.NewContext(bg, "team-a")
+	all := tenant.NewContext(bg, tenant.All)
+
+	// the same project version in two tenants
+	for _, ctx := range []context.Context{bg, teamA} {
+		if err := st.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+			Project:      depsmanager.Project{Name: "react", Version: "18.3.1", UpdatedAt: 1},
+			Dependencies: makeDeps("scheduler"),
+		}); err != nil {
+			t.Fatalf("StoreDependencies(%s): %v", tenant.FromContext(ctx), err)
+		}
+	}
+	if err := st.AddDependency(teamA, "react", "18.3.1", depsmanager.Dependency{Name: "only-a", Score: 1}); err != nil {
+		t.Fatalf("AddDependency(team-a): %v", err)
+	}
+
+	deps, err := st.ListProjectDependencies(bg, "react", "18.3.1")
+	if err != nil {
+		t.Fatalf("ListProjectDependencies(default): %v", err)
+	}
+	if len(deps) != 1 {
+		t.Fatalf("default tenant sees team-a dependency: %+v", deps)
+	}
+
+	projects, err := st.ListProjects(teamA)
+	if err != nil {
+		t.Fatalf("ListProjects(team-a): %v", err)
+	}
+	if len(projects) != 1 || projects[0].Tenant != "team-a" {
+		t.Fatalf("unexpected team-a projects: %+v", projects)
+	}
+
+	projects, err = st.ListProjects(all)
+	if err != nil {
+		t.Fatalf("ListProjects(all): %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("expected projects of both tenants, got: %+v", projects)
+	}
+
+	projects, err = st.GetProjectsByDependency(bg, "only-a")
+	if !errors.Is(err, depsmanager.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound in default tenant, got: %+v, %v", projects, err)
+	}
+	names, err := st.GetDependenciesByExactScore(all, 1)
+	if err != nil || len(names) != 1 || names[0] != "only-a" {
+		t.Fatalf("GetDependenciesByExactScore(all): %v, %v", names, err)
+	}
+
+	if err := st.DeleteProject(all, "react", "18.3.1"); !errors.Is(err, depsmanager.ErrTenantRequired) {
+		t.Fatalf("expected ErrTenantRequired for write across tenants, got: %v", err)
+	}
+	if err := st.DeleteProject(teamA, "react", "18.3.1"); err != nil {
+		t.Fatalf("DeleteProject(team-a): %v", err)
+	}
+	if _, err := st.ListProjectDependencies(bg, "react", "18.3.1"); err != nil {
+		t.Fatalf("default project deleted with team-a one: %v", err)
+	}
+}
+
+func TestTenants_UnknownTenantCannotStore(t *testing.T) {
+	st := newInMemoryStorage(t)
+	ctx := tenant.NewContext(context.Background(), "missing")
+
+	err := st.StoreDependencies(ctx, depsmanager.ProjectDependencyRecord{
+		Project: depsmanager.Project{Name: "react", Version: "18.3.1", UpdatedAt: 1},
+	})
+	if err == nil {
+		t.Fatalf("expected foreign key error for unknown tenant")
+	}
+}
+
+func TestMigrate_TenantsKeepExistingData(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "deps.db")
+	db, err := sqlx.Open("sqlite3", dbPath+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("sqlx.Open: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at INTEGER NOT NULL)`); err != nil {
+		t.Fatalf("create schema_migrations: %v", err)
+	}
+	for _, m := range migrations[:4] {
+		if err := applyMigration(db, m); err != nil {
+			t.Fatalf("applyMigration(%d): %v", m.version, err)
+		}
+	}
+	for _, stmt := range []string{
+		`INSERT INTO projects(id, name, version, updated_at) VALUES (1, 'react', '18.3.1', 1)`,
+		`INSERT INTO dependency(project_id, dependency_name, score, updated_at) VALUES (1, 'scheduler', 5, 1)`,
+		`INSERT INTO policies(name, document, updated_at) VALUES ('global', 'name: global', 1)`,
+		`INSERT INTO policy_evaluations(project_id, verdict, violations, evaluated_at) VALUES (1, 'pass', '[]', 1)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("seed %q: %v", stmt, err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	st, err := NewStorage(depsmanager.SQLLiteConfig{DBPath: dbPath})
+	if err != nil {
+		t.Fatalf("NewStorage: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	ctx := context.Background()
+
+	projects, err := st.ListProjects(ctx)
+	if err != nil || len(projects) != 1 || projects[0].Tenant != tenant.Default {
+		t.Fatalf("expected project in default tenant, got: %+v, %v", projects, err)
+	}
+	policies, err := st.ListPolicies(ctx, "")
+	if err != nil || len(policies) != 1 || policies[0].Tenant != tenant.Default {
+		t.Fatalf("expected policy in default tenant, got: %+v, %v", policies, err)
+	}
+	if _, err := st.GetPolicyEvaluation(ctx, "react", "18.3.1"); err != nil {
+		t.Fatalf("GetPolicyEvaluation: %v", err)
+	}
+
+	// foreign keys of dependency still cascade to the rebuilt projects table
+	if err := st.DeleteProject(ctx, "react", "18.3.1"); err != nil {
+		t.Fatalf("DeleteProject: %v", err)
+	}
+	var left int
+	if err := st.db.Get(&left, "SELECT COUNT(*) FROM dependency"); err != nil {
+		t.Fatalf("count dependency: %v", err)
+	}
+	if left != 0 {
+		t.Fatalf("expected dependencies deleted with project, got %d", left)
+	}
+}
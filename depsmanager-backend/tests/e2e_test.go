@@ -7,13 +7,13 @@ import (
 	"bytes"
 	"depsmanager"
 	"depsmanager/clients"
+	"depsmanager/pkg/config"
 	"depsmanager/service"
 	"depsmanager/storage"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
-	"github.com/kelseyhightower/envconfig"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"net/http"
@@ -22,16 +22,18 @@ import (
 )
 
 func Test_e2e(t *testing.T) {
-	var conf depsmanager.Config
-	envconfig.MustProcess("", &conf)
+	conf, err := config.Load("")
+	require.NoError(t, err)
 
 	db, err := storage.NewStorage(conf.SQLLiteConfig)
 	require.NoError(t, err)
 	defer db.Close()
 
+	depsClient, err := clients.NewDepsClient(conf.DepsConfig)
+	require.NoError(t, err)
 	svg := service.NewService(
 		service.WithStorage(db),
-		service.WithDepsClient(clients.NewDepsClient(conf.DepsAddress)),
+		service.WithDepsClient(depsClient),
 		service.WithTimeNow(time.Now),
 	)
 	api := service.NewAPI(svg)
@@ -241,6 +243,54 @@ func Test_e2e(t *testing.T) {
 		}
 		require.Equal(t, 3, correct)
 	})
+
+	t.Run("Override fetched dependency (v2)", func(t *testing.T) {
+		body, err := json.Marshal(depsmanager.PutDependencyRequest{Score: 9})
+		require.NoError(t, err)
+		request, err := http.NewRequest(http.MethodPut,
+			fmt.Sprintf("%s/api/v2/projects/testproject/versions/1.0.0/dependencies/pkg-b", conf.DepsAddress), bytes.NewReader(body))
+		require.NoError(t, err)
+
+		do, err := client.Do(request)
+		require.NoError(t, err)
+		defer do.Body.Close()
+		require.Equal(t, http.StatusOK, do.StatusCode)
+
+		resp, err := http.Get(fmt.Sprintf("%s/api/v2/projects/testproject/versions/1.0.0/dependencies", conf.DepsAddress))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var deps depsmanager.ListDependenciesResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&deps))
+
+		var pkgB *depsmanager.Dependency
+		for i := range deps.Dependencies {
+			if deps.Dependencies[i].Name == "pkg-b" {
+				pkgB = &deps.Dependencies[i]
+			}
+		}
+		require.NotNil(t, pkgB)
+		assert.Equal(t, depsmanager.SourceFetched, pkgB.Source)
+		assert.Equal(t, 9.0, pkgB.Score)
+		require.NotNil(t, pkgB.UpstreamScore)
+		assert.Equal(t, 5.0, *pkgB.UpstreamScore)
+		require.NotNil(t, pkgB.Override)
+		assert.Equal(t, 9.0, pkgB.Override.Score)
+
+		resp, err = http.Get(fmt.Sprintf("%s/api/v2/audit?action=%s&dependency_name=pkg-b", conf.DepsAddress, depsmanager.AuditDependencyUpdate))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var page depsmanager.AuditPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		require.Len(t, page.Entries, 1)
+
+		var before, after depsmanager.Dependency
+		require.NoError(t, json.Unmarshal(page.Entries[0].Before, &before))
+		require.NoError(t, json.Unmarshal(page.Entries[0].After, &after))
+		assert.Equal(t, 5.0, before.Score)
+		assert.Nil(t, before.Override)
+		assert.Equal(t, 9.0, after.Score)
+		require.NotNil(t, after.Override)
+	})
 }
 
 func attachFakeClient(router chi.Router) chi.Router {